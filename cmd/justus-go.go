@@ -1,32 +1,104 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"justus/internal/models"
-	"justus/internal/routers"
-	"justus/pkg/gredis"
+	"justus/internal/bootstrap"
+	"justus/internal/global"
+	"justus/internal/lifecycle"
 	"justus/pkg/logger"
 	"justus/pkg/setting"
 )
 
-func init() {
-	setting.Setup()
-	logger.Setup()
-	gredis.Setup()
-	models.Setup()
-}
+// 构建信息，由 Makefile 通过 -ldflags "-X main.Version=..." 注入
+var (
+	Version   = "dev"
+	BuildTime = ""
+	GoVersion = ""
+)
 
 func main() {
-	log.Printf("🚀 启动 Justus API 服务，端口: %d", setting.ServerSetting.HttpPort)
+	os.Exit(run())
+}
 
-	// 使用依赖注入初始化路由
-	router, err := routers.InitRouterWith()
+// run 启动服务并阻塞至收到停止信号，返回进程退出码
+func run() int {
+	global.Build = global.BuildInfo{Version: Version, BuildTime: BuildTime, GoVersion: GoVersion}
+
+	// 显式构建配置、日志、数据库、Redis 与密钥，并组装路由；配置非法时直接退出
+	app, err := bootstrap.New(bootstrap.Options{WatchConfig: true})
 	if err != nil {
-		log.Fatalf("❌ 初始化路由失败: %v", err)
+		log.Printf("❌ 初始化失败: %v", err)
+		return 1
+	}
+	log.Printf("🚀 启动 Justus API 服务，端口: %d", setting.ServerSetting.HttpPort)
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", setting.ServerSetting.HttpPort),
+		Handler:      app.Router,
+		ReadTimeout:  setting.ServerSetting.ReadTimeout,
+		WriteTimeout: setting.ServerSetting.WriteTimeout,
 	}
 
-	log.Println("依赖注入系统初始化完成")
-	router.Run(fmt.Sprintf(":%d", setting.ServerSetting.HttpPort))
+	// 数据库、Redis 已由 bootstrap 建立；停止时逆序执行：HTTP 排空 -> 等待导入导出任务 -> Redis -> 数据库 -> 导出剩余 span -> 日志刷新
+	lc := lifecycle.NewManager(app.Logger.Infof)
+	lc.Append(lifecycle.Hook{
+		Name:   "logger",
+		OnStop: func(ctx context.Context) error { return logger.Close() },
+	})
+	lc.Append(lifecycle.Hook{
+		Name:   "tracing",
+		OnStop: app.ShutdownTracing,
+	})
+	lc.Append(lifecycle.Hook{
+		Name:   "database",
+		OnStop: func(ctx context.Context) error { return app.CloseDB() },
+	})
+	lc.Append(lifecycle.Hook{
+		Name:   "redis",
+		OnStop: func(ctx context.Context) error { return app.CloseRedis() },
+	})
+	// 开始接收请求之前清理上次进程遗留的任务；停止时等待执行中的任务写完结果，之后才关闭数据库
+	dataJobs := app.Context.Container.DataJobService
+	lc.Append(lifecycle.Hook{
+		Name:    "data-jobs",
+		OnStart: dataJobs.RecoverStale,
+		OnStop:  dataJobs.Shutdown,
+	})
+	serveErr := make(chan error, 1)
+	lc.Append(lifecycle.HTTPServerHook(srv, func() { app.Context.HealthRegistry.SetDraining(true) }, setting.ServerSetting.DrainDelay, serveErr))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := lc.Start(ctx); err != nil {
+		log.Printf("❌ 启动服务失败: %v", err)
+		return 1
+	}
+	log.Printf("服务已启动，监听 %s", srv.Addr)
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("收到停止信号，开始优雅停机")
+	case err := <-serveErr:
+		log.Printf("❌ HTTP 服务异常退出: %v", err)
+		exitCode = 1
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), setting.ServerSetting.ShutdownTimeout+setting.ServerSetting.DrainDelay)
+	defer cancel()
+	if err := lc.Stop(shutdownCtx); err != nil {
+		log.Printf("❌ 停机过程中出现错误: %v", err)
+		exitCode = 1
+	}
+	log.Println("服务已停止")
+	return exitCode
 }
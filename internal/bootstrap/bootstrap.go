@@ -0,0 +1,204 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"justus/internal/global"
+	"justus/internal/infrastructure"
+	"justus/internal/metrics"
+	"justus/internal/models"
+	"justus/internal/routers"
+	"justus/internal/tracing"
+	"justus/internal/wire"
+	"justus/pkg/glange"
+	"justus/pkg/gredis"
+	"justus/pkg/logger"
+	"justus/pkg/setting"
+	"justus/pkg/signurl"
+	"justus/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// redisPingTimeout 启动时 Redis 连接测试超时
+const redisPingTimeout = 3 * time.Second
+
+// tracingFlushTimeout Close 时导出剩余 span 的超时
+const tracingFlushTimeout = 5 * time.Second
+
+// Options 启动参数
+type Options struct {
+	// ConfigFile 配置文件路径，为空时按 APP_ENV 选择
+	ConfigFile string
+	// WatchConfig 是否开启配置热加载
+	WatchConfig bool
+}
+
+// Resources 进程级基础设施：配置校验通过后构建的日志、数据库、Redis 与密钥
+type Resources struct {
+	Logger    *logrus.Logger
+	DB        *gorm.DB
+	Redis     *redis.Client
+	JWTSecret []byte
+
+	shutdownTracing func(context.Context) error
+}
+
+// App 完整组装的 HTTP 应用
+type App struct {
+	*Resources
+	Context *wire.AppContext
+	Router  *gin.Engine
+}
+
+// Setup 加载并校验配置，按顺序构建日志、数据库、Redis 与密钥
+// 数据库、Redis 与 JWT 密钥作为值交给 wire.Deps，由 WireApp 安装到仍依赖包级变量的旧代码；
+// 日志在组装前就要使用，这里直接安装到 global.Logger
+func Setup(opts Options) (*Resources, error) {
+	if err := setting.Load(opts.ConfigFile); err != nil {
+		return nil, err
+	}
+	if err := setting.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	gin.SetMode(setting.ServerSetting.RunMode)
+
+	log, err := logger.New(setting.LoggerSetting)
+	if err != nil {
+		return nil, fmt.Errorf("init logger: %w", err)
+	}
+	global.Logger = log
+
+	db, err := models.Open(setting.DatabaseSetting, setting.ServerSetting.RunMode)
+	if err != nil {
+		_ = logger.Close()
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	// Redis 不可用时只告警，由健康检查反映降级状态
+	rdb := gredis.NewClient(setting.RedisSetting)
+	ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Warnf("Redis连接失败: %v", err)
+	} else {
+		log.Infof("Redis连接成功: %s", setting.RedisSetting.Host)
+	}
+
+	// 语言包不完整时只告警，缺失的译文回退英文/中文
+	if err := glange.Setup(glange.DefaultDir); err != nil {
+		log.Warnf("加载语言包失败: %v", err)
+	}
+
+	secret := applySecrets()
+	if opts.WatchConfig {
+		// 热加载后立即生效：新的 JwtSecret 会使已签发的令牌与文件链接全部失效
+		setting.Watch(func() {
+			applySecrets()
+			log.Info("配置已重新加载，签名密钥已重新应用")
+		})
+	}
+
+	shutdownTracing, err := instrument(db, rdb)
+	if err != nil {
+		_ = rdb.Close()
+		_ = logger.Close()
+		return nil, fmt.Errorf("init tracing: %w", err)
+	}
+
+	return &Resources{
+		Logger:          log,
+		DB:              db,
+		Redis:           rdb,
+		JWTSecret:       secret,
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+// instrument 为本次打开的数据库与 Redis 连接挂载指标与追踪
+// 此时 WireApp 尚未设置 models/global 中的句柄，必须显式传入
+func instrument(db *gorm.DB, rdb *redis.Client) (func(context.Context) error, error) {
+	metrics.Setup(db, rdb)
+	return tracing.Setup(setting.TracingSetting, global.Build.Version, db, rdb)
+}
+
+// applySecrets 按当前配置安装 JWT 与文件链接签名密钥，返回 JWT 密钥
+// 未配置 FileSignSecret 时链接密钥由 JwtSecret 派生，不直接复用
+func applySecrets() []byte {
+	secret := []byte(setting.AppSetting.JwtSecret)
+	util.SetJWTSecret(secret)
+	fileSecret := signurl.DeriveSecret(secret)
+	if key := setting.AppSetting.FileSignSecret; key != "" {
+		fileSecret = []byte(key)
+	}
+	signurl.SetSecret(fileSecret)
+	return secret
+}
+
+// New 构建基础设施并组装路由，返回可直接挂到 http.Server 的应用
+func New(opts Options) (*App, error) {
+	res, err := Setup(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := infrastructure.NewLoggerWith(res.Logger)
+	appCtx, err := wire.WireApp(wire.Deps{
+		Logger:    logger,
+		Cache:     infrastructure.NewCache(),
+		DB:        res.DB,
+		Redis:     res.Redis,
+		JWTSecret: res.JWTSecret,
+		Mailer:    infrastructure.NewMailer(setting.MailSetting, logger),
+	})
+	if err != nil {
+		return nil, errors.Join(err, res.Close())
+	}
+
+	return &App{
+		Resources: res,
+		Context:   appCtx,
+		Router:    routers.NewRouter(appCtx),
+	}, nil
+}
+
+// CloseRedis 关闭 Redis 连接池
+func (r *Resources) CloseRedis() error {
+	if r.Redis == nil {
+		return nil
+	}
+	return r.Redis.Close()
+}
+
+// CloseDB 关闭数据库连接池
+func (r *Resources) CloseDB() error {
+	if r.DB == nil {
+		return nil
+	}
+	sqlDB, err := r.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// ShutdownTracing 导出尚未发送的 span 并停止 TracerProvider
+func (r *Resources) ShutdownTracing(ctx context.Context) error {
+	if r.shutdownTracing == nil {
+		return nil
+	}
+	return r.shutdownTracing(ctx)
+}
+
+// Close 按 Redis、数据库、链路追踪、日志的顺序释放资源
+func (r *Resources) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancel()
+	return errors.Join(r.CloseRedis(), r.CloseDB(), r.ShutdownTracing(ctx), logger.Close())
+}
@@ -0,0 +1,97 @@
+package bootstrap
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"justus/internal/global"
+	"justus/internal/metrics"
+	"justus/pkg/setting"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestInstrumentHandles 启动时 models/global 尚未设置，指标与追踪必须挂到传入的连接上
+func TestInstrumentHandles(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	global.Logger = log
+
+	prevMetrics, prevTracing := *setting.MetricsSetting, *setting.TracingSetting
+	t.Cleanup(func() {
+		*setting.MetricsSetting, *setting.TracingSetting = prevMetrics, prevTracing
+	})
+	setting.MetricsSetting.Enabled = true
+	// 导出地址不可达不影响挂载，span 由下方替换的 provider 记录
+	*setting.TracingSetting = setting.Tracing{Enabled: true, Endpoint: "http://127.0.0.1:1", ServiceName: "justus-test", SampleRatio: 1}
+
+	db, err := gorm.Open(sqlite.Open("file:bootstrap?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	shutdown, err := instrument(db, rdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background()) }()
+
+	for _, name := range []string{"metrics:before_query", "metrics:after_query", "tracing:before_query", "tracing:after_query"} {
+		if db.Callback().Query().Get(name) == nil {
+			t.Errorf("GORM 回调 %s 未注册", name)
+		}
+	}
+
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	if err := rdb.Set(context.Background(), "bootstrap:probe", "1", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var traced bool
+	for _, s := range rec.Ended() {
+		if s.Name() == "redis.set" {
+			traced = true
+		}
+	}
+	if !traced {
+		t.Error("Redis 追踪钩子未挂载")
+	}
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var observed bool
+	for _, f := range families {
+		if !strings.HasSuffix(f.GetName(), "redis_command_duration_seconds") {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetValue() == "set" && m.GetHistogram().GetSampleCount() > 0 {
+					observed = true
+				}
+			}
+		}
+	}
+	if !observed {
+		t.Error("Redis 指标钩子未挂载")
+	}
+}
@@ -1,7 +1,12 @@
 package container
 
 import (
+	"context"
+	"io"
 	"justus/internal/models"
+	"justus/pkg/query"
+	"justus/pkg/upload"
+	"net/url"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -18,51 +23,420 @@ type Logger interface {
 	Warnf(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
 	WithFields(fields logrus.Fields) *logrus.Entry
+	// WithContext 附带请求ID、操作者等请求级字段
+	WithContext(ctx context.Context) *logrus.Entry
 }
 
 // Cache 缓存接口
 type Cache interface {
-	Set(key string, data interface{}, expiration time.Duration) error
-	Get(key string) string
-	Del(key string) (int64, error)
+	Set(ctx context.Context, key string, data interface{}, expiration time.Duration) error
+	Get(ctx context.Context, key string) string
+	Del(ctx context.Context, key string) (int64, error)
+	// Incr 原子自增，常用于世代号/计数器
+	Incr(ctx context.Context, key string) (int64, error)
+	// DelByPattern 按匹配模式批量删除（SCAN），返回删除数量
+	DelByPattern(ctx context.Context, pattern string) (int64, error)
 }
 
 // UserRepository 用户数据访问接口
 type UserRepository interface {
-	GetByID(id int) (*models.User, error)
-	GetByIDs(ids []int) ([]*models.User, error)
-	GetUsers(page, limit int, keyword, status string) ([]*models.User, int64, error)
-	Create(user *models.User) error
-	Update(user *models.User) error
-	Delete(id int) error
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*models.User, error)
+	GetUsers(ctx context.Context, spec *query.Spec) (*query.Page[*models.User], error)
+	Create(ctx context.Context, user *models.User) error
+	// Update 整体更新，以 user.Version 为前置版本
+	Update(ctx context.Context, user *models.User) error
+	// Patch 只更新提供的字段，version 为 0 时不校验版本
+	Patch(ctx context.Context, id int, version uint, patch models.UserPatch) (*models.User, error)
+	Delete(ctx context.Context, id int) error
 }
 
 // AdminUserRepository 管理员用户数据访问接口
 type AdminUserRepository interface {
-	GetByID(id int) (*models.AdminUser, error)
-	GetByUsername(username string) (*models.AdminUser, error)
-	Create(user *models.AdminUser) error
-	Update(user *models.AdminUser) error
-	Delete(id int) error
+	GetByID(ctx context.Context, id int) (*models.AdminUser, error)
+	GetByUsername(ctx context.Context, username string) (*models.AdminUser, error)
+	GetByEmail(ctx context.Context, email string) (*models.AdminUser, error)
+	// ListByTenant 租户内绑定了角色的管理员
+	ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[*models.AdminUser], error)
+	// GetInTenant 获取租户成员，非成员返回 ERROR_ADMIN_NOT_FOUND
+	GetInTenant(ctx context.Context, id, tenantID uint) (*models.AdminUser, error)
+	// CreateInTenant 创建管理员并绑定租户角色
+	CreateInTenant(ctx context.Context, user *models.AdminUser, tenantID uint, roleIDs []uint) error
+	// RemoveFromTenant 移出租户，不再属于任何租户时删除账号；会删除最后一个启用的超级管理员时返回 ERROR_ADMIN_LAST_SUPER
+	RemoveFromTenant(ctx context.Context, id, tenantID uint) (deleted bool, err error)
+	// SetMemberStatus 设置租户内的成员状态（禁用只作用于该租户，账号本身不变）
+	SetMemberStatus(ctx context.Context, id, tenantID uint, status int) error
+	// InOtherTenants 是否还属于 tenantID 以外的租户
+	InOtherTenants(ctx context.Context, id, tenantID uint) (bool, error)
+	Create(ctx context.Context, user *models.AdminUser) error
+	Update(ctx context.Context, user *models.AdminUser) error
+	Delete(ctx context.Context, id int) error
+}
+
+// AdminInvitationRepository 管理员邀请数据访问接口
+type AdminInvitationRepository interface {
+	Create(ctx context.Context, inv *models.AdminInvitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.AdminInvitation, error)
+	ListPending(ctx context.Context, tenantID uint) ([]models.AdminInvitation, error)
+	Revoke(ctx context.Context, id, tenantID uint) error
+	// Accept 标记邀请已接受并绑定角色；admin.ID 为 0 时同时创建账号
+	Accept(ctx context.Context, inv *models.AdminInvitation, admin *models.AdminUser) error
+}
+
+// RoleRepository 角色数据访问接口（租户内角色及其授权）
+type RoleRepository interface {
+	ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Role], error)
+	GetByIDForTenant(ctx context.Context, id, tenantID uint) (*models.Role, error)
+	// GetByIDsAndTenant 获取租户可用的角色（含系统级角色）
+	GetByIDsAndTenant(ctx context.Context, ids []int, tenantID uint) ([]models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	// UpdateForTenant 只更新提供的字段，version 为 0 时不校验版本
+	UpdateForTenant(ctx context.Context, id, tenantID, version uint, patch models.RolePatch) (*models.Role, error)
+	DeleteForTenant(ctx context.Context, id, tenantID uint) error
+	GetPermissionIDs(ctx context.Context, roleID uint) ([]uint, error)
+	ReplacePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	AssignToAdminInTenant(ctx context.Context, adminUserID, tenantID uint, roleIDs []uint) error
+}
+
+// PermissionRepository 权限数据访问接口（权限为平台级数据，无租户维度）
+type PermissionRepository interface {
+	List(ctx context.Context) ([]models.Permission, error)
+	GetByName(ctx context.Context, name string) (*models.Permission, error)
+	// Create 创建权限，标识已存在时返回 ERROR_PERMISSION_EXIST
+	Create(ctx context.Context, p *models.Permission) error
+	GetMenusByIDs(ctx context.Context, ids []uint) ([]models.Permission, error)
+	GetAdminPermissionIDsInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]uint, error)
+	GetAdminPermissionNamesInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]string, error)
+}
+
+// TenantRepository 租户数据访问接口
+type TenantRepository interface {
+	GetByID(ctx context.Context, id uint) (*models.Tenant, error)
+	// Update 只更新提供的字段，version 为 0 时不校验版本
+	Update(ctx context.Context, id, version uint, patch models.TenantPatch) (*models.Tenant, error)
+	// GetPermissionIDs 租户权限（菜单）白名单
+	GetPermissionIDs(ctx context.Context, tenantID uint) ([]uint, error)
+	ReplacePermissions(ctx context.Context, tenantID uint, permissionIDs []uint) error
+	// Delete 软删除租户，关联数据保留到从回收站永久删除
+	Delete(ctx context.Context, id uint) error
 }
 
 // UserService 用户服务接口
 type UserService interface {
-	GetUserInfo(id int) (*models.User, error)
-	GetUsersByIDs(ids []int) ([]*models.User, error)
-	GetUsers(page, limit int, keyword, status string) ([]*models.User, int64, error)
-	CreateUser(user *models.User) error
-	UpdateUser(user *models.User) error
-	DeleteUser(id int) error
+	GetUserInfo(ctx context.Context, id int) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int) ([]*models.User, error)
+	GetUsers(ctx context.Context, spec *query.Spec) (*query.Page[*models.User], error)
+	CreateUser(ctx context.Context, user *models.User) error
+	// UpdateUser 整体更新，以 user.Version 为前置版本，冲突返回 ERROR_VERSION_CONFLICT
+	UpdateUser(ctx context.Context, user *models.User) error
+	// PatchUser 只更新提供的字段，version 为 0 时不校验版本
+	PatchUser(ctx context.Context, id int, version uint, patch models.UserPatch) (*models.User, error)
+	DeleteUser(ctx context.Context, id int) error
+	// GetUserLang 用户的语言偏好，按用户缓存；用户不存在或查询失败时返回空
+	GetUserLang(ctx context.Context, id int) string
 }
 
 // AdminUserService 管理员用户服务接口
 type AdminUserService interface {
-	GetAdminUserInfo(id int) (*models.AdminUser, error)
-	GetByUsername(username string) (*models.AdminUser, error)
-	CreateAdminUser(user *models.AdminUser) error
-	UpdateAdminUser(user *models.AdminUser) error
-	DeleteAdminUser(id int) error
+	GetAdminUserInfo(ctx context.Context, id int) (*models.AdminUser, error)
+	GetByUsername(ctx context.Context, username string) (*models.AdminUser, error)
+	ListAdminUsers(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[*models.AdminUser], error)
+	CreateAdminUser(ctx context.Context, user *models.AdminUser) error
+	UpdateAdminUser(ctx context.Context, user *models.AdminUser) error
+	DeleteAdminUser(ctx context.Context, id int) error
+
+	// 以下为租户内管理员管理，操作者取自 context 中的 reqctx.Actor
+	GetAdminUserInTenant(ctx context.Context, tenantID, id uint) (*models.AdminUser, error)
+	CreateAdminUserInTenant(ctx context.Context, tenantID uint, in AdminUserInput) (*models.AdminUser, error)
+	UpdateAdminUserInTenant(ctx context.Context, tenantID, id uint, in AdminUserInput) (*models.AdminUser, error)
+	UpdateAdminUserStatus(ctx context.Context, tenantID, id uint, status int) error
+	// RemoveAdminUserFromTenant 移出租户；deleted 表示账号因不再属于任何租户而被删除
+	RemoveAdminUserFromTenant(ctx context.Context, tenantID, id uint) (deleted bool, err error)
+
+	// 以下为批量操作：逐条执行与单条接口相同的校验，单条失败不影响其他记录，每条写一条审计日志
+	BulkUpdateAdminUserStatus(ctx context.Context, tenantID uint, ids []uint, status int) []BulkResult
+	// BulkAssignRoles 覆盖式设置租户角色；角色不属于该租户时整批拒绝
+	BulkAssignRoles(ctx context.Context, tenantID uint, ids, roleIDs []uint) ([]BulkResult, error)
+	BulkRemoveAdminUsersFromTenant(ctx context.Context, tenantID uint, ids []uint) []BulkResult
+}
+
+// BulkResult 批量操作中单条记录的结果，Err 为 nil 表示成功
+type BulkResult struct {
+	ID  uint
+	Err error
+}
+
+// AdminUserInput 租户内创建/更新管理员的输入
+// 更新时 Username 忽略、Password 为空表示不修改、RoleIDs 为 nil 表示不调整角色
+type AdminUserInput struct {
+	Username   string
+	Password   string
+	Email      string
+	Phone      string
+	RealName   string
+	Department string
+	Position   string
+	Avatar     string
+	RoleIDs    []uint
+}
+
+// InvitationInput 邀请管理员的输入
+type InvitationInput struct {
+	Email      string
+	RoleIDs    []uint
+	Department string
+	Position   string
+}
+
+// AcceptInvitationInput 接受邀请的输入；邮箱已有账号时只校验 Password，忽略 Username
+type AcceptInvitationInput struct {
+	Token    string
+	Username string
+	Password string
+	RealName string
+}
+
+// AdminInvitationService 管理员邀请服务接口
+type AdminInvitationService interface {
+	Invite(ctx context.Context, tenantID uint, in InvitationInput) (*models.AdminInvitation, error)
+	ListPending(ctx context.Context, tenantID uint) ([]models.AdminInvitation, error)
+	Revoke(ctx context.Context, tenantID, id uint) error
+	Accept(ctx context.Context, in AcceptInvitationInput) (*models.AdminUser, error)
+}
+
+// AuditLogRepository 审计日志数据访问接口
+type AuditLogRepository interface {
+	Create(ctx context.Context, logs []models.AuditLog) error
+	ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.AuditLog], error)
+}
+
+// DataJobRepository 导入导出任务数据访问接口
+type DataJobRepository interface {
+	Create(ctx context.Context, job *models.DataJob) error
+	GetForTenant(ctx context.Context, id, tenantID uint) (*models.DataJob, error)
+	// GetByFile 按结果文件名（导出文件或导入报告）获取任务
+	GetByFile(ctx context.Context, tenantID uint, name string) (*models.DataJob, error)
+	ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.DataJob], error)
+	Update(ctx context.Context, id uint, columns map[string]interface{}) error
+	// CountActive 租户排队中与执行中的任务数
+	CountActive(ctx context.Context, tenantID uint) (int64, error)
+	// FailActive 将全部租户排队中与执行中的任务标记为失败，返回被标记的任务
+	FailActive(ctx context.Context, reason string) ([]models.DataJob, error)
+}
+
+// DataJobService 导入导出服务接口：任务在后台执行，调用方通过 Get 轮询进度
+type DataJobService interface {
+	// Export 按列表查询参数（过滤、排序、fields）导出 resource，format 为 xlsx 或 csv
+	Export(ctx context.Context, tenantID uint, resource, format string, params url.Values) (*models.DataJob, error)
+	// Import 导入 src 中的数据，首行为表头；逐行校验，错误行写入报告文件
+	Import(ctx context.Context, tenantID uint, resource, format string, src io.Reader) (*models.DataJob, error)
+	Get(ctx context.Context, tenantID, id uint) (*models.DataJob, error)
+	List(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.DataJob], error)
+	// RecoverStale 启动时将上次进程遗留的未完成任务标记为失败并删除暂存的导入文件
+	RecoverStale(ctx context.Context) error
+	// Shutdown 不再执行排队中的任务，等待执行中的任务结束或 ctx 到期
+	Shutdown(ctx context.Context) error
+}
+
+// UploadRepository 上传文件数据访问接口
+type UploadRepository interface {
+	Create(ctx context.Context, u *models.Upload) error
+	GetForTenant(ctx context.Context, id, tenantID uint) (*models.Upload, error)
+	// FindByMD5 查找本租户相同内容的记录，不存在时返回 nil
+	FindByMD5(ctx context.Context, tenantID uint, md5 string) (*models.Upload, error)
+	GetByKey(ctx context.Context, tenantID uint, key string) (*models.Upload, error)
+	ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Upload], error)
+	// Delete 删除记录，返回仍引用同一存储键的记录数
+	Delete(ctx context.Context, u *models.Upload) (int64, error)
+	// Usage 租户已用存储（字节）与文件数
+	Usage(ctx context.Context, tenantID uint) (used int64, files int64, err error)
+}
+
+// UploadInput 上传请求
+type UploadInput struct {
+	Category string
+	// Name 客户端文件名，仅作展示，类型以内容嗅探为准
+	Name string
+	Body io.Reader
+}
+
+// UploadUsage 租户存储用量，Quota 为 0 表示不限
+type UploadUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+	Files int64 `json:"files"`
+}
+
+// UploadService 文件上传服务接口
+type UploadService interface {
+	// Upload 校验并保存文件；本租户已有相同内容时直接返回已有记录，existed 为 true
+	Upload(ctx context.Context, tenantID uint, in UploadInput) (u *models.Upload, existed bool, err error)
+	Get(ctx context.Context, tenantID, id uint) (*models.Upload, error)
+	List(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Upload], error)
+	// Delete 删除记录，存储对象在没有其他记录引用时一并删除
+	Delete(ctx context.Context, tenantID, id uint) error
+	Usage(ctx context.Context, tenantID uint) (*UploadUsage, error)
+	// URL 上传记录的访问地址：图片为公开地址，其余文件为绑定租户的签名链接
+	URL(u *models.Upload) string
+	// Variant 图片变体的访问地址，变体尚未生成时从原图生成
+	Variant(ctx context.Context, key, name string) (string, error)
+}
+
+// SignedFile 签名链接指向的文件，调用方负责关闭 Content
+type SignedFile struct {
+	// Name 文件名，未指定 ContentType 时按扩展名推断
+	Name        string
+	ContentType string
+	// ETag 为空时不参与条件请求
+	ETag    string
+	ModTime time.Time
+	Content io.ReadSeekCloser
+	Expires time.Time
+	// Filename 非空时以附件形式下载
+	Filename string
+}
+
+// FileService 签名文件服务：导出文件、私有上传与二维码只能通过 /files 下的签名链接访问
+type FileService interface {
+	// Open 校验签名与租户归属后打开文件
+	Open(ctx context.Context, path string, query url.Values) (*SignedFile, error)
+}
+
+// QrCodeInput 二维码生成参数，零值字段取默认值
+type QrCodeInput struct {
+	Content string
+	// Format 为 png（默认）、svg 或 jpg
+	Format string
+	// Size 边长（像素）
+	Size int
+	// Margin 静区宽度（模块数），为 nil 时取 4
+	Margin *int
+	// Level 纠错等级 L、M（默认）、Q、H，带 Logo 时提升到 H
+	Level string
+	// Foreground、Background 十六进制颜色，如 #000000
+	Foreground string
+	Background string
+	// LogoID 作为 Logo 的本租户上传图片
+	LogoID uint
+}
+
+// QrCodeFile 生成的二维码，调用方负责关闭 Content
+type QrCodeFile struct {
+	Name        string
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+	Content     io.ReadSeekCloser
+}
+
+// QrCodeService 二维码服务接口：相同参数只生成一次，结果缓存在 app.QrCodeSavePath
+type QrCodeService interface {
+	Generate(ctx context.Context, tenantID uint, in QrCodeInput) (*QrCodeFile, error)
+	// PurgeCache 删除生成时间超过 maxAge 的缓存文件，返回删除个数
+	PurgeCache(ctx context.Context, maxAge time.Duration) (int, error)
+}
+
+// TrashPage 回收站列表结果，Items 为对应模型的切片
+type TrashPage struct {
+	Items      interface{}
+	Pagination query.Pagination
+}
+
+// TrashRepository 回收站数据访问接口；resource 见 models.TrashSchemas，tenantID 为 0 时不按租户过滤
+type TrashRepository interface {
+	List(ctx context.Context, resource string, tenantID uint, spec *query.Spec) (*TrashPage, error)
+	Restore(ctx context.Context, resource string, tenantID, id uint) error
+	Purge(ctx context.Context, resource string, tenantID, id uint) error
+	// PurgeBefore 永久删除 cutoff 之前软删除的记录
+	PurgeBefore(ctx context.Context, resource string, cutoff time.Time) (int64, error)
+}
+
+// TrashService 回收站服务接口
+type TrashService interface {
+	List(ctx context.Context, resource string, tenantID uint, spec *query.Spec) (*TrashPage, error)
+	Restore(ctx context.Context, resource string, tenantID, id uint) error
+	Purge(ctx context.Context, resource string, tenantID, id uint) error
+	// PurgeExpired 永久删除超过保留期的记录，返回各资源删除条数
+	PurgeExpired(ctx context.Context, retention time.Duration) (map[string]int64, error)
+}
+
+// MailMessage 邮件内容（纯文本）
+type MailMessage struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// MenuNode 菜单树节点
+type MenuNode struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	DisplayName string      `json:"display_name"`
+	Route       string      `json:"route"`
+	Component   string      `json:"component"`
+	MenuIcon    string      `json:"menu_icon"`
+	ParentID    uint        `json:"parent_id"`
+	SortOrder   int         `json:"sort_order"`
+	Children    []*MenuNode `json:"children"`
+}
+
+// MenuService 菜单服务接口（租户白名单 ∩ 用户权限，带缓存）
+type MenuService interface {
+	// UserMenuTree 当前用户在租户下可见的菜单树，cached 表示是否命中缓存
+	UserMenuTree(ctx context.Context, tenantID uint, userID int) (tree []*MenuNode, cached bool, err error)
+	TenantMenuIDs(ctx context.Context, tenantID uint) ([]uint, error)
+	UpdateTenantMenus(ctx context.Context, tenantID uint, permissionIDs []uint) error
+	// InvalidateTenant 失效租户全部菜单缓存
+	InvalidateTenant(ctx context.Context, tenantID uint)
+}
+
+// CacheScope 缓存清理范围
+type CacheScope struct {
+	TenantID   uint // 目标租户
+	AllTenants bool // 是否跨全部租户（仅超级管理员）
+}
+
+// CacheService 缓存管理服务接口
+type CacheService interface {
+	Clear(ctx context.Context, category string, scope CacheScope) (map[string]int64, error)
+}
+
+// LogQuery 日志查询条件
+type LogQuery struct {
+	Level    string    // logrus 级别：debug、info、warning、error，空表示全部
+	Start    time.Time // 起始时间（含）
+	End      time.Time // 截止时间（含）
+	UserID   int
+	TenantID uint // 非0时只返回该租户的日志
+	Path     string
+	Keyword  string
+	Cursor   string
+	Limit    int
+}
+
+// LogEntry 日志条目
+type LogEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// LogPage 日志分页结果
+type LogPage struct {
+	Source     string     `json:"source"`
+	Entries    []LogEntry `json:"entries"`
+	NextCursor string     `json:"next_cursor"`
+}
+
+// LogQueryService 日志查询服务接口
+type LogQueryService interface {
+	Query(ctx context.Context, q LogQuery) (*LogPage, error)
 }
 
 // Container 依赖注入容器
@@ -70,14 +444,34 @@ type Container struct {
 	// Infrastructure
 	Logger Logger
 	Cache  Cache
+	Mailer Mailer
+	// Storage 文件存储后端（本地磁盘或 S3 兼容服务）
+	Storage upload.Storage
 
 	// Repositories
-	UserRepo      UserRepository
-	AdminUserRepo AdminUserRepository
+	UserRepo       UserRepository
+	AdminUserRepo  AdminUserRepository
+	InvitationRepo AdminInvitationRepository
+	RoleRepo       RoleRepository
+	PermissionRepo PermissionRepository
+	TenantRepo     TenantRepository
+	TrashRepo      TrashRepository
+	AuditLogRepo   AuditLogRepository
+	DataJobRepo    DataJobRepository
+	UploadRepo     UploadRepository
 
 	// Services
-	UserService      UserService
-	AdminUserService AdminUserService
+	UserService       UserService
+	AdminUserService  AdminUserService
+	InvitationService AdminInvitationService
+	TrashService      TrashService
+	CacheService      CacheService
+	LogQueryService   LogQueryService
+	MenuService       MenuService
+	DataJobService    DataJobService
+	UploadService     UploadService
+	FileService       FileService
+	QrCodeService     QrCodeService
 }
 
 // NewContainer 创建新的依赖注入容器
@@ -1,22 +1,26 @@
 package admin
 
 import (
+	"encoding/json"
+	"time"
+
 	"justus/internal/container"
-	"justus/internal/models"
 	"justus/pkg/app"
 	"justus/pkg/e"
+	"justus/pkg/rediskey"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AccessController 提供权限码等访问控制相关接口
 type AccessController struct {
-	logger container.Logger
-	cache  container.Cache
+	permissionRepo container.PermissionRepository
+	logger         container.Logger
+	cache          container.Cache
 }
 
-func NewAccessController(logger container.Logger, cache container.Cache) *AccessController {
-	return &AccessController{logger: logger, cache: cache}
+func NewAccessController(permissionRepo container.PermissionRepository, logger container.Logger, cache container.Cache) *AccessController {
+	return &AccessController{permissionRepo: permissionRepo, logger: logger, cache: cache}
 }
 
 // GetAccessCodes 返回当前管理员在当前租户下拥有的权限名称数组（用于前端按钮级控制）
@@ -37,10 +41,25 @@ func (ac *AccessController) GetAccessCodes(c *gin.Context) {
 	}
 	adminUserID := userVal.(int)
 
-	codes, err := models.GetAdminUserPermissionNamesInTenant(adminUserID, tenantID)
+	cacheKey := rediskey.TenantAdminAccessCodesKey(tenantID, uint(adminUserID))
+	if ac.cache != nil {
+		if raw := ac.cache.Get(c.Request.Context(), cacheKey); raw != "" {
+			var cached []string
+			if err := json.Unmarshal([]byte(raw), &cached); err == nil {
+				appG.Success(gin.H{"codes": cached})
+				return
+			}
+		}
+	}
+
+	codes, err := ac.permissionRepo.GetAdminPermissionNamesInTenant(c.Request.Context(), adminUserID, tenantID)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_QUERY)
+		appG.Fail(err)
 		return
 	}
+	if ac.cache != nil {
+		payload, _ := json.Marshal(codes)
+		_ = ac.cache.Set(c.Request.Context(), cacheKey, string(payload), 5*time.Minute)
+	}
 	appG.Success(gin.H{"codes": codes})
 }
@@ -0,0 +1,442 @@
+package admin
+
+import (
+	"strconv"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminUserController 管理员账号管理控制器
+type AdminUserController struct {
+	adminUserService  container.AdminUserService
+	invitationService container.AdminInvitationService
+	logger            container.Logger
+}
+
+// NewAdminUserController 创建管理员账号管理控制器实例
+func NewAdminUserController(adminUserService container.AdminUserService, invitationService container.AdminInvitationService, logger container.Logger) *AdminUserController {
+	return &AdminUserController{adminUserService: adminUserService, invitationService: invitationService, logger: logger}
+}
+
+// CreateAdminUserRequest 创建管理员请求
+type CreateAdminUserRequest struct {
+	Username   string `json:"username" binding:"required,username"`
+	Password   string `json:"password" binding:"required,min=8,max=72"`
+	Email      string `json:"email" binding:"omitempty,email,max=100"`
+	Phone      string `json:"phone" binding:"omitempty,phone"`
+	RealName   string `json:"real_name" binding:"max=50"`
+	Department string `json:"department" binding:"max=100"`
+	Position   string `json:"position" binding:"max=50"`
+	Avatar     string `json:"avatar" binding:"max=500"`
+	RoleIDs    []uint `json:"role_ids" binding:"required,min=1"`
+}
+
+// UpdateAdminUserRequest 更新管理员请求；password 为空时不修改，role_ids 省略时不调整角色
+type UpdateAdminUserRequest struct {
+	Password   string `json:"password" binding:"omitempty,min=8,max=72"`
+	Email      string `json:"email" binding:"omitempty,email,max=100"`
+	Phone      string `json:"phone" binding:"omitempty,phone"`
+	RealName   string `json:"real_name" binding:"max=50"`
+	Department string `json:"department" binding:"max=100"`
+	Position   string `json:"position" binding:"max=50"`
+	Avatar     string `json:"avatar" binding:"max=500"`
+	RoleIDs    []uint `json:"role_ids" binding:"omitempty,min=1"`
+}
+
+// UpdateAdminUserStatusRequest 更新管理员状态请求
+type UpdateAdminUserStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1 2"`
+}
+
+// BulkAdminUsersRequest 批量操作的目标管理员，单次最多 100 个
+type BulkAdminUsersRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+}
+
+// BulkAdminUserStatusRequest 批量更新管理员状态请求
+type BulkAdminUserStatusRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+	Status *int   `json:"status" binding:"required,oneof=0 1 2"`
+}
+
+// BulkAssignRolesRequest 批量设置管理员角色请求
+type BulkAssignRolesRequest struct {
+	IDs     []uint `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+	RoleIDs []uint `json:"role_ids" binding:"required,min=1"`
+}
+
+// InviteAdminUserRequest 邀请管理员请求
+type InviteAdminUserRequest struct {
+	Email      string `json:"email" binding:"required,email,max=100"`
+	RoleIDs    []uint `json:"role_ids" binding:"required,min=1"`
+	Department string `json:"department" binding:"max=100"`
+	Position   string `json:"position" binding:"max=50"`
+}
+
+// AcceptInvitationRequest 接受邀请请求；邮箱已有账号时 username 可省略，password 为该账号密码
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"omitempty,username"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	RealName string `json:"real_name" binding:"max=50"`
+}
+
+// GetAdminUsers 获取当前租户的管理员列表
+func (auc *AdminUserController) GetAdminUsers(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	spec, err := app.ParseQuery(c, models.AdminUserListSchema)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	result, err := auc.adminUserService.ListAdminUsers(c.Request.Context(), tenantID, spec)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	admins := make([]*models.AdminUserDetail, 0, len(result.Items))
+	for _, au := range result.Items {
+		admins = append(admins, au.Format(c.Request.Context()))
+	}
+
+	appG.Success(gin.H{
+		"admin_users": spec.Project(admins),
+		"pagination":  result.Pagination,
+		"filters":     spec.Echo(),
+	})
+}
+
+// GetAdminUser 获取当前租户内的管理员详情
+func (auc *AdminUserController) GetAdminUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantID, id, ok := tenantAndID(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	au, err := auc.adminUserService.GetAdminUserInTenant(c.Request.Context(), tenantID, id)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(au.Format(c.Request.Context()))
+}
+
+// CreateAdminUser 在当前租户创建管理员并绑定角色
+func (auc *AdminUserController) CreateAdminUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req CreateAdminUserRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin creating admin user: tenant_id=%d, username=%s", tenantID, req.Username)
+
+	au, err := auc.adminUserService.CreateAdminUserInTenant(c.Request.Context(), tenantID, container.AdminUserInput{
+		Username:   req.Username,
+		Password:   req.Password,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		RealName:   req.RealName,
+		Department: req.Department,
+		Position:   req.Position,
+		Avatar:     req.Avatar,
+		RoleIDs:    req.RoleIDs,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(au.Format(c.Request.Context()))
+}
+
+// UpdateAdminUser 更新当前租户内的管理员资料
+func (auc *AdminUserController) UpdateAdminUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantID, id, ok := tenantAndID(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	var req UpdateAdminUserRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin updating admin user: tenant_id=%d, id=%d", tenantID, id)
+
+	au, err := auc.adminUserService.UpdateAdminUserInTenant(c.Request.Context(), tenantID, id, container.AdminUserInput{
+		Password:   req.Password,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		RealName:   req.RealName,
+		Department: req.Department,
+		Position:   req.Position,
+		Avatar:     req.Avatar,
+		RoleIDs:    req.RoleIDs,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(au.Format(c.Request.Context()))
+}
+
+// UpdateAdminUserStatus 启用/禁用/锁定当前租户内的管理员
+func (auc *AdminUserController) UpdateAdminUserStatus(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantID, id, ok := tenantAndID(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	var req UpdateAdminUserStatusRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin updating admin user status: tenant_id=%d, id=%d, status=%d", tenantID, id, *req.Status)
+
+	if err := auc.adminUserService.UpdateAdminUserStatus(c.Request.Context(), tenantID, id, *req.Status); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": id, "status": *req.Status})
+}
+
+// DeleteAdminUser 将管理员移出当前租户，不再属于任何租户时删除账号
+func (auc *AdminUserController) DeleteAdminUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantID, id, ok := tenantAndID(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin removing admin user: tenant_id=%d, id=%d", tenantID, id)
+
+	deleted, err := auc.adminUserService.RemoveAdminUserFromTenant(c.Request.Context(), tenantID, id)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": id, "account_deleted": deleted})
+}
+
+// BulkUpdateAdminUserStatus 批量启用/禁用/锁定当前租户内的管理员，返回逐条结果
+func (auc *AdminUserController) BulkUpdateAdminUserStatus(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req BulkAdminUserStatusRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin bulk updating admin user status: tenant_id=%d, ids=%v, status=%d", tenantID, req.IDs, *req.Status)
+
+	bulkResponse(appG, auc.adminUserService.BulkUpdateAdminUserStatus(c.Request.Context(), tenantID, req.IDs, *req.Status))
+}
+
+// BulkAssignRoles 批量覆盖式设置管理员在当前租户的角色，返回逐条结果
+func (auc *AdminUserController) BulkAssignRoles(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req BulkAssignRolesRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin bulk assigning roles: tenant_id=%d, ids=%v, role_ids=%v", tenantID, req.IDs, req.RoleIDs)
+
+	results, err := auc.adminUserService.BulkAssignRoles(c.Request.Context(), tenantID, req.IDs, req.RoleIDs)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	bulkResponse(appG, results)
+}
+
+// BulkDeleteAdminUsers 批量将管理员移出当前租户，返回逐条结果
+func (auc *AdminUserController) BulkDeleteAdminUsers(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req BulkAdminUsersRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	auc.logger.WithContext(c.Request.Context()).Infof("Admin bulk removing admin users: tenant_id=%d, ids=%v", tenantID, req.IDs)
+
+	bulkResponse(appG, auc.adminUserService.BulkRemoveAdminUsersFromTenant(c.Request.Context(), tenantID, req.IDs))
+}
+
+// InviteAdminUser 通过邮件邀请管理员加入当前租户
+func (auc *AdminUserController) InviteAdminUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req InviteAdminUserRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	inv, err := auc.invitationService.Invite(c.Request.Context(), tenantID, container.InvitationInput{
+		Email:      req.Email,
+		RoleIDs:    req.RoleIDs,
+		Department: req.Department,
+		Position:   req.Position,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(inv)
+}
+
+// GetInvitations 当前租户待接受的邀请
+func (auc *AdminUserController) GetInvitations(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	list, err := auc.invitationService.ListPending(c.Request.Context(), uint(tenantVal.(int)))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"invitations": list})
+}
+
+// RevokeInvitation 撤销邀请
+func (auc *AdminUserController) RevokeInvitation(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tenantID, id, ok := tenantAndID(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	if err := auc.invitationService.Revoke(c.Request.Context(), tenantID, id); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": id})
+}
+
+// AcceptInvitation 接受邀请（公开接口，凭邮件中的令牌）
+func (auc *AdminUserController) AcceptInvitation(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req AcceptInvitationRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	au, err := auc.invitationService.Accept(c.Request.Context(), container.AcceptInvitationInput{
+		Token:    req.Token,
+		Username: req.Username,
+		Password: req.Password,
+		RealName: req.RealName,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(au.Format(c.Request.Context()))
+}
+
+// bulkResponse 批量接口统一输出逐条结果与成功/失败计数；部分失败时整体仍返回 200
+func bulkResponse(appG app.Gin, results []container.BulkResult) {
+	items := make([]app.ItemResult, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+		items = append(items, appG.Item(r.ID, r.Err))
+	}
+	appG.Success(gin.H{
+		"results":   items,
+		"succeeded": len(items) - failed,
+		"failed":    failed,
+	})
+}
+
+// tenantAndID 读取当前租户与路径参数 id
+func tenantAndID(c *gin.Context) (tenantID, id uint, ok bool) {
+	tenantVal, exists := c.Get("tenantId")
+	if !exists {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || n == 0 {
+		return 0, 0, false
+	}
+	return uint(tenantVal.(int)), uint(n), true
+}
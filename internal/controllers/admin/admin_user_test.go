@@ -0,0 +1,237 @@
+package admin_test
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+)
+
+func TestAdminUserManagement(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	resp := kit.Call(http.MethodPost, "/admin/v1/admin-users", map[string]interface{}{
+		"username": "ops_lead", "password": "s3cret-pass", "email": "ops@example.com",
+		"department": "Ops", "role_ids": []uint{testkit.RoleAEditorID},
+	}, tokenA)
+	if resp.Code != e.SUCCESS {
+		t.Fatalf("create: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var created struct {
+		ID uint `json:"id"`
+	}
+	if err := resp.Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他租户的角色不能绑定
+	resp = kit.Call(http.MethodPost, "/admin/v1/admin-users", map[string]interface{}{
+		"username": "intruder", "password": "s3cret-pass", "role_ids": []uint{testkit.RoleBEditorID},
+	}, tokenA)
+	if resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("foreign role: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	var list struct {
+		AdminUsers []struct {
+			ID uint `json:"id"`
+		} `json:"admin_users"`
+	}
+	resp = kit.Get("/admin/v1/admin-users?department=Ops", tokenA)
+	if err := resp.Decode(&list); err != nil || len(list.AdminUsers) != 1 || list.AdminUsers[0].ID != created.ID {
+		t.Fatalf("department filter: %+v err=%v", list, err)
+	}
+	// 租户 B 的管理员不在租户 A 的范围内
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/admin-users/%d", testkit.AdminBID), tokenA); resp.Status != http.StatusNotFound {
+		t.Fatalf("foreign admin: status=%d", resp.Status)
+	}
+
+	if resp := kit.Call(http.MethodPut, fmt.Sprintf("/admin/v1/admin-users/%d/status", created.ID), map[string]int{"status": 0}, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("disable: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	resp = kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/admin-users/%d", created.ID), nil, tokenA)
+	if resp.Code != e.SUCCESS {
+		t.Fatalf("delete: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var n int64
+	kit.DB.Model(&models.AdminUser{}).Where("id = ?", created.ID).Count(&n)
+	if n != 0 {
+		t.Fatal("account without memberships was not deleted")
+	}
+}
+
+func TestAdminUserProtection(t *testing.T) {
+	kit := newRBACKit(t)
+	kit.DB.Create(&models.AdminUserRole{AdminUserID: testkit.PlainAdminID, RoleID: testkit.RoleAEditorID, TenantID: testkit.TenantA})
+	kit.DB.Create(&models.AdminUserRole{AdminUserID: testkit.SuperAdminID, RoleID: testkit.RoleAEditorID, TenantID: testkit.TenantA})
+	kit.DB.Model(&models.Tenant{}).Where("id = ?", testkit.TenantA).Update("owner_user_id", testkit.PlainAdminID)
+
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	cases := []struct {
+		name  string
+		token string
+		id    int
+		code  int
+	}{
+		{"self", tokenA, testkit.AdminAID, e.ERROR_ADMIN_SELF_OPERATION},
+		{"owner", tokenA, testkit.PlainAdminID, e.ERROR_ADMIN_OWNER_PROTECT},
+		{"super by tenant admin", tokenA, testkit.SuperAdminID, e.ERROR_INSUFFICIENT_PERMISSION},
+		{"last super", kit.SuperAdminToken(testkit.AdminAID, testkit.TenantA), testkit.SuperAdminID, e.ERROR_ADMIN_LAST_SUPER},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/admin-users/%d", tc.id), nil, tc.token)
+			if resp.Code != tc.code {
+				t.Fatalf("code=%d msg=%s, want %d", resp.Code, resp.Msg, tc.code)
+			}
+		})
+	}
+}
+
+// TestAdminMembershipScope 禁用只作用于当前租户的成员资格，跨租户账号的凭据只有超级管理员能改
+func TestAdminMembershipScope(t *testing.T) {
+	kit := newRBACKit(t)
+	kit.DB.Create(&models.AdminUserRole{AdminUserID: testkit.AdminBID, RoleID: testkit.AdminRoleID, TenantID: testkit.TenantA})
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	path := fmt.Sprintf("/admin/v1/admin-users/%d", testkit.AdminBID)
+
+	for name, body := range map[string]map[string]interface{}{
+		"password": {"password": "n3w-secret-pass", "email": "b@example.com"},
+		"email":    {"email": "taken@example.com"},
+	} {
+		if resp := kit.Call(http.MethodPut, path, body, tokenA); resp.Code != e.ERROR_INSUFFICIENT_PERMISSION {
+			t.Fatalf("cross-tenant %s change: code=%d msg=%s", name, resp.Code, resp.Msg)
+		}
+	}
+	if resp := kit.Call(http.MethodPut, path, map[string]interface{}{"email": "b@example.com", "department": "Ops"}, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("profile change: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+
+	if resp := kit.Call(http.MethodPut, path+"/status", map[string]int{"status": 0}, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("suspend: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var account models.AdminUser
+	kit.DB.First(&account, testkit.AdminBID)
+	if account.Status != 1 {
+		t.Fatalf("account status changed to %d", account.Status)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", kit.TenantAdminToken(testkit.AdminBID, testkit.TenantA)); resp.Status != http.StatusForbidden {
+		t.Fatalf("suspended tenant: status=%d", resp.Status)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)); resp.Code != e.SUCCESS {
+		t.Fatalf("other tenant: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+
+	var list struct {
+		AdminUsers []struct {
+			ID           uint `json:"id"`
+			MemberStatus *int `json:"member_status"`
+		} `json:"admin_users"`
+	}
+	if err := kit.Get("/admin/v1/admin-users", tokenA).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range list.AdminUsers {
+		if u.MemberStatus == nil || (u.ID == testkit.AdminBID) != (*u.MemberStatus == 0) {
+			t.Fatalf("member_status of %d = %v", u.ID, u.MemberStatus)
+		}
+	}
+}
+
+func TestAdminInvitation(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	resp := kit.Call(http.MethodPost, "/admin/v1/admin-users/invitations", map[string]interface{}{
+		"email": "new@example.com", "role_ids": []uint{testkit.RoleAEditorID}, "position": "Analyst",
+	}, tokenA)
+	if resp.Code != e.SUCCESS {
+		t.Fatalf("invite: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(kit.Mailbox.Last().Body)
+	if match == nil {
+		t.Fatalf("no token in mail: %q", kit.Mailbox.Last().Body)
+	}
+
+	accept := map[string]string{"token": match[1], "username": "newbie", "password": "s3cret-pass"}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/invitations/accept", accept, ""); resp.Code != e.SUCCESS {
+		t.Fatalf("accept: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	// 令牌只能使用一次
+	if resp := kit.Call(http.MethodPost, "/admin/v1/invitations/accept", accept, ""); resp.Code != e.ERROR_INVITATION_INVALID {
+		t.Fatalf("reuse: code=%d", resp.Code)
+	}
+
+	var member models.AdminUser
+	if err := kit.DB.Where("username = ?", "newbie").First(&member).Error; err != nil {
+		t.Fatal(err)
+	}
+	if member.Position != "Analyst" {
+		t.Fatalf("position=%q", member.Position)
+	}
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/admin-users/%d", member.ID), tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("invited admin not in tenant: code=%d", resp.Code)
+	}
+}
+
+func TestAdminUserBulk(t *testing.T) {
+	kit := newRBACKit(t)
+	kit.DB.Create(&models.AdminUserRole{AdminUserID: testkit.PlainAdminID, RoleID: testkit.RoleAEditorID, TenantID: testkit.TenantA})
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	var out struct {
+		Results []struct {
+			ID      uint `json:"id"`
+			Success bool `json:"success"`
+			Code    int  `json:"code"`
+		} `json:"results"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	}
+	resp := kit.Call(http.MethodPost, "/admin/v1/admin-users/bulk/status", map[string]interface{}{
+		"ids":    []int{testkit.PlainAdminID, testkit.AdminAID, testkit.AdminBID, testkit.PlainAdminID},
+		"status": 0,
+	}, tokenA)
+	if err := resp.Decode(&out); err != nil || resp.Code != e.SUCCESS {
+		t.Fatalf("bulk status: code=%d err=%v", resp.Code, err)
+	}
+	want := []int{e.SUCCESS, e.ERROR_ADMIN_SELF_OPERATION, e.ERROR_ADMIN_NOT_FOUND}
+	if len(out.Results) != len(want) || out.Succeeded != 1 || out.Failed != 2 {
+		t.Fatalf("bulk status results: %+v", out)
+	}
+	for i, code := range want {
+		if out.Results[i].Code != code {
+			t.Fatalf("result %d: %+v, want code %d", i, out.Results[i], code)
+		}
+	}
+
+	var logs []models.AuditLog
+	kit.DB.Where("action = ?", models.AuditAdminUserStatus).Order("id").Find(&logs)
+	if len(logs) != 3 || logs[0].BatchID == "" || logs[0].BatchID != logs[2].BatchID || !logs[0].Success || logs[1].Success || logs[0].ActorID != testkit.AdminAID {
+		t.Fatalf("audit logs: %+v", logs)
+	}
+
+	// 角色不属于当前租户时整批拒绝
+	resp = kit.Call(http.MethodPost, "/admin/v1/admin-users/bulk/roles", map[string]interface{}{
+		"ids":      []int{testkit.PlainAdminID},
+		"role_ids": []int{testkit.RoleBEditorID},
+	}, tokenA)
+	if resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("bulk roles with foreign role: status=%d", resp.Status)
+	}
+
+	resp = kit.Call(http.MethodPost, "/admin/v1/admin-users/bulk/delete", map[string]interface{}{"ids": []int{testkit.PlainAdminID}}, tokenA)
+	if err := resp.Decode(&out); err != nil || out.Succeeded != 1 {
+		t.Fatalf("bulk delete: %+v err=%v", out, err)
+	}
+	var n int64
+	kit.DB.Model(&models.AuditLog{}).Where("action = ? AND target_id = ?", models.AuditAdminUserRemove, testkit.PlainAdminID).Count(&n)
+	if n != 1 {
+		t.Fatalf("remove audit logs = %d", n)
+	}
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogController 审计日志控制器
+type AuditLogController struct {
+	auditLogRepo container.AuditLogRepository
+	logger       container.Logger
+}
+
+// NewAuditLogController 创建审计日志控制器实例
+func NewAuditLogController(auditLogRepo container.AuditLogRepository, logger container.Logger) *AuditLogController {
+	return &AuditLogController{auditLogRepo: auditLogRepo, logger: logger}
+}
+
+// GetAuditLogs 当前租户的审计日志，支持按动作、目标、批次号过滤
+func (alc *AuditLogController) GetAuditLogs(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	spec, err := app.ParseQuery(c, models.AuditLogListSchema)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	result, err := alc.auditLogRepo.ListByTenant(c.Request.Context(), uint(tenantVal.(int)), spec)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{
+		"audit_logs": spec.Project(result.Items),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
+	})
+}
@@ -2,7 +2,6 @@ package admin
 
 import (
 	"justus/internal/container"
-	"justus/internal/models"
 	"justus/pkg/app"
 	"justus/pkg/e"
 
@@ -11,12 +10,13 @@ import (
 
 // AuthController 提供认证相关接口
 type AuthController struct {
-	logger container.Logger
-	cache  container.Cache
+	adminUserService container.AdminUserService
+	tenantRepo       container.TenantRepository
+	logger           container.Logger
 }
 
-func NewAuthController(logger container.Logger, cache container.Cache) *AuthController {
-	return &AuthController{logger: logger, cache: cache}
+func NewAuthController(adminUserService container.AdminUserService, tenantRepo container.TenantRepository, logger container.Logger) *AuthController {
+	return &AuthController{adminUserService: adminUserService, tenantRepo: tenantRepo, logger: logger}
 }
 
 // Profile 返回当前管理员与租户信息
@@ -38,18 +38,17 @@ func (ac *AuthController) Profile(c *gin.Context) {
 	tenantID := uint(tenantVal.(int))
 
 	// 管理员基础信息
-	au := &models.AdminUser{ID: uint(adminUserID)}
-	adminInfo, err := au.GetAdminUserInfo()
+	adminInfo, err := ac.adminUserService.GetAdminUserInfo(c.Request.Context(), adminUserID)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_QUERY)
+		appG.Fail(err)
 		return
 	}
-	profile := adminInfo.Format()
+	profile := adminInfo.Format(c.Request.Context())
 
 	// 租户信息
-	tenant, err := models.GetTenantByID(tenantID)
+	tenant, err := ac.tenantRepo.GetByID(c.Request.Context(), tenantID)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_QUERY)
+		appG.Fail(err)
 		return
 	}
 
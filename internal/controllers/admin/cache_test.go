@@ -0,0 +1,80 @@
+package admin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"justus/internal/testkit"
+	"justus/pkg/e"
+)
+
+// TestClearCache 按分类清理缓存：菜单世代号始终保留，user 分类仅超级管理员清理全部租户时可用
+func TestClearCache(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	superToken := kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantA)
+
+	// testkit 的 Redis 前缀为 test:
+	seed := func() {
+		for key, val := range map[string]string{
+			"test:tenant:1:menus:gen":             "5",
+			"test:tenant:1:menus:whitelist":       "[1]",
+			"test:tenant:1:menus:tree:g5:admin:2": "[]",
+			"test:tenant:1:perms:admin:2:codes":   "[]",
+			"test:tenant:2:menus:gen":             "3",
+			"test:tenant:2:menus:whitelist":       "[1]",
+			"test:user:7:lang":                    "en",
+		} {
+			if err := kit.Redis.Set(key, val); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	exists := func(key string) bool { return kit.Redis.Exists(key) }
+
+	seed()
+	if resp := kit.Call(http.MethodPost, "/admin/v1/system/cache/clear?type=menus", nil, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("menus: status=%d code=%d msg=%s", resp.Status, resp.Code, resp.Msg)
+	}
+	if exists("test:tenant:1:menus:whitelist") || exists("test:tenant:1:menus:tree:g5:admin:2") {
+		t.Fatal("menus: tenant A menu cache not cleared")
+	}
+	if gen, _ := kit.Redis.Get("test:tenant:1:menus:gen"); gen != "5" {
+		t.Fatalf("menus: generation reset to %q", gen)
+	}
+	if !exists("test:tenant:1:perms:admin:2:codes") || !exists("test:tenant:2:menus:whitelist") {
+		t.Fatal("menus: cleared keys outside the category or tenant")
+	}
+
+	// 普通管理员不能清理平台级的用户缓存
+	if resp := kit.Call(http.MethodPost, "/admin/v1/system/cache/clear?type=user", nil, tokenA); resp.Status != http.StatusForbidden {
+		t.Fatalf("tenant user: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/system/cache/clear?type=nope", nil, tokenA); resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("unknown type: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	seed()
+	if resp := kit.Call(http.MethodPost, "/admin/v1/system/cache/clear?type=user", nil, superToken); resp.Code != e.SUCCESS {
+		t.Fatalf("super user: status=%d code=%d msg=%s", resp.Status, resp.Code, resp.Msg)
+	}
+	if exists("test:user:7:lang") || !exists("test:tenant:1:menus:whitelist") {
+		t.Fatal("super user: wrong keys cleared")
+	}
+
+	seed()
+	if resp := kit.Call(http.MethodPost, "/admin/v1/system/cache/clear?type=all", nil, superToken); resp.Code != e.SUCCESS {
+		t.Fatalf("super all: status=%d code=%d msg=%s", resp.Status, resp.Code, resp.Msg)
+	}
+	for _, key := range []string{"test:tenant:1:menus:whitelist", "test:tenant:1:menus:tree:g5:admin:2", "test:tenant:1:perms:admin:2:codes", "test:tenant:2:menus:whitelist", "test:user:7:lang"} {
+		if exists(key) {
+			t.Errorf("super all: %s not cleared", key)
+		}
+	}
+	if gen, _ := kit.Redis.Get("test:tenant:1:menus:gen"); gen != "5" {
+		t.Errorf("super all: tenant A generation reset to %q", gen)
+	}
+	if gen, _ := kit.Redis.Get("test:tenant:2:menus:gen"); gen != "3" {
+		t.Errorf("super all: tenant B generation reset to %q", gen)
+	}
+}
@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+	"justus/pkg/e"
+	"justus/pkg/export"
+	"justus/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataJobController 导入导出任务控制器
+type DataJobController struct {
+	dataJobService container.DataJobService
+	logger         container.Logger
+}
+
+// NewDataJobController 创建导入导出任务控制器实例
+func NewDataJobController(dataJobService container.DataJobService, logger container.Logger) *DataJobController {
+	return &DataJobController{dataJobService: dataJobService, logger: logger}
+}
+
+// DataJobView 任务详情，任务成功且有结果文件时附带下载地址
+type DataJobView struct {
+	models.DataJob
+	DownloadURL string `json:"download_url"`
+}
+
+func newDataJobView(job *models.DataJob) DataJobView {
+	view := DataJobView{DataJob: *job}
+	if job.Status == models.DataJobSucceeded && job.FileName != "" {
+		view.DownloadURL = export.GetExcelSignedUrl(job.FileName, job.TenantID)
+	}
+	return view
+}
+
+// Export 创建导出任务：format 为 xlsx（默认）或 csv，其余查询参数与对应列表接口一致
+func (dc *DataJobController) Export(c *gin.Context) {
+	appG := app.Gin{C: c}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	format := c.DefaultQuery("format", export.FormatXLSX)
+
+	job, err := dc.dataJobService.Export(c.Request.Context(), uint(tenantVal.(int)), c.Param("resource"), format, c.Request.URL.Query())
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Response(http.StatusAccepted, e.SUCCESS, gin.H{"job": newDataJobView(job)})
+}
+
+// Import 上传文件创建导入任务：multipart 字段 file，格式取 format 参数或文件扩展名
+func (dc *DataJobController) Import(c *gin.Context) {
+	appG := app.Gin{C: c}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	maxSize := int64(setting.AppSetting.ImportMaxSize)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		appG.InvalidParams()
+		return
+	}
+	if file.Size > maxSize {
+		appG.Error(e.ERROR_IMPORT_FILE_INVALID)
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = export.FormatOf(file.Filename)
+	}
+	src, err := file.Open()
+	if err != nil {
+		appG.Fail(e.Wrap(e.ERROR_FILE_READ_FAIL, err))
+		return
+	}
+	defer src.Close()
+
+	dc.logger.WithContext(c.Request.Context()).Infof("Admin importing %s: file=%s, size=%d", c.Param("resource"), file.Filename, file.Size)
+
+	job, err := dc.dataJobService.Import(c.Request.Context(), uint(tenantVal.(int)), c.Param("resource"), format, src)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Response(http.StatusAccepted, e.SUCCESS, gin.H{"job": newDataJobView(job)})
+}
+
+// GetDataJobs 当前租户的导入导出任务列表
+func (dc *DataJobController) GetDataJobs(c *gin.Context) {
+	appG := app.Gin{C: c}
+	spec, err := app.ParseQuery(c, models.DataJobListSchema)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	result, err := dc.dataJobService.List(c.Request.Context(), uint(tenantVal.(int)), spec)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	jobs := make([]DataJobView, 0, len(result.Items))
+	for i := range result.Items {
+		jobs = append(jobs, newDataJobView(&result.Items[i]))
+	}
+	appG.Success(gin.H{
+		"jobs":       spec.Project(jobs),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
+	})
+}
+
+// GetDataJob 任务详情，用于轮询进度
+func (dc *DataJobController) GetDataJob(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		appG.InvalidParams()
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	job, err := dc.dataJobService.Get(c.Request.Context(), uint(tenantVal.(int)), uint(id))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"job": newDataJobView(job)})
+}
@@ -0,0 +1,190 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+	"justus/pkg/export"
+	"justus/pkg/setting"
+	"justus/pkg/signurl"
+)
+
+type dataJobResp struct {
+	Job struct {
+		ID          uint   `json:"id"`
+		Status      int    `json:"status"`
+		Total       int64  `json:"total"`
+		Processed   int64  `json:"processed"`
+		Failed      int64  `json:"failed"`
+		FileName    string `json:"file_name"`
+		Error       string `json:"error"`
+		DownloadURL string `json:"download_url"`
+	} `json:"job"`
+}
+
+// waitDataJob 轮询任务直到结束
+func waitDataJob(t *testing.T, kit *testkit.Kit, token string, resp *testkit.Response) dataJobResp {
+	t.Helper()
+	if resp.Status != http.StatusAccepted {
+		t.Fatalf("create job: status=%d code=%d msg=%s", resp.Status, resp.Code, resp.Msg)
+	}
+	var job dataJobResp
+	if err := resp.Decode(&job); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := kit.Get(fmt.Sprintf("/admin/v1/data-jobs/%d", job.Job.ID), token).Decode(&job); err != nil {
+			t.Fatal(err)
+		}
+		if job.Job.Status >= models.DataJobSucceeded {
+			return job
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("job %d did not finish", job.Job.ID)
+	return job
+}
+
+// uploadBody 构造 multipart 上传请求体，返回 Content-Type
+func uploadBody(t *testing.T, name, content string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(fw, content)
+	_ = mw.Close()
+	return buf.Bytes(), mw.FormDataContentType()
+}
+
+func TestDataJobs(t *testing.T) {
+	app := *setting.AppSetting
+	t.Cleanup(func() { *setting.AppSetting = app })
+	setting.AppSetting.RuntimeRootPath = t.TempDir() + "/"
+	setting.AppSetting.ExportSavePath = "export/"
+	setting.AppSetting.ImportSavePath = "import/"
+	setting.AppSetting.ImportMaxSize = 1 << 20
+
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	// 导出遵循过滤条件与租户隔离
+	job := waitDataJob(t, kit, tokenA, kit.Call(http.MethodPost, "/admin/v1/exports/roles?format=csv&fields=id,name&status=1", nil, tokenA))
+	download := strings.TrimPrefix(job.Job.DownloadURL, setting.AppSetting.PrefixUrl)
+	if job.Job.Status != models.DataJobSucceeded || job.Job.Processed != 1 || !strings.HasPrefix(download, "/files/exports/"+job.Job.FileName+"?") {
+		t.Fatalf("roles export: %+v", job.Job)
+	}
+	w := kit.Do(http.MethodGet, download, nil, "")
+	if w.Code != http.StatusOK || w.Body.String() != "\uFEFFid,name\n2,a-editor\n" || !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+		t.Fatalf("download: status=%d body=%q", w.Code, w.Body.String())
+	}
+	// 导出目录不再作为静态目录公开；链接绑定租户，换成其他租户签名后按不存在处理
+	if w := kit.Do(http.MethodGet, "/export/"+job.Job.FileName, nil, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("static export: status=%d", w.Code)
+	}
+	forged := signurl.Sign("/files/exports/"+job.Job.FileName, signurl.Options{TenantID: testkit.TenantB})
+	if resp := kit.Get(forged, ""); resp.Status != http.StatusNotFound || resp.Code != e.ERROR_FILE_NOT_FOUND {
+		t.Fatalf("cross-tenant export: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	job = waitDataJob(t, kit, tokenA, kit.Call(http.MethodPost, "/admin/v1/exports/audit-logs", nil, tokenA))
+	r, err := export.OpenReader(export.FormatXLSX, filepath.Join(export.GetExcelFullPath(), job.Job.FileName))
+	if err != nil {
+		t.Fatalf("open xlsx export: %v (%+v)", err, job.Job)
+	}
+	header, err := r.Next()
+	_ = r.Close()
+	if err != nil || len(header) == 0 || header[0] != "id" {
+		t.Fatalf("xlsx header = %v, %v", header, err)
+	}
+
+	if resp := kit.Call(http.MethodPost, "/admin/v1/exports/permissions", nil, tokenA); resp.Code != e.ERROR_DATA_JOB_UNSUPPORTED {
+		t.Fatalf("unsupported export: code=%d", resp.Code)
+	}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/exports/roles?status=abc", nil, tokenA); resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid filter: status=%d", resp.Status)
+	}
+
+	// 导入逐行校验，错误行写入报告
+	body, contentType := uploadBody(t, "users.csv", "username,email,first_name\nalice_imp,alice@example.com,Alice\n1bad,bad@example.com,Bad\n\nbob_imp,bob@example.com,Bob\n")
+	kit.Header = http.Header{"Content-Type": {contentType}}
+	job = waitDataJob(t, kit, tokenA, kit.Call(http.MethodPost, "/admin/v1/imports/users", body, tokenA))
+	if job.Job.Status != models.DataJobSucceeded || job.Job.Processed != 3 || job.Job.Failed != 1 || job.Job.DownloadURL == "" {
+		t.Fatalf("users import: %+v", job.Job)
+	}
+	report, err := os.ReadFile(filepath.Join(export.GetExcelFullPath(), job.Job.FileName))
+	if err != nil || !strings.Contains(string(report), "3,username,") {
+		t.Fatalf("import report: %q, %v", report, err)
+	}
+	var n int64
+	kit.DB.Model(&models.User{}).Where("username IN ?", []string{"alice_imp", "bob_imp", "1bad"}).Count(&n)
+	if n != 2 {
+		t.Fatalf("imported users = %d", n)
+	}
+
+	body, contentType = uploadBody(t, "users.csv", "email\nx@example.com\n")
+	kit.Header = http.Header{"Content-Type": {contentType}}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/imports/users", body, tokenA); resp.Code != e.ERROR_IMPORT_FILE_INVALID {
+		t.Fatalf("missing header: code=%d", resp.Code)
+	}
+	kit.Header = nil
+
+	// 其他租户看不到该任务
+	tokenB := kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/data-jobs/%d", job.Job.ID), tokenB); resp.Code != e.ERROR_DATA_JOB_NOT_FOUND {
+		t.Fatalf("cross-tenant job: code=%d", resp.Code)
+	}
+}
+
+// TestDataJobLifecycle 启动时遗留任务记为失败并删除暂存文件；停止后新任务不再执行
+func TestDataJobLifecycle(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	jobs := container.GlobalContainer.DataJobService
+
+	source := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(source, []byte("username\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := []models.DataJob{
+		{TenantID: testkit.TenantA, Kind: models.DataJobImport, Resource: "users", Format: export.FormatCSV, Status: models.DataJobRunning, SourceFile: source},
+		{TenantID: testkit.TenantB, Kind: models.DataJobExport, Resource: "roles", Format: export.FormatCSV, Status: models.DataJobPending},
+		{TenantID: testkit.TenantA, Kind: models.DataJobExport, Resource: "roles", Format: export.FormatCSV, Status: models.DataJobSucceeded},
+	}
+	kit.DB.Create(&stale)
+	if err := jobs.RecoverStale(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var got []models.DataJob
+	kit.DB.Order("id").Find(&got)
+	if got[0].Status != models.DataJobFailed || got[1].Status != models.DataJobFailed || got[0].Error == "" || got[2].Status != models.DataJobSucceeded {
+		t.Fatalf("recovered jobs: %+v", got)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Fatalf("import file left behind: %v", err)
+	}
+
+	if err := jobs.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	job := waitDataJob(t, kit, tokenA, kit.Call(http.MethodPost, "/admin/v1/exports/roles?format=csv", nil, tokenA))
+	if job.Job.Status != models.DataJobFailed || job.Job.Error == "" {
+		t.Fatalf("job after shutdown: %+v", job.Job)
+	}
+}
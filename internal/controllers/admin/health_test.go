@@ -0,0 +1,41 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"justus/internal/testkit"
+)
+
+// TestHealthDetails 公开的 /health 只返回状态摘要，检查详情需登录后台获取
+func TestHealthDetails(t *testing.T) {
+	kit := newRBACKit(t)
+
+	resp := kit.Get("/health", "")
+	var public struct {
+		Status string                     `json:"status"`
+		Checks map[string]json.RawMessage `json:"checks"`
+	}
+	if resp.Status != http.StatusOK || resp.Decode(&public) != nil || public.Status != "healthy" {
+		t.Fatalf("public health: status=%d body=%s", resp.Status, resp.Data)
+	}
+	for name, raw := range public.Checks {
+		if string(raw) != `"healthy"` {
+			t.Fatalf("public check %s exposes %s", name, raw)
+		}
+	}
+
+	if resp := kit.Get("/admin/v1/system/health", ""); resp.Status != http.StatusUnauthorized {
+		t.Fatalf("anonymous details: status=%d", resp.Status)
+	}
+	resp = kit.Get("/admin/v1/system/health", kit.SuperAdminToken(testkit.SuperAdminID, 0))
+	var details struct {
+		Checks map[string]struct {
+			Details map[string]interface{} `json:"details"`
+		} `json:"checks"`
+	}
+	if resp.Status != http.StatusOK || resp.Decode(&details) != nil || details.Checks["database"].Details == nil {
+		t.Fatalf("admin details: status=%d body=%s", resp.Status, resp.Data)
+	}
+}
@@ -0,0 +1,74 @@
+package admin_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"strings"
+	"testing"
+
+	"justus/internal/testkit"
+	"justus/pkg/e"
+	"justus/pkg/setting"
+)
+
+func TestQrCodes(t *testing.T) {
+	app := *setting.AppSetting
+	t.Cleanup(func() { *setting.AppSetting = app })
+	setting.AppSetting.RuntimeRootPath = t.TempDir() + "/"
+	setting.AppSetting.QrCodeSavePath = "qrcode/"
+	setting.AppSetting.ImageMaxSize = 1 << 20
+	setting.AppSetting.ImageAllowExts = []string{".png", ".pdf"}
+
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	tokenB := kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)
+
+	if w := kit.Do(http.MethodGet, "/admin/v1/qrcodes?content=hi", nil, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous: status=%d", w.Code)
+	}
+
+	// 默认 PNG；相同参数命中缓存，ETag 可用于条件请求
+	path := "/admin/v1/qrcodes?content=" + "https%3A%2F%2Fexample.com%2Fa" + "&size=200&fg=%23112233"
+	w := kit.Do(http.MethodGet, path, nil, tokenA)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || w.Header().Get("ETag") == "" ||
+		!strings.HasPrefix(w.Header().Get("Cache-Control"), "private") {
+		t.Fatalf("png: status=%d headers=%v body=%s", w.Code, w.Header(), w.Body.String())
+	}
+	img, _, err := image.Decode(bytes.NewReader(w.Body.Bytes()))
+	if err != nil || img.Bounds().Dx() != 200 {
+		t.Fatalf("png decode: %v", err)
+	}
+	kit.Header = http.Header{"If-None-Match": {w.Header().Get("ETag")}}
+	cached := kit.Do(http.MethodGet, path, nil, tokenA)
+	kit.Header = nil
+	if cached.Code != http.StatusNotModified {
+		t.Fatalf("if-none-match: status=%d", cached.Code)
+	}
+
+	w = kit.Do(http.MethodGet, "/admin/v1/qrcodes?content=hi&format=svg&margin=0", nil, tokenA)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(w.Body.String(), "<svg") {
+		t.Fatalf("svg: status=%d body=%.80s", w.Code, w.Body.String())
+	}
+
+	// Logo 取本租户上传的图片，其他租户的 ID 按不存在处理
+	var logo uploadResp
+	if err := postUpload(t, kit, tokenA, "logo.png", "image", pngBytes(t, 16, 16, color.RGBA{G: 255, A: 255})).Decode(&logo); err != nil {
+		t.Fatal(err)
+	}
+	w = kit.Do(http.MethodGet, fmt.Sprintf("/admin/v1/qrcodes?content=hi&logo=%d", logo.Upload.ID), nil, tokenA)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logo: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/qrcodes?content=hi&logo=%d", logo.Upload.ID), tokenB); resp.Code != e.ERROR_UPLOAD_NOT_FOUND {
+		t.Fatalf("cross-tenant logo: code=%d", resp.Code)
+	}
+
+	for _, query := range []string{"", "content=hi&format=gif", "content=hi&size=10", "content=hi&fg=red", "content=" + strings.Repeat("x", 2048) + "&level=H"} {
+		if resp := kit.Get("/admin/v1/qrcodes?"+query, tokenA); resp.Status != http.StatusUnprocessableEntity {
+			t.Fatalf("%.40s: status=%d code=%d", query, resp.Status, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package admin_test
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+)
+
+func newRBACKit(t *testing.T) *testkit.Kit {
+	kit := testkit.New(t)
+	kit.Load(testkit.RBACFixtures())
+	return kit
+}
+
+func TestAdminAuth(t *testing.T) {
+	kit := newRBACKit(t)
+
+	cases := []struct {
+		name   string
+		token  string
+		status int
+		code   int
+	}{
+		{"missing token", "", http.StatusUnauthorized, e.INVALID_PARAMS},
+		{"expired token", kit.ExpiredToken(testkit.AdminAID), http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_TIMEOUT},
+		{"no admin role", kit.TenantAdminToken(testkit.PlainAdminID, testkit.TenantA), http.StatusForbidden, e.ERROR_PERMISSION_DENIED},
+		{"tenant admin", kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA), http.StatusOK, e.SUCCESS},
+		{"super admin", kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantA), http.StatusOK, e.SUCCESS},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := kit.Get("/admin/v1/access/codes", tc.token)
+			if resp.Status != tc.status || resp.Code != tc.code {
+				t.Fatalf("got status=%d code=%d, want status=%d code=%d", resp.Status, resp.Code, tc.status, tc.code)
+			}
+		})
+	}
+}
+
+func TestRoleTenantIsolation(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	var list struct {
+		Roles []struct {
+			ID       uint `json:"id"`
+			TenantID uint `json:"tenant_id"`
+		} `json:"roles"`
+	}
+	resp := kit.Get("/admin/v1/roles", tokenA)
+	if resp.Code != e.SUCCESS {
+		t.Fatalf("list roles: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if err := resp.Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range list.Roles {
+		if role.TenantID != testkit.TenantA {
+			t.Fatalf("tenant A listed role %d of tenant %d", role.ID, role.TenantID)
+		}
+	}
+
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleAEditorID), tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("own role: code=%d", resp.Code)
+	}
+	// 其他租户的角色对当前租户不可见，统一按不存在处理
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleBEditorID), tokenA); resp.Status != http.StatusNotFound || resp.Code != e.ERROR_ROLE_NOT_FOUND {
+		t.Fatalf("read tenant B role: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleBEditorID), nil, tokenA); resp.Status != http.StatusNotFound {
+		t.Fatalf("delete tenant B role: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if resp := kit.Call(http.MethodPut, fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleBEditorID), map[string]string{"name": "hijacked"}, tokenA); resp.Status != http.StatusNotFound {
+		t.Fatalf("update tenant B role: status=%d code=%d", resp.Status, resp.Code)
+	}
+	var kept int64
+	if err := kit.DB.Table("ay_roles").Where("id = ?", testkit.RoleBEditorID).Count(&kept).Error; err != nil || kept != 1 {
+		t.Fatalf("tenant B role missing after cross-tenant delete: %v", err)
+	}
+}
+
+func TestMenusRespectTenantWhitelist(t *testing.T) {
+	kit := newRBACKit(t)
+
+	menuNames := func(token string) []string {
+		t.Helper()
+		var data struct {
+			Menus []container.MenuNode `json:"menus"`
+		}
+		resp := kit.Get("/admin/v1/menus", token)
+		if resp.Code != e.SUCCESS {
+			t.Fatalf("menus: code=%d msg=%s", resp.Code, resp.Msg)
+		}
+		if err := resp.Decode(&data); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range data.Menus {
+			names = append(names, m.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// 两个管理员拥有相同角色，可见菜单由各自租户的白名单裁剪
+	if got := fmt.Sprint(menuNames(kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA))); got != "[admin.dashboard admin.users]" {
+		t.Fatalf("tenant A menus = %s", got)
+	}
+	if got := fmt.Sprint(menuNames(kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB))); got != "[admin.dashboard]" {
+		t.Fatalf("tenant B menus = %s", got)
+	}
+}
+
+func TestTenantMenusRequireSuperAdmin(t *testing.T) {
+	kit := newRBACKit(t)
+	path := fmt.Sprintf("/admin/v1/tenants/%d/menus", testkit.TenantB)
+	body := map[string]interface{}{"permission_ids": []uint{testkit.PermDashboardID, testkit.PermRolesID}}
+
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	if resp := kit.Call(http.MethodPut, path, body, tokenA); resp.Code != e.ERROR_PERMISSION_DENIED {
+		t.Fatalf("tenant admin update: code=%d, want %d", resp.Code, e.ERROR_PERMISSION_DENIED)
+	}
+
+	super := kit.SuperAdminToken(testkit.SuperAdminID, 0)
+	if resp := kit.Call(http.MethodPut, path, body, super); resp.Code != e.SUCCESS {
+		t.Fatalf("super admin update: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var data struct {
+		PermissionIDs []uint `json:"permission_ids"`
+	}
+	resp := kit.Get(path, super)
+	if err := resp.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.PermissionIDs) != 2 {
+		t.Fatalf("tenant B whitelist = %v", data.PermissionIDs)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	// 字段校验失败：422 + 字段明细
+	resp := kit.Call(http.MethodPost, "/admin/v1/roles", map[string]string{"description": "no name"}, tokenA)
+	if resp.Status != http.StatusUnprocessableEntity || resp.Code != e.ERROR_DATA_VALIDATION {
+		t.Fatalf("create role without name: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "name" || resp.Errors[0].Rule != "required" {
+		t.Fatalf("field errors = %+v", resp.Errors)
+	}
+
+	// 请求体无法解析：400
+	if resp := kit.Call(http.MethodPost, "/admin/v1/roles", "{", tokenA); resp.Status != http.StatusBadRequest || resp.Code != e.INVALID_PARAMS {
+		t.Fatalf("malformed body: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	// 仍有管理员绑定的角色不能删除：409
+	bind := models.AdminUserRole{AdminUserID: testkit.AdminAID, RoleID: testkit.RoleAEditorID, TenantID: testkit.TenantA}
+	if err := kit.DB.Create(&bind).Error; err != nil {
+		t.Fatal(err)
+	}
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleAEditorID), nil, tokenA); resp.Status != http.StatusConflict || resp.Code != e.ERROR_ROLE_IN_USE {
+		t.Fatalf("delete role in use: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	// 内部原因不返回给客户端
+	w := kit.Do(http.MethodDelete, fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleAEditorID), nil, tokenA)
+	if body := w.Body.String(); strings.Contains(body, "assigned") {
+		t.Fatalf("response leaks internal cause: %s", body)
+	}
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		msg    string
+		field  string
+	}{
+		{"default chinese", "", "", "数据验证失败", "不能为空"},
+		{"lange header", "lange", "en", "Data validation failed", "is required"},
+		{"accept-language", "Accept-Language", "zh-TW,zh;q=0.9", "數據驗證失敗", "不能為空"},
+		{"missing translation falls back to english", "lange", "ja", "Data validation failed", "is required"},
+		{"unknown locale keeps default", "lange", "xx", "数据验证失败", "不能为空"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kit.Header = http.Header{}
+			if tc.header != "" {
+				kit.Header.Set(tc.header, tc.value)
+			}
+			resp := kit.Call(http.MethodPost, "/admin/v1/roles", map[string]string{}, tokenA)
+			if resp.Msg != tc.msg || len(resp.Errors) != 1 || resp.Errors[0].Message != tc.field {
+				t.Fatalf("msg=%q errors=%+v, want msg=%q field=%q", resp.Msg, resp.Errors, tc.msg, tc.field)
+			}
+		})
+	}
+}
+
+func TestRoleListQuery(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	for i := 0; i < 5; i++ {
+		role := models.Role{TenantID: testkit.TenantA, Name: fmt.Sprintf("query_%d", i), DisplayName: "Query", Status: 1, SortOrder: i % 2}
+		if err := kit.DB.Create(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type page struct {
+		Roles []struct {
+			ID   uint   `json:"id"`
+			Name string `json:"name"`
+		} `json:"roles"`
+		Pagination struct {
+			Total      *int64 `json:"total"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		} `json:"pagination"`
+	}
+	list := func(path string) page {
+		t.Helper()
+		var p page
+		resp := kit.Get(path, tokenA)
+		if resp.Code != e.SUCCESS {
+			t.Fatalf("%s: code=%d errors=%v", path, resp.Code, resp.Errors)
+		}
+		if err := resp.Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	first := list("/admin/v1/roles?limit=100")
+	if first.Pagination.Total == nil || int(*first.Pagination.Total) != len(first.Roles) || first.Pagination.HasMore {
+		t.Fatalf("page mode: total=%v roles=%d has_more=%v", first.Pagination.Total, len(first.Roles), first.Pagination.HasMore)
+	}
+
+	// 游标逐页遍历应与一次取全的顺序完全一致，且不统计总数
+	var walked []uint
+	path := "/admin/v1/roles?limit=2"
+	for {
+		p := list(path)
+		for _, r := range p.Roles {
+			walked = append(walked, r.ID)
+		}
+		if !p.Pagination.HasMore {
+			break
+		}
+		path = "/admin/v1/roles?limit=2&cursor=" + p.Pagination.NextCursor
+		if p := list(path); p.Pagination.Total != nil {
+			t.Fatalf("cursor mode reported total")
+		}
+	}
+	if len(walked) != len(first.Roles) {
+		t.Fatalf("cursor walk returned %d roles, want %d", len(walked), len(first.Roles))
+	}
+	for i, r := range first.Roles {
+		if walked[i] != r.ID {
+			t.Fatalf("cursor order differs at %d: %v vs %v", i, walked, first.Roles)
+		}
+	}
+
+	filtered := list("/admin/v1/roles?filter[name][like]=query_&sort=-name&fields=id,name")
+	if len(filtered.Roles) != 5 || filtered.Roles[0].Name != "query_4" {
+		t.Fatalf("filter/sort: %+v", filtered.Roles)
+	}
+
+	resp := kit.Get("/admin/v1/roles?filter[tenant_id]=2&sort=password", tokenA)
+	if resp.Status != http.StatusUnprocessableEntity || len(resp.Errors) != 2 {
+		t.Fatalf("bad query: status=%d errors=%v", resp.Status, resp.Errors)
+	}
+	if resp := kit.Get("/admin/v1/roles?cursor=bogus", tokenA); resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("bad cursor: status=%d", resp.Status)
+	}
+}
+
+// TestCustomRuleDTOs 权限标识与租户编码在接口上按自定义规则校验
+func TestCustomRuleDTOs(t *testing.T) {
+	kit := newRBACKit(t)
+	super := kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantA)
+
+	perm := map[string]interface{}{"name": "admin.report.export", "display_name": "导出报表", "method": "POST"}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/roles/permissions", perm, kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)); resp.Code != e.ERROR_PERMISSION_DENIED {
+		t.Fatalf("tenant admin create permission: code=%d", resp.Code)
+	}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/roles/permissions", perm, super); resp.Code != e.SUCCESS {
+		t.Fatalf("create permission: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var created models.Permission
+	if err := kit.DB.Where("name = ?", "admin.report.export").First(&created).Error; err != nil || created.Module != "admin" || created.Action != "report" || created.Resource != "export" {
+		t.Fatalf("created permission: %+v %v", created, err)
+	}
+	if resp := kit.Call(http.MethodPost, "/admin/v1/roles/permissions", perm, super); resp.Status != http.StatusConflict || resp.Code != e.ERROR_PERMISSION_EXIST {
+		t.Fatalf("duplicate permission: status=%d code=%d", resp.Status, resp.Code)
+	}
+	perm["name"] = "admin.report"
+	if resp := kit.Call(http.MethodPost, "/admin/v1/roles/permissions", perm, super); resp.Status != http.StatusUnprocessableEntity || len(resp.Errors) != 1 || resp.Errors[0].Field != "name" {
+		t.Fatalf("two segment permission: status=%d errors=%+v", resp.Status, resp.Errors)
+	}
+
+	tenantPath := fmt.Sprintf("/admin/v1/tenants/%d", testkit.TenantA)
+	if resp := kit.Call(http.MethodPatch, tenantPath, map[string]interface{}{"code": "Tenant_A"}, super); resp.Status != http.StatusUnprocessableEntity || len(resp.Errors) != 1 || resp.Errors[0].Field != "code" {
+		t.Fatalf("invalid tenant code: status=%d errors=%+v", resp.Status, resp.Errors)
+	}
+	if resp := kit.Call(http.MethodPatch, tenantPath, map[string]interface{}{"code": "tenant-b"}, super); resp.Code != e.ERROR_TENANT_CODE_EXIST {
+		t.Fatalf("duplicate tenant code: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if resp := kit.Call(http.MethodPatch, tenantPath, map[string]interface{}{"code": "acme"}, super); resp.Code != e.SUCCESS {
+		t.Fatalf("rename tenant code: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+}
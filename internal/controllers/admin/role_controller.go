@@ -1,37 +1,64 @@
 package admin
 
 import (
+	"context"
 	"strconv"
+	"strings"
 
 	"justus/internal/container"
 	"justus/internal/models"
 	"justus/pkg/app"
+	"justus/pkg/e"
+	"justus/pkg/rediskey"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RoleController 角色管理控制器
 type RoleController struct {
-	logger container.Logger
-	cache  container.Cache
+	roleRepo       container.RoleRepository
+	permissionRepo container.PermissionRepository
+	menuService    container.MenuService
+	logger         container.Logger
+	cache          container.Cache
 }
 
 // NewRoleController 创建角色管理控制器实例
-func NewRoleController(logger container.Logger, cache container.Cache) *RoleController {
+func NewRoleController(roleRepo container.RoleRepository, permissionRepo container.PermissionRepository, menuService container.MenuService, logger container.Logger, cache container.Cache) *RoleController {
 	return &RoleController{
-		logger: logger,
-		cache:  cache,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		menuService:    menuService,
+		logger:         logger,
+		cache:          cache,
 	}
 }
 
 // RoleRequest 角色请求结构体
 type RoleRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	Description string   `json:"description"`
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=500"`
 	Permissions []string `json:"permissions"`
 	Status      int      `json:"status"`
 }
 
+// PatchRoleRequest 角色部分更新请求，未提供的字段保持不变
+type PatchRoleRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Status      *int    `json:"status"`
+}
+
+// PermissionRequest 创建权限请求，模块、操作与资源由权限标识的三段拆出
+type PermissionRequest struct {
+	Name        string `json:"name" binding:"required,max=100,permname"`
+	DisplayName string `json:"display_name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Route       string `json:"route" binding:"max=200"`
+	Method      string `json:"method" binding:"omitempty,oneof=GET POST PUT PATCH DELETE *"`
+	SortOrder   int    `json:"sort_order"`
+}
+
 // UpdateRolePermissionsRequest 更新角色权限请求
 type UpdateRolePermissionsRequest struct {
 	PermissionIDs []uint `json:"permission_ids" binding:"required"`
@@ -41,24 +68,12 @@ type UpdateRolePermissionsRequest struct {
 func (rc *RoleController) GetRoles(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		appG.InvalidParams()
-		return
-	}
-
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		appG.InvalidParams()
+	spec, err := app.ParseQuery(c, models.RoleListSchema)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
-	keyword := c.Query("keyword")
-	status := c.Query("status")
-
 	tenantVal, ok := c.Get("tenantId")
 	if !ok {
 		appG.InvalidParams()
@@ -66,25 +81,18 @@ func (rc *RoleController) GetRoles(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin getting roles list: tenant_id=%d, page=%d, limit=%d, keyword=%s, status=%s", tenantID, page, limit, keyword, status)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin getting roles list: tenant_id=%d, page=%d, limit=%d, keyword=%s, cursor=%t", tenantID, spec.Page, spec.Limit, spec.Keyword, spec.Cursor())
 
-	roles, total, err := models.ListTenantRoles(tenantID, keyword, status, page, limit)
+	result, err := rc.roleRepo.ListByTenant(c.Request.Context(), tenantID, spec)
 	if err != nil {
-		appG.Error(50000)
+		appG.Fail(err)
 		return
 	}
 
 	appG.Success(gin.H{
-		"roles": roles,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-		"filters": gin.H{
-			"keyword": keyword,
-			"status":  status,
-		},
+		"roles":      spec.Project(result.Items),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
 	})
 }
 
@@ -106,18 +114,19 @@ func (rc *RoleController) GetRole(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin getting role details: tenant_id=%d, id=%d", tenantID, id)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin getting role details: tenant_id=%d, id=%d", tenantID, id)
 
-	role, err := models.GetRoleByIDForTenant(uint(id), tenantID)
+	role, err := rc.roleRepo.GetByIDForTenant(c.Request.Context(), uint(id), tenantID)
 	if err != nil {
-		appG.Error(50000)
+		appG.Fail(err)
 		return
 	}
-	permIDs, err := models.GetPermissionIDsOfRole(role.ID)
+	permIDs, err := rc.roleRepo.GetPermissionIDs(c.Request.Context(), role.ID)
 	if err != nil {
-		appG.Error(50000)
+		appG.Fail(err)
 		return
 	}
+	app.SetETag(c, role.Version)
 	appG.Success(gin.H{"role": role, "permission_ids": permIDs})
 }
 
@@ -126,9 +135,9 @@ func (rc *RoleController) CreateRole(c *gin.Context) {
 	appG := app.Gin{C: c}
 
 	var req RoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		rc.logger.Errorf("Invalid role creation request: %v", err)
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		rc.logger.WithContext(c.Request.Context()).Errorf("Invalid role creation request: %v", err)
+		appG.Fail(err)
 		return
 	}
 
@@ -139,32 +148,62 @@ func (rc *RoleController) CreateRole(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin creating role: tenant_id=%d, name=%s", tenantID, req.Name)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin creating role: tenant_id=%d, name=%s", tenantID, req.Name)
 
 	// 创建角色
-	role, err := models.CreateRoleForTenant(tenantID, req.Name, req.Name, req.Description, req.Status)
-	if err != nil {
-		appG.Error(50000)
+	role := &models.Role{
+		TenantID:    tenantID,
+		Name:        req.Name,
+		DisplayName: req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+	}
+	if err := rc.roleRepo.Create(c.Request.Context(), role); err != nil {
+		appG.Fail(err)
 		return
 	}
 	appG.Success(gin.H{"message": "角色创建成功", "role_id": role.ID})
 }
 
-// UpdateRole 更新角色
+// UpdateRole 整体更新角色，If-Match 携带版本时做乐观锁校验
 func (rc *RoleController) UpdateRole(c *gin.Context) {
 	appG := app.Gin{C: c}
 
+	var req RoleRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		rc.logger.WithContext(c.Request.Context()).Errorf("Invalid role update request: %v", err)
+		appG.Fail(err)
+		return
+	}
+	rc.saveRole(c, models.RolePatch{DisplayName: &req.Name, Description: &req.Description, Status: &req.Status})
+}
+
+// PatchRole 部分更新角色，只修改请求中提供的字段
+func (rc *RoleController) PatchRole(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var req PatchRoleRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		rc.logger.WithContext(c.Request.Context()).Errorf("Invalid role patch request: %v", err)
+		appG.Fail(err)
+		return
+	}
+	rc.saveRole(c, models.RolePatch{DisplayName: req.Name, Description: req.Description, Status: req.Status})
+}
+
+// saveRole 按 If-Match 版本更新本租户角色并返回新的 ETag
+func (rc *RoleController) saveRole(c *gin.Context, patch models.RolePatch) {
+	appG := app.Gin{C: c}
+
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		appG.InvalidParams()
 		return
 	}
-
-	var req RoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		rc.logger.Errorf("Invalid role update request: %v", err)
-		appG.InvalidParams()
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
@@ -175,13 +214,15 @@ func (rc *RoleController) UpdateRole(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin updating role: tenant_id=%d, id=%d, name=%s", tenantID, id, req.Name)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin updating role: tenant_id=%d, id=%d, version=%d", tenantID, id, version)
 
-	if err := models.UpdateRoleForTenant(uint(id), tenantID, req.Name, req.Description, req.Status); err != nil {
-		appG.Error(50000)
+	role, err := rc.roleRepo.UpdateForTenant(c.Request.Context(), uint(id), tenantID, version, patch)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
-	appG.Success(gin.H{"message": "角色更新成功", "role_id": id})
+	app.SetETag(c, role.Version)
+	appG.Success(gin.H{"message": "角色更新成功", "role_id": id, "role": role})
 }
 
 // UpdateRolePermissions 覆盖式更新角色权限
@@ -203,22 +244,22 @@ func (rc *RoleController) UpdateRolePermissions(c *gin.Context) {
 	tenantID := uint(tenantVal.(int))
 
 	// 校验角色归属
-	role, err := models.GetRoleByIDForTenant(uint(id), tenantID)
-	if err != nil || role == nil {
-		appG.Error(50000)
+	if _, err := rc.roleRepo.GetByIDForTenant(c.Request.Context(), uint(id), tenantID); err != nil {
+		appG.Fail(err)
 		return
 	}
 
 	var req UpdateRolePermissionsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
 		return
 	}
 
-	if err := models.ReplaceRolePermissions(uint(id), req.PermissionIDs); err != nil {
-		appG.Error(50000)
+	if err := rc.roleRepo.ReplacePermissions(c.Request.Context(), uint(id), req.PermissionIDs); err != nil {
+		appG.Fail(err)
 		return
 	}
+	rc.invalidateTenantAuthz(c.Request.Context(), tenantID)
 	appG.Success(gin.H{"message": "权限更新成功", "role_id": id})
 }
 
@@ -240,60 +281,82 @@ func (rc *RoleController) DeleteRole(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin deleting role: tenant_id=%d, id=%d", tenantID, id)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin deleting role: tenant_id=%d, id=%d", tenantID, id)
 
-	if err := models.DeleteRoleForTenant(uint(id), tenantID); err != nil {
-		appG.Error(50000)
+	if err := rc.roleRepo.DeleteForTenant(c.Request.Context(), uint(id), tenantID); err != nil {
+		appG.Fail(err)
 		return
 	}
+	rc.invalidateTenantAuthz(c.Request.Context(), tenantID)
 	appG.Success(gin.H{"message": "角色删除成功", "role_id": id})
 }
 
-// GetPermissions 获取所有可用权限列表
+// GetPermissions 获取所有可用权限列表（按模块分组）
 func (rc *RoleController) GetPermissions(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	rc.logger.Info("Admin getting permissions list")
-
-	// TODO: 实现获取权限列表逻辑
-	permissions := []gin.H{
-		{
-			"group":      "user",
-			"group_name": "用户管理",
-			"permissions": []gin.H{
-				{"name": "user.read", "display_name": "查看用户", "description": "查看用户列表和详情"},
-				{"name": "user.write", "display_name": "编辑用户", "description": "创建、更新用户信息"},
-				{"name": "user.delete", "display_name": "删除用户", "description": "删除用户账户"},
-				{"name": "user.status", "display_name": "管理用户状态", "description": "启用/禁用用户账户"},
-			},
-		},
-		{
-			"group":      "system",
-			"group_name": "系统管理",
-			"permissions": []gin.H{
-				{"name": "system.read", "display_name": "查看系统信息", "description": "查看系统状态和统计"},
-				{"name": "system.write", "display_name": "系统配置", "description": "修改系统配置"},
-				{"name": "system.logs", "display_name": "查看日志", "description": "查看系统日志"},
-				{"name": "system.cache", "display_name": "缓存管理", "description": "清理系统缓存"},
-			},
-		},
-		{
-			"group":      "role",
-			"group_name": "角色权限",
-			"permissions": []gin.H{
-				{"name": "role.read", "display_name": "查看角色", "description": "查看角色列表和详情"},
-				{"name": "role.write", "display_name": "编辑角色", "description": "创建、更新角色"},
-				{"name": "role.delete", "display_name": "删除角色", "description": "删除角色"},
-				{"name": "role.assign", "display_name": "分配角色", "description": "为用户分配角色"},
-			},
-		},
+	rc.logger.WithContext(c.Request.Context()).Info("Admin getting permissions list")
+
+	perms, err := rc.permissionRepo.List(c.Request.Context())
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	groups := []gin.H{}
+	index := map[string]int{}
+	for _, p := range perms {
+		i, ok := index[p.Module]
+		if !ok {
+			i = len(groups)
+			index[p.Module] = i
+			groups = append(groups, gin.H{"group": p.Module, "permissions": []models.Permission{}})
+		}
+		groups[i]["permissions"] = append(groups[i]["permissions"].([]models.Permission), p)
 	}
 
 	appG.Success(gin.H{
-		"permissions": permissions,
+		"permissions": groups,
 	})
 }
 
+// CreatePermission 创建权限（仅超级管理员，权限定义为平台级数据）
+func (rc *RoleController) CreatePermission(c *gin.Context) {
+	appG := app.Gin{C: c}
+	if isSuper, _ := c.Get("isSuper"); isSuper != true {
+		appG.Error(e.ERROR_PERMISSION_DENIED)
+		return
+	}
+
+	var req PermissionRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	rc.logger.WithContext(c.Request.Context()).Infof("Super admin creating permission: %s", req.Name)
+
+	// permname 规则已保证恰好三段
+	parts := strings.Split(req.Name, ".")
+	perm := &models.Permission{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Module:      parts[0],
+		Action:      parts[1],
+		Resource:    parts[2],
+		Route:       req.Route,
+		Method:      req.Method,
+		SortOrder:   req.SortOrder,
+		Level:       1,
+	}
+	if err := rc.permissionRepo.Create(c.Request.Context(), perm); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"message": "权限创建成功", "permission": perm})
+}
+
 // AssignRole 为用户分配角色
 func (rc *RoleController) AssignRole(c *gin.Context) {
 	appG := app.Gin{C: c}
@@ -302,9 +365,9 @@ func (rc *RoleController) AssignRole(c *gin.Context) {
 		AdminUserID int   `json:"admin_user_id" binding:"required"`
 		RoleIDs     []int `json:"role_ids" binding:"required"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		rc.logger.Errorf("Invalid role assignment request: %v", err)
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		rc.logger.WithContext(c.Request.Context()).Errorf("Invalid role assignment request: %v", err)
+		appG.Fail(err)
 		return
 	}
 
@@ -316,16 +379,16 @@ func (rc *RoleController) AssignRole(c *gin.Context) {
 	}
 	tenantID := uint(tenantVal.(int))
 
-	rc.logger.Infof("Admin assigning roles: tenant_id=%d, admin_user_id=%d, role_ids=%v", tenantID, req.AdminUserID, req.RoleIDs)
+	rc.logger.WithContext(c.Request.Context()).Infof("Admin assigning roles: tenant_id=%d, admin_user_id=%d, role_ids=%v", tenantID, req.AdminUserID, req.RoleIDs)
 
 	// 校验角色是否属于该租户或系统级
-	roles, err := models.GetRolesByIDsAndTenant(req.RoleIDs, tenantID)
+	roles, err := rc.roleRepo.GetByIDsAndTenant(c.Request.Context(), req.RoleIDs, tenantID)
 	if err != nil {
-		appG.Error(50000)
+		appG.Fail(err)
 		return
 	}
 	if len(roles) != len(req.RoleIDs) {
-		appG.InvalidParams()
+		appG.Fail(e.Validation(e.FieldError{Field: "role_ids", Rule: "exists", Message: "包含不存在或不属于当前租户的角色"}))
 		return
 	}
 
@@ -334,12 +397,13 @@ func (rc *RoleController) AssignRole(c *gin.Context) {
 	for _, rid := range req.RoleIDs {
 		roleIDsUint = append(roleIDsUint, uint(rid))
 	}
-	if err := models.AssignRolesToAdminInTenant(uint(req.AdminUserID), tenantID, roleIDsUint); err != nil {
-		appG.Error(50000)
+	if err := rc.roleRepo.AssignToAdminInTenant(c.Request.Context(), uint(req.AdminUserID), tenantID, roleIDsUint); err != nil {
+		appG.Fail(err)
 		return
 	}
+	rc.invalidateTenantAuthz(c.Request.Context(), tenantID)
 
-	rc.logger.Infof("Roles assigned successfully: admin_user_id=%d", req.AdminUserID)
+	rc.logger.WithContext(c.Request.Context()).Infof("Roles assigned successfully: admin_user_id=%d", req.AdminUserID)
 
 	appG.Success(gin.H{
 		"message":       "角色分配成功",
@@ -347,3 +411,13 @@ func (rc *RoleController) AssignRole(c *gin.Context) {
 		"role_ids":      req.RoleIDs,
 	})
 }
+
+// invalidateTenantAuthz 角色或授权变化后失效租户权限码缓存与菜单树缓存
+func (rc *RoleController) invalidateTenantAuthz(ctx context.Context, tenantID uint) {
+	if rc.cache != nil {
+		if _, err := rc.cache.DelByPattern(ctx, rediskey.TenantPermsPattern(tenantID)); err != nil {
+			rc.logger.WithContext(ctx).Errorf("purge tenant perms cache error: tenant_id=%d, err=%v", tenantID, err)
+		}
+	}
+	rc.menuService.InvalidateTenant(ctx, tenantID)
+}
@@ -1,23 +1,45 @@
 package admin
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
 	"justus/internal/container"
+	"justus/internal/global"
+	"justus/internal/health"
+	"justus/internal/middleware/stats"
+	"justus/internal/models"
+	"justus/internal/service"
 	"justus/pkg/app"
+	"justus/pkg/e"
+	"justus/pkg/gredis"
+	"justus/pkg/setting"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SystemController 系统管理控制器
 type SystemController struct {
-	logger container.Logger
-	cache  container.Cache
+	cacheService    container.CacheService
+	logQueryService container.LogQueryService
+	healthRegistry  *health.Registry
+	logger          container.Logger
+	cache           container.Cache
 }
 
 // NewSystemController 创建系统管理控制器实例
-func NewSystemController(logger container.Logger, cache container.Cache) *SystemController {
+func NewSystemController(cacheService container.CacheService, logQueryService container.LogQueryService, healthRegistry *health.Registry, logger container.Logger, cache container.Cache) *SystemController {
 	return &SystemController{
-		logger: logger,
-		cache:  cache,
+		cacheService:    cacheService,
+		logQueryService: logQueryService,
+		healthRegistry:  healthRegistry,
+		logger:          logger,
+		cache:           cache,
 	}
 }
 
@@ -25,18 +47,21 @@ func NewSystemController(logger container.Logger, cache container.Cache) *System
 func (sc *SystemController) GetSystemInfo(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	sc.logger.Info("Admin requesting system info")
+	sc.logger.WithContext(c.Request.Context()).Info("Admin requesting system info")
 
-	// TODO: 实现获取系统信息逻辑
-	// 可以包括：服务器信息、版本信息、运行状态等
+	uptime := time.Since(global.StartTime)
 	systemInfo := gin.H{
-		"version":      "1.0.0",
-		"environment":  "development", // 或从配置文件读取
-		"uptime":       "0d 0h 0m",    // 可以计算服务运行时间
-		"memory_usage": "0MB",         // 可以获取内存使用情况
-		"cpu_usage":    "0%",          // 可以获取CPU使用情况
-		"database":     "connected",   // 数据库连接状态
-		"redis":        "connected",   // Redis连接状态
+		"version":        global.Build.Version,
+		"build_time":     global.Build.BuildTime,
+		"go_version":     runtime.Version(),
+		"environment":    currentEnvironment(),
+		"run_mode":       setting.ServerSetting.RunMode,
+		"started_at":     global.StartTime.Format("2006-01-02 15:04:05"),
+		"uptime":         formatUptime(uptime),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"runtime":        runtimeInfo(),
+		"database":       databaseInfo(c.Request.Context()),
+		"redis":          redisInfo(c.Request.Context()),
 	}
 
 	appG.Success(gin.H{
@@ -49,82 +74,209 @@ func (sc *SystemController) GetSystemInfo(c *gin.Context) {
 func (sc *SystemController) GetSystemStats(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	sc.logger.Info("Admin requesting system stats")
+	sc.logger.WithContext(c.Request.Context()).Info("Admin requesting system stats")
 
-	// TODO: 实现获取系统统计逻辑
-	// 可以包括：用户数量、活跃用户、请求统计等
-	stats := gin.H{
-		"total_users":      0, // 总用户数
-		"active_users":     0, // 活跃用户数
-		"total_requests":   0, // 总请求数
-		"requests_today":   0, // 今日请求数
-		"error_rate":       0, // 错误率
-		"average_response": 0, // 平均响应时间
-		"database_queries": 0, // 数据库查询数
-		"cache_hit_rate":   0, // 缓存命中率
+	totalUsers, err := models.CountUsers(c.Request.Context())
+	if err != nil {
+		sc.logger.WithContext(c.Request.Context()).Errorf("count users error: %v", err)
+		appG.Fail(e.Wrap(e.ERROR_DATABASE_QUERY, err))
+		return
+	}
+	activeUsers, err := models.CountActiveUsersSince(c.Request.Context(), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		sc.logger.WithContext(c.Request.Context()).Errorf("count active users error: %v", err)
+		appG.Fail(e.Wrap(e.ERROR_DATABASE_QUERY, err))
+		return
+	}
+	totalAdmins, err := models.CountAdminUsers(c.Request.Context())
+	if err != nil {
+		sc.logger.WithContext(c.Request.Context()).Errorf("count admin users error: %v", err)
+		appG.Fail(e.Wrap(e.ERROR_DATABASE_QUERY, err))
+		return
+	}
+
+	requests := stats.GetSnapshot()
+	statsData := gin.H{
+		"total_users":      totalUsers,
+		"active_users":     activeUsers, // 近24小时登录过的用户
+		"total_admins":     totalAdmins,
+		"total_requests":   requests.TotalRequests,
+		"requests_today":   requests.RequestsToday,
+		"errors_today":     requests.ErrorsToday,
+		"in_flight":        requests.InFlight,
+		"error_rate":       requests.ErrorRate,
+		"average_response": requests.AverageResponseMs, // 毫秒
+		"cache_hit_rate":   redisHitRate(c.Request.Context()),
+	}
+	if dbStats, ok := databaseStats(); ok {
+		statsData["database_pool"] = dbStats
 	}
 
 	appG.Success(gin.H{
 		"message": "系统统计获取成功",
-		"stats":   stats,
+		"stats":   statsData,
 	})
 }
 
 // GetSystemLogs 获取系统日志
+// 支持 level、start、end、user_id、path、q 过滤，cursor + limit 游标分页；普通管理员只能查看本租户日志
 func (sc *SystemController) GetSystemLogs(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 分页参数
-	level := c.DefaultQuery("level", "all") // error, warn, info, debug, all
-	limit := c.DefaultQuery("limit", "100")
-
-	sc.logger.Infof("Admin requesting system logs: level=%s, limit=%s", level, limit)
+	query, ok := sc.logQuery(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
 
-	// TODO: 实现获取系统日志逻辑
-	logs := []gin.H{
-		{
-			"timestamp": "2024-01-01 12:00:00",
-			"level":     "INFO",
-			"message":   "System started successfully",
-			"source":    "main.go:25",
-		},
-		// 更多日志...
+	page, err := sc.logQueryService.Query(c.Request.Context(), query)
+	if err != nil {
+		appG.Fail(err)
+		return
 	}
 
 	appG.Success(gin.H{
-		"message": "系统日志获取成功",
-		"logs":    logs,
-		"filters": gin.H{
-			"level": level,
-			"limit": limit,
-		},
+		"source":      page.Source,
+		"logs":        page.Entries,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
 	})
 }
 
+// logLevels 查询参数到 logrus 级别名的映射
+var logLevels = map[string]string{
+	"debug":   "debug",
+	"info":    "info",
+	"warn":    "warning",
+	"warning": "warning",
+	"error":   "error",
+	"fatal":   "fatal",
+	"panic":   "panic",
+}
+
+// logQuery 解析日志查询参数
+func (sc *SystemController) logQuery(c *gin.Context) (container.LogQuery, bool) {
+	q := container.LogQuery{
+		Path:    c.Query("path"),
+		Keyword: c.Query("q"),
+		Cursor:  c.Query("cursor"),
+	}
+
+	if level := strings.ToLower(c.DefaultQuery("level", "all")); level != "all" {
+		lv, ok := logLevels[level]
+		if !ok {
+			return q, false
+		}
+		q.Level = lv
+	}
+
+	var err error
+	if q.Start, err = parseLogTime(c.Query("start")); err != nil {
+		return q, false
+	}
+	if q.End, err = parseLogTime(c.Query("end")); err != nil {
+		return q, false
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.End.Before(q.Start) {
+		return q, false
+	}
+
+	if uid := c.Query("user_id"); uid != "" {
+		if q.UserID, err = strconv.Atoi(uid); err != nil || q.UserID <= 0 {
+			return q, false
+		}
+	}
+
+	q.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || q.Limit <= 0 || q.Limit > 200 {
+		return q, false
+	}
+
+	// 租户范围与清理缓存一致：超级管理员可选 tenant_id，普通管理员固定为本租户
+	scope, ok := sc.cacheScope(c)
+	if !ok {
+		return q, false
+	}
+	q.TenantID = scope.TenantID
+	return q, true
+}
+
+// parseLogTime 解析时间参数，支持 RFC3339 与 "2006-01-02 15:04:05"
+func parseLogTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", v, time.Local)
+}
+
 // ClearCache 清理缓存
+// type: menus, permissions, tenant, user, all；user 为平台级分类，仅超级管理员清理全部租户时可用
+// 超级管理员可通过 tenant_id 指定租户，不指定时清理全部租户；普通管理员只能清理当前租户
 func (sc *SystemController) ClearCache(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	cacheType := c.DefaultQuery("type", "all") // user, system, all
+	cacheType := c.DefaultQuery("type", service.CacheCategoryAll)
 
-	sc.logger.Infof("Admin clearing cache: type=%s", cacheType)
+	scope, ok := sc.cacheScope(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	sc.logger.WithContext(c.Request.Context()).Infof("Admin clearing cache: type=%s, tenant_id=%d, all_tenants=%v", cacheType, scope.TenantID, scope.AllTenants)
+
+	removed, err := sc.cacheService.Clear(c.Request.Context(), cacheType, scope)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
 
-	// TODO: 实现清理缓存逻辑
-	// 根据cacheType清理不同类型的缓存
+	var total int64
+	for _, n := range removed {
+		total += n
+	}
 
 	appG.Success(gin.H{
-		"message": "缓存清理成功",
-		"type":    cacheType,
+		"message":     "缓存清理成功",
+		"type":        cacheType,
+		"tenant_id":   scope.TenantID,
+		"all_tenants": scope.AllTenants,
+		"removed":     removed,
+		"total":       total,
 	})
 }
 
+// cacheScope 解析缓存清理范围
+func (sc *SystemController) cacheScope(c *gin.Context) (container.CacheScope, bool) {
+	if isSuper, _ := c.Get("isSuper"); isSuper == true {
+		tenantIDStr := c.Query("tenant_id")
+		if tenantIDStr == "" {
+			return container.CacheScope{AllTenants: true}, true
+		}
+		tenantID, err := strconv.Atoi(tenantIDStr)
+		if err != nil || tenantID <= 0 {
+			return container.CacheScope{}, false
+		}
+		return container.CacheScope{TenantID: uint(tenantID)}, true
+	}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		return container.CacheScope{}, false
+	}
+	return container.CacheScope{TenantID: uint(tenantVal.(int))}, true
+}
+
 // RestartService 重启服务
 func (sc *SystemController) RestartService(c *gin.Context) {
 	appG := app.Gin{C: c}
 
 	service := c.Param("service") // database, redis, logger, etc.
 
-	sc.logger.Infof("Admin requesting service restart: service=%s", service)
+	sc.logger.WithContext(c.Request.Context()).Infof("Admin requesting service restart: service=%s", service)
 
 	// TODO: 实现重启服务逻辑
 	// 注意：这个功能需要谨慎实现，可能需要特殊权限
@@ -137,40 +289,118 @@ func (sc *SystemController) RestartService(c *gin.Context) {
 }
 
 // GetHealthStatus 获取健康状态详情
+// 与 /health 共用检查注册表，但忽略缓存实时执行；附带进程内存信息
 func (sc *SystemController) GetHealthStatus(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	sc.logger.Info("Admin requesting detailed health status")
+	report := sc.healthRegistry.Refresh(c.Request.Context())
 
-	// TODO: 实现获取详细健康状态逻辑
-	healthStatus := gin.H{
-		"database": gin.H{
-			"status":        "healthy",
-			"connections":   10,
-			"max_conn":      100,
-			"response_time": "2ms",
-		},
-		"redis": gin.H{
-			"status":        "healthy",
-			"memory_usage":  "50MB",
-			"response_time": "1ms",
-		},
-		"disk": gin.H{
-			"status":    "healthy",
-			"total":     "100GB",
-			"used":      "30GB",
-			"available": "70GB",
-		},
+	appG.Success(gin.H{
+		"status":     report.Status,
+		"checks":     report.Checks,
+		"checked_at": report.CheckedAt,
+		"runtime":    runtimeInfo(),
+	})
+}
+
+// currentEnvironment 当前运行环境（与配置文件选择逻辑一致）
+func currentEnvironment() string {
+	if env := os.Getenv("APP_ENV"); env != "" {
+		return env
+	}
+	return "dev"
+}
+
+// formatUptime 将运行时长格式化为 "1d 2h 3m"
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+}
+
+// runtimeInfo Go 运行时信息
+func runtimeInfo() gin.H {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return gin.H{
+		"goroutines": runtime.NumGoroutine(),
+		"num_cpu":    runtime.NumCPU(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
 		"memory": gin.H{
-			"status":    "healthy",
-			"total":     "8GB",
-			"used":      "2GB",
-			"available": "6GB",
+			"alloc_bytes":      mem.Alloc,
+			"sys_bytes":        mem.Sys,
+			"heap_inuse_bytes": mem.HeapInuse,
+			"heap_objects":     mem.HeapObjects,
+			"num_gc":           mem.NumGC,
 		},
 	}
+}
 
-	appG.Success(gin.H{
-		"message": "健康状态获取成功",
-		"health":  healthStatus,
-	})
+// databaseStats 数据库连接池统计
+func databaseStats() (gin.H, bool) {
+	db := models.GetDb()
+	if db == nil {
+		return nil, false
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, false
+	}
+	st := sqlDB.Stats()
+	return gin.H{
+		"max_open_connections": st.MaxOpenConnections,
+		"open_connections":     st.OpenConnections,
+		"in_use":               st.InUse,
+		"idle":                 st.Idle,
+		"wait_count":           st.WaitCount,
+		"wait_duration_ms":     st.WaitDuration.Milliseconds(),
+		"max_idle_closed":      st.MaxIdleClosed,
+		"max_lifetime_closed":  st.MaxLifetimeClosed,
+	}, true
+}
+
+// databaseInfo 数据库连接状态与连接池统计
+func databaseInfo(ctx context.Context) gin.H {
+	dbStats, ok := databaseStats()
+	if !ok {
+		return gin.H{"status": "disconnected"}
+	}
+	sqlDB, _ := models.GetDb().DB()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return gin.H{"status": "disconnected", "error": err.Error(), "pool": dbStats}
+	}
+	return gin.H{"status": "connected", "pool": dbStats}
+}
+
+// redisInfo Redis 连接状态与关键 INFO 指标
+func redisInfo(ctx context.Context) gin.H {
+	info, err := gredis.Info(ctx)
+	if err != nil {
+		return gin.H{"status": "disconnected"}
+	}
+	return gin.H{
+		"status":            "connected",
+		"version":           info["redis_version"],
+		"uptime_seconds":    info["uptime_in_seconds"],
+		"connected_clients": info["connected_clients"],
+		"used_memory":       info["used_memory_human"],
+		"used_memory_peak":  info["used_memory_peak_human"],
+		"keyspace_hits":     info["keyspace_hits"],
+		"keyspace_misses":   info["keyspace_misses"],
+	}
+}
+
+// redisHitRate 基于 Redis keyspace 命中统计计算缓存命中率
+func redisHitRate(ctx context.Context) float64 {
+	info, err := gredis.Info(ctx, "stats")
+	if err != nil {
+		return 0
+	}
+	hits, _ := strconv.ParseFloat(info["keyspace_hits"], 64)
+	misses, _ := strconv.ParseFloat(info["keyspace_misses"], 64)
+	if hits+misses == 0 {
+		return 0
+	}
+	return hits / (hits + misses)
 }
@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"strconv"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+	"justus/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantController 租户资料控制器（仅超级管理员）
+type TenantController struct {
+	tenantRepo container.TenantRepository
+	logger     container.Logger
+}
+
+// NewTenantController 创建租户控制器实例
+func NewTenantController(tenantRepo container.TenantRepository, logger container.Logger) *TenantController {
+	return &TenantController{tenantRepo: tenantRepo, logger: logger}
+}
+
+// TenantRequest 租户整体更新请求；Code 为空时保持原编码
+type TenantRequest struct {
+	Code        string `json:"code" binding:"omitempty,tenantcode"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Status      *int   `json:"status" binding:"required,oneof=0 1"`
+	Plan        string `json:"plan" binding:"max=50"`
+	OwnerUserID uint   `json:"owner_user_id"`
+}
+
+// PatchTenantRequest 租户部分更新请求，未提供的字段保持不变
+type PatchTenantRequest struct {
+	Code        *string `json:"code" binding:"omitempty,tenantcode"`
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Status      *int    `json:"status" binding:"omitempty,oneof=0 1"`
+	Plan        *string `json:"plan" binding:"omitempty,max=50"`
+	OwnerUserID *uint   `json:"owner_user_id"`
+}
+
+// GetTenant 获取租户详情，ETag 为当前版本
+func (tc *TenantController) GetTenant(c *gin.Context) {
+	appG := app.Gin{C: c}
+	if isSuper, _ := c.Get("isSuper"); isSuper != true {
+		appG.Error(e.ERROR_PERMISSION_DENIED)
+		return
+	}
+	tid, err := strconv.Atoi(c.Param("id"))
+	if err != nil || tid <= 0 {
+		appG.InvalidParams()
+		return
+	}
+	tenant, err := tc.tenantRepo.GetByID(c.Request.Context(), uint(tid))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	app.SetETag(c, tenant.Version)
+	appG.Success(gin.H{"tenant": tenant})
+}
+
+// UpdateTenant 整体更新租户，If-Match 携带版本时做乐观锁校验
+func (tc *TenantController) UpdateTenant(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var req TenantRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+	patch := models.TenantPatch{Name: &req.Name, Status: req.Status, Plan: &req.Plan, OwnerUserID: &req.OwnerUserID}
+	if req.Code != "" {
+		patch.Code = &req.Code
+	}
+	tc.saveTenant(c, patch)
+}
+
+// PatchTenant 部分更新租户，只修改请求中提供的字段
+func (tc *TenantController) PatchTenant(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var req PatchTenantRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+	tc.saveTenant(c, models.TenantPatch{Code: req.Code, Name: req.Name, Status: req.Status, Plan: req.Plan, OwnerUserID: req.OwnerUserID})
+}
+
+// saveTenant 按 If-Match 版本更新租户并返回新的 ETag
+func (tc *TenantController) saveTenant(c *gin.Context, patch models.TenantPatch) {
+	appG := app.Gin{C: c}
+	if isSuper, _ := c.Get("isSuper"); isSuper != true {
+		appG.Error(e.ERROR_PERMISSION_DENIED)
+		return
+	}
+	tid, err := strconv.Atoi(c.Param("id"))
+	if err != nil || tid <= 0 {
+		appG.InvalidParams()
+		return
+	}
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	tc.logger.WithContext(c.Request.Context()).Infof("Super admin updating tenant: id=%d, version=%d", tid, version)
+
+	tenant, err := tc.tenantRepo.Update(c.Request.Context(), uint(tid), version, patch)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	app.SetETag(c, tenant.Version)
+	appG.Success(gin.H{"message": "租户更新成功", "tenant": tenant})
+}
+
+// DeleteTenant 删除租户（进入回收站），租户内管理员随即失去访问权限
+func (tc *TenantController) DeleteTenant(c *gin.Context) {
+	appG := app.Gin{C: c}
+	if isSuper, _ := c.Get("isSuper"); isSuper != true {
+		appG.Error(e.ERROR_PERMISSION_DENIED)
+		return
+	}
+	tid, err := strconv.Atoi(c.Param("id"))
+	if err != nil || tid <= 0 {
+		appG.InvalidParams()
+		return
+	}
+
+	tc.logger.WithContext(c.Request.Context()).Infof("Super admin deleting tenant: id=%d", tid)
+
+	if err := tc.tenantRepo.Delete(c.Request.Context(), uint(tid)); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": tid})
+}
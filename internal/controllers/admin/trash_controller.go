@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"strconv"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+	"justus/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashController 回收站控制器：查看、恢复与永久删除软删除的记录
+type TrashController struct {
+	trashService container.TrashService
+	logger       container.Logger
+}
+
+// NewTrashController 创建回收站控制器实例
+func NewTrashController(trashService container.TrashService, logger container.Logger) *TrashController {
+	return &TrashController{trashService: trashService, logger: logger}
+}
+
+// GetTrash 回收站列表，resource 取值 users、admin-users、roles、tenants
+func (tc *TrashController) GetTrash(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	resource := c.Param("resource")
+	schema, ok := models.TrashSchemas[resource]
+	if !ok {
+		appG.Fail(e.NotFound(e.ERROR_TRASH_NOT_FOUND))
+		return
+	}
+	spec, err := app.ParseQuery(c, schema)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+	tenantID := uint(tenantVal.(int))
+
+	result, err := tc.trashService.List(c.Request.Context(), resource, tenantID, spec)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	appG.Success(gin.H{
+		"items":      spec.Project(result.Items),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
+	})
+}
+
+// RestoreTrash 从回收站恢复
+func (tc *TrashController) RestoreTrash(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	resource, tenantID, id, ok := trashTarget(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	tc.logger.WithContext(c.Request.Context()).Infof("Admin restoring %s: tenant_id=%d, id=%d", resource, tenantID, id)
+
+	if err := tc.trashService.Restore(c.Request.Context(), resource, tenantID, id); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": id})
+}
+
+// PurgeTrash 从回收站永久删除
+func (tc *TrashController) PurgeTrash(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	resource, tenantID, id, ok := trashTarget(c)
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	tc.logger.WithContext(c.Request.Context()).Infof("Admin purging %s: tenant_id=%d, id=%d", resource, tenantID, id)
+
+	if err := tc.trashService.Purge(c.Request.Context(), resource, tenantID, id); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"id": id})
+}
+
+// trashTarget 读取资源名、当前租户与记录 id
+func trashTarget(c *gin.Context) (resource string, tenantID, id uint, ok bool) {
+	tenantVal, exists := c.Get("tenantId")
+	if !exists {
+		return "", 0, 0, false
+	}
+	n, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || n == 0 {
+		return "", 0, 0, false
+	}
+	return c.Param("resource"), uint(tenantVal.(int)), uint(n), true
+}
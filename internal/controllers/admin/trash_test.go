@@ -0,0 +1,155 @@
+package admin_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+)
+
+func TestTrashLifecycle(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	rolePath := fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleAEditorID)
+
+	if resp := kit.Call(http.MethodDelete, rolePath, nil, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("delete role: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get(rolePath, tokenA); resp.Status != http.StatusNotFound {
+		t.Fatalf("deleted role still visible: status=%d", resp.Status)
+	}
+	var n int64
+	kit.DB.Unscoped().Model(&models.Role{}).Where("id = ? AND deleted_at IS NOT NULL", testkit.RoleAEditorID).Count(&n)
+	if n != 1 {
+		t.Fatal("role was not soft deleted")
+	}
+
+	var trash struct {
+		Items []struct {
+			ID        uint   `json:"id"`
+			DeletedAt string `json:"deleted_at"`
+		} `json:"items"`
+	}
+	resp := kit.Get("/admin/v1/trash/roles", tokenA)
+	if err := resp.Decode(&trash); err != nil || len(trash.Items) != 1 || trash.Items[0].DeletedAt == "" {
+		t.Fatalf("trash list: %+v err=%v", trash, err)
+	}
+	// 其他租户看不到本租户的回收站
+	if resp := kit.Call(http.MethodPost, fmt.Sprintf("/admin/v1/trash/roles/%d/restore", testkit.RoleAEditorID), nil, kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)); resp.Code != e.ERROR_TRASH_NOT_FOUND {
+		t.Fatalf("cross-tenant restore: code=%d", resp.Code)
+	}
+	if resp := kit.Get("/admin/v1/trash/admin-users", tokenA); resp.Code != e.ERROR_INSUFFICIENT_PERMISSION {
+		t.Fatalf("admin-users trash for tenant admin: code=%d", resp.Code)
+	}
+
+	if resp := kit.Call(http.MethodPost, fmt.Sprintf("/admin/v1/trash/roles/%d/restore", testkit.RoleAEditorID), nil, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("restore: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get(rolePath, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("restored role: code=%d", resp.Code)
+	}
+
+	kit.Call(http.MethodDelete, rolePath, nil, tokenA)
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/trash/roles/%d", testkit.RoleAEditorID), nil, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("purge: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	kit.DB.Unscoped().Model(&models.Role{}).Where("id = ?", testkit.RoleAEditorID).Count(&n)
+	if n != 0 {
+		t.Fatal("purged role still in database")
+	}
+	kit.DB.Model(&models.RolePermission{}).Where("role_id = ?", testkit.RoleAEditorID).Count(&n)
+	if n != 0 {
+		t.Fatal("purged role left permission bindings")
+	}
+}
+
+func TestTrashPurgeExpired(t *testing.T) {
+	kit := newRBACKit(t)
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	kit.DB.Unscoped().Model(&models.AdminUser{}).Where("id = ?", testkit.PlainAdminID).Update("deleted_at", old)
+	kit.DB.Unscoped().Model(&models.Role{}).Where("id = ?", testkit.RoleBEditorID).Update("deleted_at", time.Now())
+
+	purged, err := container.GlobalContainer.TrashService.PurgeExpired(context.Background(), 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged[models.TrashAdminUsers] != 1 || purged[models.TrashRoles] != 0 {
+		t.Fatalf("purged=%v", purged)
+	}
+}
+
+// TestTrashRestoreUsable 移出最后一个租户的管理员与被删除的租户恢复后立即可用；
+// 所属租户已被永久删除的管理员拒绝恢复
+func TestTrashRestoreUsable(t *testing.T) {
+	kit := newRBACKit(t)
+	kit.DB.Create(&models.AdminUserRole{AdminUserID: testkit.PlainAdminID, RoleID: testkit.AdminRoleID, TenantID: testkit.TenantA})
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	plainToken := kit.TenantAdminToken(testkit.PlainAdminID, testkit.TenantA)
+	superA := kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantA)
+
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/admin-users/%d", testkit.PlainAdminID), nil, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("remove admin: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", plainToken); resp.Status != http.StatusForbidden {
+		t.Fatalf("trashed admin: status=%d", resp.Status)
+	}
+	if resp := kit.Call(http.MethodPost, fmt.Sprintf("/admin/v1/trash/admin-users/%d/restore", testkit.PlainAdminID), nil, superA); resp.Code != e.SUCCESS {
+		t.Fatalf("restore admin: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", plainToken); resp.Code != e.SUCCESS {
+		t.Fatalf("restored admin: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+
+	tenantPath := fmt.Sprintf("/admin/v1/tenants/%d", testkit.TenantB)
+	tokenB := kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)
+	if resp := kit.Call(http.MethodDelete, tenantPath, nil, tokenA); resp.Status != http.StatusForbidden {
+		t.Fatalf("tenant delete by tenant admin: status=%d", resp.Status)
+	}
+	if resp := kit.Call(http.MethodDelete, tenantPath, nil, superA); resp.Code != e.SUCCESS {
+		t.Fatalf("delete tenant: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", tokenB); resp.Status != http.StatusForbidden {
+		t.Fatalf("trashed tenant: status=%d", resp.Status)
+	}
+	if resp := kit.Get("/admin/v1/trash/tenants", tokenA); resp.Code != e.ERROR_INSUFFICIENT_PERMISSION {
+		t.Fatalf("tenant trash for tenant admin: code=%d", resp.Code)
+	}
+	var trash struct {
+		Items []struct {
+			ID uint `json:"id"`
+		} `json:"items"`
+	}
+	if err := kit.Get("/admin/v1/trash/tenants", superA).Decode(&trash); err != nil || len(trash.Items) != 1 || trash.Items[0].ID != testkit.TenantB {
+		t.Fatalf("tenant trash: %+v err=%v", trash, err)
+	}
+	if resp := kit.Call(http.MethodPost, fmt.Sprintf("/admin/v1/trash/tenants/%d/restore", testkit.TenantB), nil, superA); resp.Code != e.SUCCESS {
+		t.Fatalf("restore tenant: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	if resp := kit.Get("/admin/v1/admin-users", tokenB); resp.Code != e.SUCCESS {
+		t.Fatalf("restored tenant: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+
+	// 管理员 B 只属于租户 B，租户被永久删除后账号无法恢复
+	superB := kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantB)
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/admin-users/%d", testkit.AdminBID), nil, superB); resp.Code != e.SUCCESS {
+		t.Fatalf("remove admin b: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	kit.Call(http.MethodDelete, tenantPath, nil, superA)
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/trash/tenants/%d", testkit.TenantB), nil, superA); resp.Code != e.SUCCESS {
+		t.Fatalf("purge tenant: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var n int64
+	kit.DB.Unscoped().Model(&models.Role{}).Where("tenant_id = ?", testkit.TenantB).Count(&n)
+	if n != 0 {
+		t.Fatal("purged tenant left roles")
+	}
+	if resp := kit.Call(http.MethodPost, fmt.Sprintf("/admin/v1/trash/admin-users/%d/restore", testkit.AdminBID), nil, superA); resp.Code != e.ERROR_TRASH_RESTORE_ORPHAN {
+		t.Fatalf("orphan restore: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+}
@@ -0,0 +1,176 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/app"
+	"justus/pkg/e"
+	"justus/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadController 文件上传控制器
+type UploadController struct {
+	uploadService container.UploadService
+	logger        container.Logger
+}
+
+// NewUploadController 创建文件上传控制器实例
+func NewUploadController(uploadService container.UploadService, logger container.Logger) *UploadController {
+	return &UploadController{uploadService: uploadService, logger: logger}
+}
+
+// UploadView 上传记录及其访问地址
+type UploadView struct {
+	models.Upload
+	URL string `json:"url"`
+}
+
+func (uc *UploadController) view(u *models.Upload) UploadView {
+	return UploadView{Upload: *u, URL: uc.uploadService.URL(u)}
+}
+
+// Upload 上传文件：multipart 字段 file，表单字段 category 为 avatar、image（默认）或 file
+// 新文件返回 201；本租户已有相同内容时返回 200 与已有记录
+func (uc *UploadController) Upload(c *gin.Context) {
+	appG := app.Gin{C: c}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	// 整个请求体按单文件上限加 1MB 表单开销截断，文件内容本身由 upload.Receive 流式限长
+	maxSize := int64(setting.AppSetting.ImageMaxSize)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			appG.Error(e.ERROR_UPLOAD_TOO_LARGE)
+			return
+		}
+		appG.InvalidParams()
+		return
+	}
+	if file.Size > maxSize {
+		appG.Error(e.ERROR_UPLOAD_TOO_LARGE)
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		appG.Fail(e.Wrap(e.ERROR_FILE_READ_FAIL, err))
+		return
+	}
+	defer src.Close()
+
+	u, existed, err := uc.uploadService.Upload(c.Request.Context(), uint(tenantVal.(int)), container.UploadInput{
+		Category: c.PostForm("category"),
+		Name:     file.Filename,
+		Body:     src,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	status := http.StatusCreated
+	if existed {
+		status = http.StatusOK
+	}
+	appG.Response(status, e.SUCCESS, gin.H{"upload": uc.view(u), "existed": existed})
+}
+
+// GetUploads 当前租户的上传文件列表
+func (uc *UploadController) GetUploads(c *gin.Context) {
+	appG := app.Gin{C: c}
+	spec, err := app.ParseQuery(c, models.UploadListSchema)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	result, err := uc.uploadService.List(c.Request.Context(), uint(tenantVal.(int)), spec)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	uploads := make([]UploadView, 0, len(result.Items))
+	for i := range result.Items {
+		uploads = append(uploads, uc.view(&result.Items[i]))
+	}
+	appG.Success(gin.H{
+		"uploads":    spec.Project(uploads),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
+	})
+}
+
+// GetUpload 上传文件详情
+func (uc *UploadController) GetUpload(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		appG.InvalidParams()
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	u, err := uc.uploadService.Get(c.Request.Context(), uint(tenantVal.(int)), uint(id))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"upload": uc.view(u)})
+}
+
+// DeleteUpload 删除上传文件
+func (uc *UploadController) DeleteUpload(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		appG.InvalidParams()
+		return
+	}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	if err := uc.uploadService.Delete(c.Request.Context(), uint(tenantVal.(int)), uint(id)); err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"message": "文件删除成功", "upload_id": id})
+}
+
+// GetUploadUsage 当前租户的存储用量与配额
+func (uc *UploadController) GetUploadUsage(c *gin.Context) {
+	appG := app.Gin{C: c}
+	tenantVal, ok := c.Get("tenantId")
+	if !ok {
+		appG.InvalidParams()
+		return
+	}
+
+	usage, err := uc.uploadService.Usage(c.Request.Context(), uint(tenantVal.(int)))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	appG.Success(gin.H{"usage": usage})
+}
@@ -0,0 +1,337 @@
+package admin_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+	"justus/pkg/setting"
+	"justus/pkg/signurl"
+)
+
+type uploadResp struct {
+	Upload struct {
+		ID          uint   `json:"id"`
+		Category    string `json:"category"`
+		Name        string `json:"name"`
+		Key         string `json:"key"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+		MD5         string `json:"md5"`
+		URL         string `json:"url"`
+	} `json:"upload"`
+	Existed bool `json:"existed"`
+}
+
+// pngBytes 生成 w×h 的 PNG 图片，c 不同则内容不同
+func pngBytes(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// postUpload 以 multipart 上传 content
+func postUpload(t *testing.T, kit *testkit.Kit, token, name, category string, content []byte) *testkit.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if category != "" {
+		_ = mw.WriteField("category", category)
+	}
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fw.Write(content)
+	_ = mw.Close()
+	kit.Header = http.Header{"Content-Type": {mw.FormDataContentType()}}
+	defer func() { kit.Header = nil }()
+	return kit.Call(http.MethodPost, "/admin/v1/uploads", buf.Bytes(), token)
+}
+
+func TestUploads(t *testing.T) {
+	app, up := *setting.AppSetting, *setting.UploadSetting
+	t.Cleanup(func() { *setting.AppSetting, *setting.UploadSetting = app, up })
+	setting.AppSetting.ImageSavePath = "uploads/"
+	setting.AppSetting.ImageMaxSize = 64 << 10
+	setting.AppSetting.ImageAllowExts = []string{".jpg", ".png", ".pdf"}
+	setting.UploadSetting.TenantQuota = 0
+
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	tokenB := kit.TenantAdminToken(testkit.AdminBID, testkit.TenantB)
+	red := pngBytes(t, 8, 8, color.RGBA{R: 255, A: 255})
+
+	// 新文件 201，存储键由内容 MD5 决定，与客户端文件名无关
+	resp := postUpload(t, kit, tokenA, "../../avatar.JPG", "avatar", red)
+	var first uploadResp
+	if err := resp.Decode(&first); err != nil || resp.Status != http.StatusCreated {
+		t.Fatalf("upload: status=%d code=%d msg=%s", resp.Status, resp.Code, resp.Msg)
+	}
+	u := first.Upload
+	if u.ContentType != "image/png" || u.Name != "avatar.JPG" || u.Size != int64(len(red)) ||
+		u.Key != "images/"+u.MD5[:2]+"/"+u.MD5+".png" || u.URL != "http://testkit/uploads/"+u.Key {
+		t.Fatalf("upload record: %+v", u)
+	}
+	if w := kit.Do(http.MethodGet, "/uploads/"+u.Key, nil, ""); w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), red) {
+		t.Fatalf("serve upload: status=%d", w.Code)
+	}
+
+	// 相同内容返回已有记录
+	resp = postUpload(t, kit, tokenA, "again.png", "image", red)
+	var again uploadResp
+	if err := resp.Decode(&again); err != nil || resp.Status != http.StatusOK || !again.Existed || again.Upload.ID != u.ID {
+		t.Fatalf("dedupe: status=%d %+v", resp.Status, again)
+	}
+
+	// 类型按内容判断：改扩展名的文本与头像分类下的 PDF 都被拒绝
+	if resp := postUpload(t, kit, tokenA, "fake.png", "", []byte("<svg onload=alert(1)>")); resp.Status != http.StatusUnsupportedMediaType || resp.Code != e.ERROR_UPLOAD_TYPE_NOT_ALLOWED {
+		t.Fatalf("fake png: status=%d code=%d", resp.Status, resp.Code)
+	}
+	pdf := []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n")
+	if resp := postUpload(t, kit, tokenA, "doc.pdf", "avatar", pdf); resp.Code != e.ERROR_UPLOAD_TYPE_NOT_ALLOWED {
+		t.Fatalf("pdf avatar: code=%d", resp.Code)
+	}
+	if resp := postUpload(t, kit, tokenA, "doc.pdf", "file", pdf); resp.Status != http.StatusCreated {
+		t.Fatalf("pdf file: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if resp := postUpload(t, kit, tokenA, "a.png", "video", red); resp.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("bad category: status=%d", resp.Status)
+	}
+
+	// 超过单文件上限
+	big := append(append([]byte{}, red...), bytes.Repeat([]byte{0}, 64<<10)...)
+	if resp := postUpload(t, kit, tokenA, "big.png", "", big); resp.Status != http.StatusRequestEntityTooLarge || resp.Code != e.ERROR_UPLOAD_TOO_LARGE {
+		t.Fatalf("too large: status=%d code=%d", resp.Status, resp.Code)
+	}
+
+	// 租户配额：B 可引用 A 已存储的对象，但仍计入 B 的用量
+	setting.UploadSetting.TenantQuota = int64(len(red)) + 10
+	if resp := postUpload(t, kit, tokenB, "red.png", "", red); resp.Status != http.StatusCreated {
+		t.Fatalf("tenant B upload: status=%d code=%d", resp.Status, resp.Code)
+	}
+	blue := pngBytes(t, 8, 8, color.RGBA{B: 255, A: 255})
+	if resp := postUpload(t, kit, tokenB, "blue.png", "", blue); resp.Status != http.StatusForbidden || resp.Code != e.ERROR_UPLOAD_QUOTA_EXCEEDED {
+		t.Fatalf("quota: status=%d code=%d", resp.Status, resp.Code)
+	}
+	var usage struct {
+		Usage struct {
+			Used  int64 `json:"used"`
+			Quota int64 `json:"quota"`
+			Files int64 `json:"files"`
+		} `json:"usage"`
+	}
+	if err := kit.Get("/admin/v1/uploads/usage", tokenB).Decode(&usage); err != nil || usage.Usage.Used != int64(len(red)) || usage.Usage.Files != 1 || usage.Usage.Quota != setting.UploadSetting.TenantQuota {
+		t.Fatalf("usage: %+v, %v", usage, err)
+	}
+
+	// 列表与租户隔离
+	var list struct {
+		Uploads []map[string]interface{} `json:"uploads"`
+	}
+	if err := kit.Get("/admin/v1/uploads?category=image&fields=id,url", tokenA).Decode(&list); err != nil || len(list.Uploads) != 0 {
+		t.Fatalf("list by category: %+v, %v", list, err)
+	}
+	if err := kit.Get("/admin/v1/uploads?fields=id,url", tokenA).Decode(&list); err != nil || len(list.Uploads) != 2 || list.Uploads[1]["url"] != u.URL {
+		t.Fatalf("list: %+v, %v", list, err)
+	}
+	if resp := kit.Get(fmt.Sprintf("/admin/v1/uploads/%d", u.ID), tokenB); resp.Code != e.ERROR_UPLOAD_NOT_FOUND {
+		t.Fatalf("cross-tenant get: code=%d", resp.Code)
+	}
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/uploads/%d", u.ID), nil, tokenB); resp.Code != e.ERROR_UPLOAD_NOT_FOUND {
+		t.Fatalf("cross-tenant delete: code=%d", resp.Code)
+	}
+
+	// 仍被 B 引用的对象在 A 删除后保留，最后一条记录删除后对象才删除
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/uploads/%d", u.ID), nil, tokenA); resp.Status != http.StatusOK {
+		t.Fatalf("delete: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if ok, _ := kit.Storage.Exists(t.Context(), u.Key); !ok {
+		t.Fatal("shared object removed while still referenced")
+	}
+	var b models.Upload
+	kit.DB.Where("tenant_id = ?", testkit.TenantB).First(&b)
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/uploads/%d", b.ID), nil, tokenB); resp.Status != http.StatusOK {
+		t.Fatalf("delete B: status=%d code=%d", resp.Status, resp.Code)
+	}
+	if ok, _ := kit.Storage.Exists(t.Context(), u.Key); ok {
+		t.Fatal("object kept after last reference deleted")
+	}
+	if !strings.HasPrefix(b.Key, "images/") {
+		t.Fatalf("tenant B key = %q", b.Key)
+	}
+}
+
+func TestUploadImageVariants(t *testing.T) {
+	app, up := *setting.AppSetting, *setting.UploadSetting
+	t.Cleanup(func() { *setting.AppSetting, *setting.UploadSetting = app, up })
+	setting.AppSetting.ImageUrl = "http://testkit/uploads"
+	setting.AppSetting.PrefixUrl = "http://testkit"
+	setting.AppSetting.ImageMaxSize = 1 << 20
+	setting.AppSetting.ImageAllowExts = []string{".png"}
+	setting.UploadSetting.TenantQuota = 0
+	setting.UploadSetting.Image = setting.ImagePipeline{
+		MaxDimension: 16,
+		Quality:      80,
+		Variants:     []setting.ImageVariant{{Name: "thumb", Width: 4, Height: 4, Fit: "cover"}},
+	}
+
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	ctx := t.Context()
+
+	// 上传时限制尺寸并生成变体
+	var red uploadResp
+	resp := postUpload(t, kit, tokenA, "red.png", "avatar", pngBytes(t, 32, 24, color.RGBA{R: 255, A: 255}))
+	if err := resp.Decode(&red); err != nil || resp.Status != http.StatusCreated {
+		t.Fatalf("upload: status=%d code=%d", resp.Status, resp.Code)
+	}
+	rc, err := kit.Storage.Open(ctx, red.Upload.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := image.DecodeConfig(rc)
+	_ = rc.Close()
+	if err != nil || cfg.Width != 16 || cfg.Height != 12 || red.Upload.Size == int64(len(pngBytes(t, 32, 24, color.RGBA{R: 255, A: 255}))) {
+		t.Fatalf("normalized original = %dx%d size=%d, %v", cfg.Width, cfg.Height, red.Upload.Size, err)
+	}
+	thumbKey := strings.TrimSuffix(red.Upload.Key, ".png") + "_thumb.png"
+	if ok, _ := kit.Storage.Exists(ctx, thumbKey); !ok {
+		t.Fatalf("variant %s not generated", thumbKey)
+	}
+
+	// 管理员头像返回各变体地址
+	kit.DB.Model(&models.AdminUser{}).Where("id = ?", testkit.AdminAID).Update("avatar", red.Upload.Key)
+	var detail struct {
+		Avatar  string            `json:"avatar"`
+		Avatars map[string]string `json:"avatars"`
+	}
+	if err := kit.Get(fmt.Sprintf("/admin/v1/admin-users/%d", testkit.AdminAID), tokenA).Decode(&detail); err != nil ||
+		detail.Avatar != "http://testkit/uploads/"+red.Upload.Key || detail.Avatars["thumb"] != "http://testkit/variants/thumb/"+red.Upload.Key {
+		t.Fatalf("admin avatar: %+v, %v", detail, err)
+	}
+	// 已生成的变体直接重定向到存储地址
+	if w := kit.Do(http.MethodGet, strings.TrimPrefix(detail.Avatars["thumb"], "http://testkit"), nil, ""); w.Code != http.StatusFound || w.Header().Get("Location") != "http://testkit/uploads/"+thumbKey {
+		t.Fatalf("eager variant: status=%d location=%s", w.Code, w.Header().Get("Location"))
+	}
+
+	// Lazy 模式：首次访问 /variants 时生成并重定向
+	setting.UploadSetting.Image.Lazy = true
+	var blue uploadResp
+	if err := postUpload(t, kit, tokenA, "blue.png", "image", pngBytes(t, 8, 8, color.RGBA{B: 255, A: 255})).Decode(&blue); err != nil {
+		t.Fatal(err)
+	}
+	blueThumb := strings.TrimSuffix(blue.Upload.Key, ".png") + "_thumb.png"
+	if ok, _ := kit.Storage.Exists(ctx, blueThumb); ok {
+		t.Fatal("lazy variant generated at upload")
+	}
+	w := kit.Do(http.MethodGet, "/variants/thumb/"+blue.Upload.Key, nil, "")
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "http://testkit/uploads/"+blueThumb {
+		t.Fatalf("lazy variant: status=%d location=%s", w.Code, w.Header().Get("Location"))
+	}
+	if ok, _ := kit.Storage.Exists(ctx, blueThumb); !ok {
+		t.Fatal("lazy variant not stored")
+	}
+	for _, path := range []string{"/variants/huge/" + blue.Upload.Key, "/variants/thumb/images/../secret.png", "/variants/thumb/images/00/missing.png"} {
+		if w := kit.Do(http.MethodGet, path, nil, ""); w.Code != http.StatusNotFound {
+			t.Fatalf("%s: status=%d", path, w.Code)
+		}
+	}
+
+	// 删除最后一条引用时变体一并删除
+	if resp := kit.Call(http.MethodDelete, fmt.Sprintf("/admin/v1/uploads/%d", red.Upload.ID), nil, tokenA); resp.Status != http.StatusOK {
+		t.Fatalf("delete: status=%d", resp.Status)
+	}
+	if ok, _ := kit.Storage.Exists(ctx, thumbKey); ok {
+		t.Fatal("variant kept after delete")
+	}
+}
+
+func TestPrivateUploadLinks(t *testing.T) {
+	app, up := *setting.AppSetting, *setting.UploadSetting
+	t.Cleanup(func() { *setting.AppSetting, *setting.UploadSetting = app, up })
+	setting.AppSetting.PrefixUrl = "http://testkit"
+	setting.AppSetting.ImageSavePath = "uploads/"
+	setting.AppSetting.ImageMaxSize = 64 << 10
+	setting.AppSetting.ImageAllowExts = []string{".pdf"}
+	setting.UploadSetting.TenantQuota = 0
+
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	pdf := []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n")
+
+	// 非图片文件的地址是绑定租户的签名链接，静态目录不提供
+	var doc uploadResp
+	if err := postUpload(t, kit, tokenA, "报告.pdf", "file", pdf).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	link := strings.TrimPrefix(doc.Upload.URL, "http://testkit")
+	if !strings.HasPrefix(link, "/files/uploads/files/") || !strings.Contains(link, "tenant=1") {
+		t.Fatalf("private url = %s", doc.Upload.URL)
+	}
+	for _, path := range []string{"/uploads/" + doc.Upload.Key, "/uploads/images/../" + doc.Upload.Key} {
+		if w := kit.Do(http.MethodGet, path, nil, ""); w.Code != http.StatusNotFound {
+			t.Fatalf("static %s: status=%d", path, w.Code)
+		}
+	}
+
+	w := kit.Do(http.MethodGet, link, nil, "")
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), pdf) || w.Header().Get("Content-Type") != "application/pdf" ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "filename*=utf-8''%E6%8A%A5%E5%91%8A.pdf") ||
+		!strings.HasPrefix(w.Header().Get("Cache-Control"), "private, max-age=") {
+		t.Fatalf("download: status=%d headers=%v", w.Code, w.Header())
+	}
+
+	// Range 与条件请求
+	kit.Header = http.Header{"Range": {"bytes=0-3"}}
+	w = kit.Do(http.MethodGet, link, nil, "")
+	kit.Header = http.Header{"If-None-Match": {w.Header().Get("ETag")}}
+	notModified := kit.Do(http.MethodGet, link, nil, "")
+	kit.Header = nil
+	if w.Code != http.StatusPartialContent || w.Body.String() != "%PDF" || w.Header().Get("Content-Range") != fmt.Sprintf("bytes 0-3/%d", len(pdf)) {
+		t.Fatalf("range: status=%d body=%q", w.Code, w.Body.String())
+	}
+	if notModified.Code != http.StatusNotModified {
+		t.Fatalf("if-none-match: status=%d", notModified.Code)
+	}
+
+	// 篡改、过期与跨租户
+	path := "/files/uploads/" + doc.Upload.Key
+	cases := []struct {
+		link   string
+		status int
+		code   int
+	}{
+		{strings.Replace(link, "tenant=1", "tenant=2", 1), http.StatusForbidden, e.ERROR_FILE_LINK_INVALID},
+		{path, http.StatusForbidden, e.ERROR_FILE_LINK_INVALID},
+		{signurl.Sign(path, signurl.Options{TenantID: testkit.TenantA, TTL: time.Nanosecond}), http.StatusGone, e.ERROR_FILE_LINK_EXPIRED},
+		{signurl.Sign(path, signurl.Options{TenantID: testkit.TenantB}), http.StatusNotFound, e.ERROR_FILE_NOT_FOUND},
+		{signurl.Sign(path, signurl.Options{}), http.StatusNotFound, e.ERROR_FILE_NOT_FOUND},
+		{signurl.Sign("/files/uploads/../../conf/app.dev.yaml", signurl.Options{TenantID: testkit.TenantA}), http.StatusNotFound, 0},
+	}
+	for _, c := range cases {
+		w := kit.Do(http.MethodGet, c.link, nil, "")
+		if w.Code != c.status || (c.code != 0 && !strings.Contains(w.Body.String(), fmt.Sprintf(`"code":%d`, c.code))) {
+			t.Fatalf("%s: status=%d body=%s", c.link, w.Code, w.Body.String())
+		}
+	}
+}
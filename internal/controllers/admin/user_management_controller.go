@@ -6,7 +6,6 @@ import (
 	"justus/internal/container"
 	"justus/internal/models"
 	"justus/pkg/app"
-	"justus/pkg/e"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,61 +30,51 @@ func NewUserManagementController(userService container.UserService, adminUserSer
 type UserRequest struct {
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
-	Phone     string `json:"phone" binding:"required"`
-	Lang      string `json:"lang"`
+	Phone     string `json:"phone" binding:"required,phone"`
+	Lang      string `json:"lang" binding:"omitempty,max=10"`
 	Avatar    string `json:"avatar"`
 }
 
+// PatchUserRequest 用户部分更新请求，未提供的字段保持不变
+type PatchUserRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=1"`
+	Phone     *string `json:"phone" binding:"omitempty,phone"`
+	Lang      *string `json:"lang" binding:"omitempty,max=10"`
+	Avatar    *string `json:"avatar"`
+	Status    *int    `json:"status" binding:"omitempty,oneof=0 1 2"`
+}
+
 // GetUsers 获取用户列表 (管理员)
 func (umc *UserManagementController) GetUsers(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		appG.InvalidParams()
-		return
-	}
-
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		appG.InvalidParams()
+	spec, err := app.ParseQuery(c, models.UserListSchema)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
-	// 搜索条件
-	keyword := c.Query("keyword")
-	status := c.Query("status")
-
-	umc.logger.Infof("Admin getting users list: page=%d, limit=%d, keyword=%s, status=%s", page, limit, keyword, status)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin getting users list: page=%d, limit=%d, keyword=%s, cursor=%t", spec.Page, spec.Limit, spec.Keyword, spec.Cursor())
 
 	// 获取用户列表
-	users, total, err := umc.userService.GetUsers(page, limit, keyword, status)
+	result, err := umc.userService.GetUsers(c.Request.Context(), spec)
 	if err != nil {
-		umc.logger.Errorf("Failed to get users list: %v", err)
-		appG.Error(e.ERROR_DATABASE_QUERY)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to get users list: %v", err)
+		appG.Fail(err)
 		return
 	}
 
 	// 格式化用户信息
-	var userList []interface{}
-	for _, user := range users {
+	userList := make([]*models.UserInfo, 0, len(result.Items))
+	for _, user := range result.Items {
 		userList = append(userList, user.Format())
 	}
 
 	appG.Success(gin.H{
-		"users": userList,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-		"filters": gin.H{
-			"keyword": keyword,
-			"status":  status,
-		},
+		"users":      spec.Project(userList),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
 	})
 }
 
@@ -100,15 +89,16 @@ func (umc *UserManagementController) GetUser(c *gin.Context) {
 		return
 	}
 
-	umc.logger.Infof("Admin getting user details: id=%d", id)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin getting user details: id=%d", id)
 
-	user, err := umc.userService.GetUserInfo(id)
+	user, err := umc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		umc.logger.Errorf("Failed to get user details: id=%d, error=%v", id, err)
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to get user details: id=%d, error=%v", id, err)
+		appG.Fail(err)
 		return
 	}
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"user": user.Format(),
 	})
@@ -119,13 +109,13 @@ func (umc *UserManagementController) CreateUser(c *gin.Context) {
 	appG := app.Gin{C: c}
 
 	var req UserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		umc.logger.Errorf("Invalid user creation request: %v", err)
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		umc.logger.WithContext(c.Request.Context()).Errorf("Invalid user creation request: %v", err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("Admin creating user: phone=%s", req.Phone)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin creating user: phone=%s", req.Phone)
 
 	// 创建用户
 	user := &models.User{
@@ -137,14 +127,14 @@ func (umc *UserManagementController) CreateUser(c *gin.Context) {
 		Status:    1, // 默认正常状态
 	}
 
-	err := umc.userService.CreateUser(user)
+	err := umc.userService.CreateUser(c.Request.Context(), user)
 	if err != nil {
-		umc.logger.Errorf("Failed to create user: %v", err)
-		appG.Error(e.ERROR_DATABASE_INSERT)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to create user: %v", err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("User created successfully: id=%d", user.ID)
+	umc.logger.WithContext(c.Request.Context()).Infof("User created successfully: id=%d", user.ID)
 
 	appG.Success(gin.H{
 		"message": "用户创建成功",
@@ -152,7 +142,7 @@ func (umc *UserManagementController) CreateUser(c *gin.Context) {
 	})
 }
 
-// UpdateUser 更新用户 (管理员)
+// UpdateUser 整体更新用户 (管理员)，If-Match 携带版本时做乐观锁校验
 func (umc *UserManagementController) UpdateUser(c *gin.Context) {
 	appG := app.Gin{C: c}
 
@@ -162,21 +152,26 @@ func (umc *UserManagementController) UpdateUser(c *gin.Context) {
 		appG.InvalidParams()
 		return
 	}
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
 
 	var req UserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		umc.logger.Errorf("Invalid user update request: %v", err)
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		umc.logger.WithContext(c.Request.Context()).Errorf("Invalid user update request: %v", err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("Admin updating user: id=%d", id)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin updating user: id=%d", id)
 
 	// 检查用户是否存在
-	user, err := umc.userService.GetUserInfo(id)
+	user, err := umc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		umc.logger.Errorf("User not found for update: id=%d", id)
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		umc.logger.WithContext(c.Request.Context()).Errorf("User not found for update: id=%d", id)
+		appG.Fail(err)
 		return
 	}
 
@@ -186,16 +181,67 @@ func (umc *UserManagementController) UpdateUser(c *gin.Context) {
 	user.Phone = req.Phone
 	user.Lang = req.Lang
 	user.Avatar = req.Avatar
+	if version > 0 {
+		// 以客户端读取时的版本为前置条件，期间被他人修改则返回冲突
+		user.Version = version
+	}
 
-	err = umc.userService.UpdateUser(user)
+	err = umc.userService.UpdateUser(c.Request.Context(), user)
 	if err != nil {
-		umc.logger.Errorf("Failed to update user: id=%d, error=%v", id, err)
-		appG.Error(e.ERROR_DATABASE_UPDATE)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to update user: id=%d, error=%v", id, err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("User updated successfully: id=%d", id)
+	umc.logger.WithContext(c.Request.Context()).Infof("User updated successfully: id=%d", id)
 
+	app.SetETag(c, user.Version)
+	appG.Success(gin.H{
+		"message": "用户更新成功",
+		"user":    user.Format(),
+	})
+}
+
+// PatchUser 部分更新用户 (管理员)，只修改请求中提供的字段
+func (umc *UserManagementController) PatchUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		appG.InvalidParams()
+		return
+	}
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	var req PatchUserRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		umc.logger.WithContext(c.Request.Context()).Errorf("Invalid user patch request: %v", err)
+		appG.Fail(err)
+		return
+	}
+
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin patching user: id=%d, version=%d", id, version)
+
+	user, err := umc.userService.PatchUser(c.Request.Context(), id, version, models.UserPatch{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+		Lang:      req.Lang,
+		Avatar:    req.Avatar,
+		Status:    req.Status,
+	})
+	if err != nil {
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to patch user: id=%d, error=%v", id, err)
+		appG.Fail(err)
+		return
+	}
+
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"message": "用户更新成功",
 		"user":    user.Format(),
@@ -213,24 +259,24 @@ func (umc *UserManagementController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	umc.logger.Infof("Admin deleting user: id=%d", id)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin deleting user: id=%d", id)
 
 	// 检查用户是否存在
-	_, err = umc.userService.GetUserInfo(id)
+	_, err = umc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		umc.logger.Errorf("User not found for deletion: id=%d", id)
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		umc.logger.WithContext(c.Request.Context()).Errorf("User not found for deletion: id=%d", id)
+		appG.Fail(err)
 		return
 	}
 
-	err = umc.userService.DeleteUser(id)
+	err = umc.userService.DeleteUser(c.Request.Context(), id)
 	if err != nil {
-		umc.logger.Errorf("Failed to delete user: id=%d, error=%v", id, err)
-		appG.Error(e.ERROR_DATABASE_DELETE)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to delete user: id=%d, error=%v", id, err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("User deleted successfully: id=%d", id)
+	umc.logger.WithContext(c.Request.Context()).Infof("User deleted successfully: id=%d", id)
 
 	appG.Success(gin.H{
 		"message": "用户删除成功",
@@ -250,35 +296,33 @@ func (umc *UserManagementController) UpdateUserStatus(c *gin.Context) {
 	}
 
 	var req struct {
-		Status int `json:"status" binding:"required"`
+		Status *int `json:"status" binding:"required,oneof=0 1 2"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		umc.logger.Errorf("Invalid status update request: %v", err)
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		umc.logger.WithContext(c.Request.Context()).Errorf("Invalid status update request: %v", err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("Admin updating user status: id=%d, status=%d", id, req.Status)
-
-	// 检查用户是否存在
-	user, err := umc.userService.GetUserInfo(id)
+	version, err := app.IfMatch(c)
 	if err != nil {
-		umc.logger.Errorf("User not found for status update: id=%d", id)
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
-	// 更新用户状态
-	user.Status = req.Status
-	err = umc.userService.UpdateUser(user)
+	umc.logger.WithContext(c.Request.Context()).Infof("Admin updating user status: id=%d, status=%d", id, *req.Status)
+
+	// 只更新状态列，不覆盖并发修改的其他资料
+	user, err := umc.userService.PatchUser(c.Request.Context(), id, version, models.UserPatch{Status: req.Status})
 	if err != nil {
-		umc.logger.Errorf("Failed to update user status: id=%d, error=%v", id, err)
-		appG.Error(e.ERROR_DATABASE_UPDATE)
+		umc.logger.WithContext(c.Request.Context()).Errorf("Failed to update user status: id=%d, error=%v", id, err)
+		appG.Fail(err)
 		return
 	}
 
-	umc.logger.Infof("User status updated successfully: id=%d, status=%d", id, req.Status)
+	umc.logger.WithContext(c.Request.Context()).Infof("User status updated successfully: id=%d, status=%d", id, *req.Status)
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"message": "用户状态更新成功",
 		"user":    user.Format(),
@@ -0,0 +1,73 @@
+package admin_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"justus/internal/models"
+	"justus/internal/testkit"
+	"justus/pkg/e"
+)
+
+func TestOptimisticLocking(t *testing.T) {
+	kit := newRBACKit(t)
+	tokenA := kit.TenantAdminToken(testkit.AdminAID, testkit.TenantA)
+	rolePath := fmt.Sprintf("/admin/v1/roles/%d", testkit.RoleAEditorID)
+
+	etag := kit.Do(http.MethodGet, rolePath, nil, tokenA).Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("role etag = %q", etag)
+	}
+
+	kit.Header = http.Header{"If-Match": {etag}}
+	w := kit.Do(http.MethodPatch, rolePath, map[string]interface{}{"description": "first"}, tokenA)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("patch role: status=%d etag=%q body=%s", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+	// 仍持有旧版本的客户端更新被拒绝
+	resp := kit.Call(http.MethodPut, rolePath, map[string]interface{}{"name": "stale", "status": 1}, tokenA)
+	if resp.Status != http.StatusConflict || resp.Code != e.ERROR_VERSION_CONFLICT {
+		t.Fatalf("stale put: status=%d code=%d", resp.Status, resp.Code)
+	}
+	kit.Header = http.Header{"If-Match": {"v2"}}
+	if resp := kit.Call(http.MethodPatch, rolePath, map[string]interface{}{"status": 0}, tokenA); resp.Status != http.StatusBadRequest {
+		t.Fatalf("malformed If-Match: status=%d", resp.Status)
+	}
+	kit.Header = nil
+
+	var role models.Role
+	kit.DB.First(&role, testkit.RoleAEditorID)
+	if role.Description != "first" || role.DisplayName == "stale" || role.Status != 1 || role.Version != 2 {
+		t.Fatalf("role after updates: %+v", role)
+	}
+
+	// PATCH 只修改提供的字段
+	user := models.User{Username: "patch-me", FirstName: "Ada", LastName: "Lovelace", Phone: "13800000000", Status: 1}
+	kit.DB.Create(&user)
+	userPath := fmt.Sprintf("/admin/v1/users/%d", user.ID)
+	if resp := kit.Call(http.MethodPatch, userPath, map[string]interface{}{"last_name": "Byron"}, tokenA); resp.Code != e.SUCCESS {
+		t.Fatalf("patch user: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	var got models.User
+	kit.DB.First(&got, user.ID)
+	if got.FirstName != "Ada" || got.LastName != "Byron" || got.Phone != "13800000000" || got.Version != 2 {
+		t.Fatalf("user after patch: %+v", got)
+	}
+	kit.Header = http.Header{"If-Match": {`"1"`}}
+	if resp := kit.Call(http.MethodPut, userPath+"/status", map[string]interface{}{"status": 0}, tokenA); resp.Code != e.ERROR_VERSION_CONFLICT {
+		t.Fatalf("stale status update: code=%d", resp.Code)
+	}
+	kit.Header = nil
+
+	// 租户资料仅超级管理员可改
+	tenantPath := fmt.Sprintf("/admin/v1/tenants/%d", testkit.TenantA)
+	if resp := kit.Call(http.MethodPatch, tenantPath, map[string]interface{}{"plan": "pro"}, tokenA); resp.Code != e.ERROR_PERMISSION_DENIED {
+		t.Fatalf("tenant patch by tenant admin: code=%d", resp.Code)
+	}
+	superToken := kit.SuperAdminToken(testkit.SuperAdminID, testkit.TenantA)
+	w = kit.Do(http.MethodPatch, tenantPath, map[string]interface{}{"plan": "pro"}, superToken)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("tenant patch: status=%d etag=%q body=%s", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+}
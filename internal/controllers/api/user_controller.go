@@ -30,11 +30,20 @@ func NewUserController(userService container.UserService, logger container.Logge
 type UserRequest struct {
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
-	Phone     string `json:"phone" binding:"required"`
-	Lang      string `json:"lang"`
+	Phone     string `json:"phone" binding:"required,phone"`
+	Lang      string `json:"lang" binding:"omitempty,max=10"`
 	Avatar    string `json:"avatar"`
 }
 
+// PatchUserRequest 用户部分更新请求，未提供的字段保持不变
+type PatchUserRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=1"`
+	Phone     *string `json:"phone" binding:"omitempty,phone"`
+	Lang      *string `json:"lang" binding:"omitempty,max=10"`
+	Avatar    *string `json:"avatar"`
+}
+
 // GetUser 获取用户信息
 func (uc *UserController) GetUser(c *gin.Context) {
 	appG := app.Gin{C: c}
@@ -46,12 +55,13 @@ func (uc *UserController) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.GetUserInfo(id)
+	user, err := uc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"user": user.Format(),
 	})
@@ -61,49 +71,29 @@ func (uc *UserController) GetUser(c *gin.Context) {
 func (uc *UserController) GetUsers(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		appG.InvalidParams()
-		return
-	}
-
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		appG.InvalidParams()
+	spec, err := app.ParseQuery(c, models.UserListSchema)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
-	// 搜索条件
-	keyword := c.Query("keyword")
-	status := c.Query("status")
-
 	// 获取普通用户列表
-	users, total, err := uc.userService.GetUsers(page, limit, keyword, status)
+	result, err := uc.userService.GetUsers(c.Request.Context(), spec)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_QUERY)
+		appG.Fail(err)
 		return
 	}
 
 	// 格式化用户信息
-	var userList []interface{}
-	for _, user := range users {
+	userList := make([]*models.UserInfo, 0, len(result.Items))
+	for _, user := range result.Items {
 		userList = append(userList, user.Format())
 	}
 
 	appG.Success(gin.H{
-		"users": userList,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-		"filters": gin.H{
-			"keyword": keyword,
-			"status":  status,
-		},
+		"users":      spec.Project(userList),
+		"pagination": result.Pagination,
+		"filters":    spec.Echo(),
 	})
 }
 
@@ -112,8 +102,8 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 	appG := app.Gin{C: c}
 
 	var req UserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
 		return
 	}
 
@@ -127,9 +117,9 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		Status:    1, // 默认正常状态
 	}
 
-	err := uc.userService.CreateUser(user)
+	err := uc.userService.CreateUser(c.Request.Context(), user)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_INSERT)
+		appG.Fail(err)
 		return
 	}
 
@@ -151,15 +141,21 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	}
 
 	var req UserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
 	// 检查用户是否存在
-	user, err := uc.userService.GetUserInfo(id)
+	user, err := uc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
@@ -169,13 +165,18 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	user.Phone = req.Phone
 	user.Lang = req.Lang
 	user.Avatar = req.Avatar
+	if version > 0 {
+		// 以客户端读取时的版本为前置条件，期间被他人修改则返回冲突
+		user.Version = version
+	}
 
-	err = uc.userService.UpdateUser(user)
+	err = uc.userService.UpdateUser(c.Request.Context(), user)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_UPDATE)
+		appG.Fail(err)
 		return
 	}
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"message": "用户更新成功",
 		"user":    user.Format(),
@@ -194,15 +195,15 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 	}
 
 	// 检查用户是否存在
-	_, err = uc.userService.GetUserInfo(id)
+	_, err = uc.userService.GetUserInfo(c.Request.Context(), id)
 	if err != nil {
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
-	err = uc.userService.DeleteUser(id)
+	err = uc.userService.DeleteUser(c.Request.Context(), id)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_DELETE)
+		appG.Fail(err)
 		return
 	}
 
@@ -224,12 +225,13 @@ func (uc *UserController) GetProfile(c *gin.Context) {
 	}
 
 	uid := userId.(int)
-	user, err := uc.userService.GetUserInfo(uid)
+	user, err := uc.userService.GetUserInfo(c.Request.Context(), uid)
 	if err != nil {
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"user": user.Format(),
 	})
@@ -249,15 +251,21 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 	uid := userId.(int)
 
 	var req UserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		appG.InvalidParams()
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
 		return
 	}
 
 	// 检查用户是否存在
-	user, err := uc.userService.GetUserInfo(uid)
+	user, err := uc.userService.GetUserInfo(c.Request.Context(), uid)
 	if err != nil {
-		appG.Error(e.ERROR_USER_NOT_FOUND)
+		appG.Fail(err)
 		return
 	}
 
@@ -267,15 +275,81 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 	user.Phone = req.Phone
 	user.Lang = req.Lang
 	user.Avatar = req.Avatar
+	if version > 0 {
+		// 以客户端读取时的版本为前置条件，期间被他人修改则返回冲突
+		user.Version = version
+	}
 
-	err = uc.userService.UpdateUser(user)
+	err = uc.userService.UpdateUser(c.Request.Context(), user)
 	if err != nil {
-		appG.Error(e.ERROR_DATABASE_UPDATE)
+		appG.Fail(err)
 		return
 	}
 
+	app.SetETag(c, user.Version)
 	appG.Success(gin.H{
 		"message": "个人信息更新成功",
 		"user":    user.Format(),
 	})
 }
+
+// PatchUser 部分更新用户信息，只修改请求中提供的字段
+func (uc *UserController) PatchUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		appG.InvalidParams()
+		return
+	}
+	uc.patchUser(c, id, "用户更新成功")
+}
+
+// PatchProfile 部分更新当前用户个人信息
+func (uc *UserController) PatchProfile(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	// 从JWT中获取用户ID
+	userId, exists := c.Get("userId")
+	if !exists {
+		appG.Unauthorized(e.ERROR_AUTH)
+		return
+	}
+	uc.patchUser(c, userId.(int), "个人信息更新成功")
+}
+
+// patchUser 按 If-Match 版本部分更新用户并返回新的 ETag
+func (uc *UserController) patchUser(c *gin.Context, id int, message string) {
+	appG := app.Gin{C: c}
+
+	version, err := app.IfMatch(c)
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	var req PatchUserRequest
+	if err := app.BindJSON(c, &req); err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	user, err := uc.userService.PatchUser(c.Request.Context(), id, version, models.UserPatch{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+		Lang:      req.Lang,
+		Avatar:    req.Avatar,
+	})
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+
+	app.SetETag(c, user.Version)
+	appG.Success(gin.H{
+		"message": message,
+		"user":    user.Format(),
+	})
+}
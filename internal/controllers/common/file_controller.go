@@ -0,0 +1,54 @@
+package common
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"justus/internal/container"
+	"justus/pkg/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileController 签名文件下载控制器
+type FileController struct {
+	fileService container.FileService
+	logger      container.Logger
+}
+
+// NewFileController 创建签名文件下载控制器实例
+func NewFileController(fileService container.FileService, logger container.Logger) *FileController {
+	return &FileController{fileService: fileService, logger: logger}
+}
+
+// Serve 按签名链接下载文件，无需认证；支持 Range 与 If-Modified-Since/If-None-Match 条件请求
+// 地址由 signurl.URL 生成，形如 /files/exports/{name}?expires=…&tenant=…&signature=…
+func (fc *FileController) Serve(c *gin.Context) {
+	appG := app.Gin{C: c}
+	file, err := fc.fileService.Open(c.Request.Context(), c.Request.URL.Path, c.Request.URL.Query())
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	defer file.Content.Close()
+
+	// 只允许在链接有效期内缓存，且不进入共享缓存
+	maxAge := int(time.Until(file.Expires).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	if file.ContentType != "" {
+		c.Header("Content-Type", file.ContentType)
+	}
+	if file.ETag != "" {
+		c.Header("ETag", file.ETag)
+	}
+	if file.Filename != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, file.Name, file.ModTime, file.Content)
+}
@@ -1,65 +1,66 @@
 package common
 
 import (
+	"net/http"
+	"time"
+
 	"justus/internal/container"
-	"justus/internal/models"
+	"justus/internal/health"
 	"justus/pkg/app"
-	"justus/pkg/gredis"
-	"time"
+	"justus/pkg/e"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HealthController 健康检查控制器
 type HealthController struct {
-	logger container.Logger
-	cache  container.Cache
+	registry *health.Registry
+	logger   container.Logger
 }
 
 // NewHealthController 创建健康检查控制器实例
-func NewHealthController(logger container.Logger, cache container.Cache) *HealthController {
+func NewHealthController(registry *health.Registry, logger container.Logger) *HealthController {
 	return &HealthController{
-		logger: logger,
-		cache:  cache,
+		registry: registry,
+		logger:   logger,
 	}
 }
 
 // Health 健康检查接口
+// healthy/degraded 返回200，关键依赖不可用时返回503；只返回状态摘要，详情见后台 /system/health
 func (hc *HealthController) Health(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 检查各项服务状态
-	status := hc.checkServicesHealth()
+	report := hc.registry.Run(c.Request.Context())
+	hc.logUnhealthy(report)
 
-	if status["overall"] == "healthy" {
-		c.Status(200)
-		appG.Success(gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().Unix(),
-			"services":  status,
-		})
-	} else {
-		c.Status(503)
-		appG.Error(503)
+	if report.Status == health.StatusUnhealthy {
+		appG.Response(http.StatusServiceUnavailable, e.ERROR, report.Summary())
+		return
 	}
+	appG.Success(report.Summary())
 }
 
 // Readiness 就绪检查接口
 func (hc *HealthController) Readiness(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	// 检查应用是否已准备好处理请求
-	ready := hc.checkReadiness()
-
-	if ready {
-		appG.Success(gin.H{
-			"status":    "ready",
+	ready, report := hc.registry.Ready(c.Request.Context())
+	if !ready {
+		hc.logUnhealthy(report)
+		appG.Response(http.StatusServiceUnavailable, e.ERROR, gin.H{
+			"status":    "not_ready",
+			"draining":  hc.registry.Draining(),
 			"timestamp": time.Now().Unix(),
+			"checks":    report.Summary().Checks,
 		})
-	} else {
-		c.Status(503)
-		appG.Error(503)
+		return
 	}
+
+	appG.Success(gin.H{
+		"status":    "ready",
+		"timestamp": time.Now().Unix(),
+	})
 }
 
 // Liveness 存活检查接口
@@ -73,72 +74,14 @@ func (hc *HealthController) Liveness(c *gin.Context) {
 	})
 }
 
-// checkServicesHealth 检查各项服务健康状态
-func (hc *HealthController) checkServicesHealth() map[string]string {
-	status := make(map[string]string)
-	overall := "healthy"
-
-	// 检查数据库连接
-	db := models.GetDb()
-	if db != nil {
-		sqlDB, err := db.DB()
-		if err != nil || sqlDB.Ping() != nil {
-			status["database"] = "unhealthy"
-			overall = "unhealthy"
-			hc.logger.Errorf("Database health check failed: %v", err)
-		} else {
-			status["database"] = "healthy"
-		}
-	} else {
-		status["database"] = "unhealthy"
-		overall = "unhealthy"
-		hc.logger.Error("Database connection is nil")
+// logUnhealthy 记录失败的检查项（缓存结果不重复记录）
+func (hc *HealthController) logUnhealthy(report health.Report) {
+	if report.Cached {
+		return
 	}
-
-	// 检查Redis连接
-	if gredis.Get("health_check") == "" {
-		// 尝试设置一个临时键值
-		err := gredis.Set("health_check", "ok", time.Minute)
-		if err != nil {
-			status["redis"] = "unhealthy"
-			overall = "unhealthy"
-			hc.logger.Errorf("Redis health check failed: %v", err)
-		} else {
-			status["redis"] = "healthy"
+	for name, res := range report.Checks {
+		if res.Status != health.StatusHealthy {
+			hc.logger.Warnf("Health check %s is %s: %s", name, res.Status, res.Error)
 		}
-	} else {
-		status["redis"] = "healthy"
-	}
-
-	status["overall"] = overall
-	return status
-}
-
-// checkReadiness 检查应用就绪状态
-func (hc *HealthController) checkReadiness() bool {
-	// 检查数据库是否可用
-	db := models.GetDb()
-	if db == nil {
-		hc.logger.Error("Database connection is nil in readiness check")
-		return false
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		hc.logger.Errorf("Failed to get SQL DB in readiness check: %v", err)
-		return false
-	}
-
-	if err := sqlDB.Ping(); err != nil {
-		hc.logger.Errorf("Database ping failed in readiness check: %v", err)
-		return false
 	}
-
-	// 检查Redis是否可用
-	if err := gredis.Set("readiness_check", "ok", time.Minute); err != nil {
-		hc.logger.Errorf("Redis connection failed in readiness check: %v", err)
-		return false
-	}
-
-	return true
 }
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+
+	"justus/internal/container"
+	"justus/pkg/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImageController 图片变体控制器
+type ImageController struct {
+	uploadService container.UploadService
+	logger        container.Logger
+}
+
+// NewImageController 创建图片变体控制器实例
+func NewImageController(uploadService container.UploadService, logger container.Logger) *ImageController {
+	return &ImageController{uploadService: uploadService, logger: logger}
+}
+
+// Variant 重定向到图片变体，变体不存在时先从原图生成
+// 地址形如 /variants/thumb/images/ab/ab12….png，与原图一样无需认证
+func (ic *ImageController) Variant(c *gin.Context) {
+	appG := app.Gin{C: c}
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	url, err := ic.uploadService.Variant(c.Request.Context(), key, c.Param("variant"))
+	if err != nil {
+		appG.Fail(err)
+		return
+	}
+	// 变体内容只由原图决定，重定向结果可长期缓存
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Redirect(http.StatusFound, url)
+}
@@ -23,7 +23,7 @@ func NewTestController(logger container.Logger) *TestController {
 func (tc *TestController) Test(c *gin.Context) {
 	appG := app.Gin{C: c}
 
-	tc.logger.Info("API test endpoint accessed")
+	tc.logger.WithContext(c.Request.Context()).Info("API test endpoint accessed")
 
 	appG.Success(gin.H{
 		"message": "test ok",
@@ -1,31 +1,44 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"justus/internal/models"
-	"justus/pkg/gredis"
-	"justus/pkg/logger"
+	"os/signal"
+	"syscall"
+
+	"justus/internal/bootstrap"
 	"justus/pkg/setting"
-	"justus/pkg/util"
-	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
-func init() {
-	setting.Setup()
-	logger.Setup()
-	models.Setup()
-	util.Setup()
-	gredis.Setup()
-}
-
 func main() {
-	var err error
+	app, err := bootstrap.New(bootstrap.Options{})
+	if err != nil {
+		fmt.Println("bootstrap:", err)
+		return
+	}
+	defer app.Close()
+
+	trash := app.Context.Container.TrashService
+	qrcodes := app.Context.Container.QrCodeService
+
 	c := cron.New(cron.WithSeconds())
-	//添加2秒钟定时任务 处理
-	_, err = c.AddFunc("*/2 * * * * *", func() {
-		fmt.Println("start_time_topic:", time.Now())
+	// 回收站清理：永久删除超过保留期的软删除记录
+	_, err = c.AddFunc(setting.TrashSetting.PurgeSpec, func() {
+		if _, err := trash.PurgeExpired(context.Background(), setting.TrashSetting.Retention); err != nil {
+			app.Logger.Errorf("cron: purge trash error: %v", err)
+		}
+	})
+	if err != nil {
+		fmt.Println("AddFun:", err)
+		return
+	}
+	// 二维码缓存清理：每小时删除生成时间超过 app.QrCodeCacheTTL 的文件
+	_, err = c.AddFunc("0 0 * * * *", func() {
+		if _, err := qrcodes.PurgeCache(context.Background(), setting.AppSetting.QrCodeCacheTTL); err != nil {
+			app.Logger.Errorf("cron: purge qrcode cache error: %v", err)
+		}
 	})
 	if err != nil {
 		fmt.Println("AddFun:", err)
@@ -33,12 +46,11 @@ func main() {
 	}
 
 	c.Start()
-	t1 := time.NewTimer(time.Second * 10)
-	for {
-		select {
-		case <-t1.C:
-			t1.Reset(time.Second * 10)
 
-		}
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	// 等待正在执行的任务结束
+	<-c.Stop().Done()
 }
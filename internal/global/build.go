@@ -0,0 +1,17 @@
+package global
+
+import "time"
+
+// BuildInfo 构建信息，由 main 包通过 -ldflags 注入后写入
+type BuildInfo struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+var (
+	// Build 当前进程的构建信息
+	Build = BuildInfo{Version: "dev"}
+	// StartTime 进程启动时间，用于计算运行时长
+	StartTime = time.Now()
+)
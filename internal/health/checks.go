@@ -0,0 +1,169 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	// diskDegradedRatio 可用空间低于该比例时降级
+	diskDegradedRatio = 0.10
+	// diskUnhealthyRatio 可用空间低于该比例时不可用
+	diskUnhealthyRatio = 0.02
+	// poolBusyRatio 连接池使用中的连接达到上限的该比例时降级
+	poolBusyRatio = 0.9
+)
+
+// DatabaseCheck 数据库 Ping 及连接池状态
+func DatabaseCheck(getDB func() *gorm.DB) CheckFunc {
+	return func(ctx context.Context) Result {
+		db := getDB()
+		if db == nil {
+			return Unhealthy(errors.New("database not initialized"), nil)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return Unhealthy(err, nil)
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return Unhealthy(err, nil)
+		}
+
+		stats := sqlDB.Stats()
+		details := map[string]interface{}{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+			"max_open":         stats.MaxOpenConnections,
+			"wait_count":       stats.WaitCount,
+			"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+		}
+		if stats.MaxOpenConnections > 0 && float64(stats.InUse) >= float64(stats.MaxOpenConnections)*poolBusyRatio {
+			return Degraded("connection pool nearly exhausted", details)
+		}
+		return Healthy(details)
+	}
+}
+
+// RedisCheck Redis Ping 及连接池状态
+func RedisCheck(getClient func() *redis.Client) CheckFunc {
+	return func(ctx context.Context) Result {
+		client := getClient()
+		if client == nil {
+			return Unhealthy(errors.New("redis not initialized"), nil)
+		}
+		if err := client.Ping(ctx).Err(); err != nil {
+			return Unhealthy(err, nil)
+		}
+
+		stats := client.PoolStats()
+		return Healthy(map[string]interface{}{
+			"total_conns": stats.TotalConns,
+			"idle_conns":  stats.IdleConns,
+			"stale_conns": stats.StaleConns,
+			"timeouts":    stats.Timeouts,
+		})
+	}
+}
+
+// PingCheck 包装不支持 context 的 Ping 函数（如 zincsearch.Client.Ping），超时由注册表兜底
+func PingCheck(ping func() error) CheckFunc {
+	return func(ctx context.Context) Result {
+		if err := ping(); err != nil {
+			return Unhealthy(err, nil)
+		}
+		return Healthy(nil)
+	}
+}
+
+// TCPCheck 检查 endpoint 的 TCP 可达性，endpoint 可以是 host、host:port 或 URL
+func TCPCheck(endpoint string, defaultPort string) CheckFunc {
+	return func(ctx context.Context) Result {
+		addr, err := dialAddress(endpoint, defaultPort)
+		if err != nil {
+			return Unhealthy(err, nil)
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return Unhealthy(err, map[string]interface{}{"address": addr})
+		}
+		conn.Close()
+		return Healthy(map[string]interface{}{"address": addr})
+	}
+}
+
+// DiskCheck 检查 path 所在分区的可用空间
+func DiskCheck(path string) CheckFunc {
+	return func(ctx context.Context) Result {
+		usage, err := diskUsage(path)
+		if err != nil {
+			return Unhealthy(err, map[string]interface{}{"path": path})
+		}
+
+		details := map[string]interface{}{
+			"path":        path,
+			"total_bytes": usage.Total,
+			"free_bytes":  usage.Free,
+			"used_bytes":  usage.Total - usage.Free,
+		}
+		if usage.Total == 0 {
+			return Healthy(details)
+		}
+		ratio := float64(usage.Free) / float64(usage.Total)
+		details["free_ratio"] = ratio
+		switch {
+		case ratio < diskUnhealthyRatio:
+			return Unhealthy(fmt.Errorf("only %.1f%% disk space free", ratio*100), details)
+		case ratio < diskDegradedRatio:
+			return Degraded(fmt.Sprintf("only %.1f%% disk space free", ratio*100), details)
+		}
+		return Healthy(details)
+	}
+}
+
+// BinaryCheck 检查外部可执行文件是否可用，detect 返回可执行文件路径
+func BinaryCheck(detect func() (string, error)) CheckFunc {
+	return func(ctx context.Context) Result {
+		path, err := detect()
+		if err != nil {
+			return Unhealthy(err, nil)
+		}
+		if _, err := exec.LookPath(path); err != nil {
+			return Unhealthy(err, map[string]interface{}{"path": path})
+		}
+		return Healthy(map[string]interface{}{"path": path})
+	}
+}
+
+// dialAddress 将 endpoint 规范为 host:port
+func dialAddress(endpoint, defaultPort string) (string, error) {
+	if endpoint == "" {
+		return "", errors.New("endpoint not configured")
+	}
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", err
+		}
+		if u.Port() == "" && u.Scheme == "http" {
+			return net.JoinHostPort(u.Hostname(), "80"), nil
+		}
+		if u.Port() == "" {
+			return net.JoinHostPort(u.Hostname(), defaultPort), nil
+		}
+		return u.Host, nil
+	}
+	if _, _, err := net.SplitHostPort(endpoint); err == nil {
+		return endpoint, nil
+	}
+	return net.JoinHostPort(endpoint, defaultPort), nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package health
+
+import "os"
+
+// diskStat 分区容量（字节）
+type diskStat struct {
+	Total uint64
+	Free  uint64
+}
+
+// diskUsage 非 unix 平台仅校验目录存在，不统计容量
+func diskUsage(path string) (diskStat, error) {
+	_, err := os.Stat(path)
+	return diskStat{}, err
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package health
+
+import "syscall"
+
+// diskStat 分区容量（字节）
+type diskStat struct {
+	Total uint64
+	Free  uint64
+}
+
+// diskUsage 基于 statfs 获取分区容量，Free 为非特权用户可用空间
+func diskUsage(path string) (diskStat, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return diskStat{}, err
+	}
+	return diskStat{
+		Total: uint64(fs.Blocks) * uint64(fs.Bsize),
+		Free:  uint64(fs.Bavail) * uint64(fs.Bsize),
+	}, nil
+}
@@ -0,0 +1,265 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+const (
+	// DefaultTimeout 单项检查的默认超时时间
+	DefaultTimeout = 2 * time.Second
+	// DefaultCacheTTL 检查结果的默认缓存时间，避免探活请求频繁打到下游依赖
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Result 单项检查结果
+type Result struct {
+	Status    Status                 `json:"status"`
+	Critical  bool                   `json:"critical"`
+	LatencyMs float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Healthy 返回健康结果
+func Healthy(details map[string]interface{}) Result {
+	return Result{Status: StatusHealthy, Details: details}
+}
+
+// Degraded 返回降级结果（可用但存在风险）
+func Degraded(reason string, details map[string]interface{}) Result {
+	return Result{Status: StatusDegraded, Error: reason, Details: details}
+}
+
+// Unhealthy 返回不可用结果
+func Unhealthy(err error, details map[string]interface{}) Result {
+	return Result{Status: StatusUnhealthy, Error: err.Error(), Details: details}
+}
+
+// CheckFunc 检查函数，需在 ctx 取消后尽快返回
+type CheckFunc func(ctx context.Context) Result
+
+// Check 检查项定义
+type Check struct {
+	Name string
+	// Critical 关键依赖：失败时整体为 unhealthy 且不再就绪；非关键依赖失败只会使整体降级
+	Critical bool
+	// Timeout 单项超时，为0时使用 DefaultTimeout
+	Timeout time.Duration
+	Run     CheckFunc
+}
+
+// Report 整体检查报告
+type Report struct {
+	Status    Status            `json:"status"`
+	Checks    map[string]Result `json:"checks"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Cached    bool              `json:"cached"`
+}
+
+// Summary 对外公开的检查摘要，只含整体与各项状态；错误信息、连接池、磁盘路径等细节
+// 仅通过需认证的后台健康详情接口返回
+type Summary struct {
+	Status    Status            `json:"status"`
+	Checks    map[string]Status `json:"checks"`
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// Summary 去除细节后的报告摘要
+func (r Report) Summary() Summary {
+	checks := make(map[string]Status, len(r.Checks))
+	for name, res := range r.Checks {
+		checks[name] = res.Status
+	}
+	return Summary{Status: r.Status, Checks: checks, CheckedAt: r.CheckedAt}
+}
+
+// Registry 健康检查注册表，/health、/ready 与后台健康详情共用
+type Registry struct {
+	mu     sync.Mutex
+	checks []Check
+	ttl    time.Duration
+
+	runMu    sync.Mutex // 保证同一时刻只有一轮检查在执行
+	cached   *Report
+	cachedAt time.Time
+
+	draining atomic.Bool
+}
+
+// NewRegistry 创建注册表，ttl<=0 表示不缓存
+func NewRegistry(ttl time.Duration) *Registry {
+	return &Registry{ttl: ttl}
+}
+
+// Register 注册检查项，同名检查项会被替换
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.checks {
+		if r.checks[i].Name == check.Name {
+			r.checks[i] = check
+			r.invalidate()
+			return
+		}
+	}
+	r.checks = append(r.checks, check)
+	r.invalidate()
+}
+
+// Names 已注册的检查项名称
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.checks))
+	for _, c := range r.checks {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run 执行全部检查，缓存未过期时直接返回缓存结果
+func (r *Registry) Run(ctx context.Context) Report {
+	return r.run(ctx, false)
+}
+
+// Refresh 忽略缓存立即执行全部检查
+func (r *Registry) Refresh(ctx context.Context) Report {
+	return r.run(ctx, true)
+}
+
+// Ready 是否可以接收流量：未在停机排空且没有关键依赖不可用
+func (r *Registry) Ready(ctx context.Context) (bool, Report) {
+	report := r.Run(ctx)
+	return !r.Draining() && report.Status != StatusUnhealthy, report
+}
+
+func (r *Registry) run(ctx context.Context, fresh bool) Report {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	if !fresh && r.ttl > 0 {
+		r.mu.Lock()
+		cached, at := r.cached, r.cachedAt
+		r.mu.Unlock()
+		if cached != nil && time.Since(at) < r.ttl {
+			report := *cached
+			report.Cached = true
+			return report
+		}
+	}
+
+	r.mu.Lock()
+	checks := make([]Check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.Unlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusHealthy,
+		Checks:    make(map[string]Result, len(checks)),
+		CheckedAt: time.Now(),
+	}
+	for i, check := range checks {
+		res := results[i]
+		report.Checks[check.Name] = res
+		report.Status = worse(report.Status, overallStatus(res))
+	}
+
+	r.mu.Lock()
+	r.cached, r.cachedAt = &report, report.CheckedAt
+	r.mu.Unlock()
+	return report
+}
+
+// invalidate 清除缓存，调用方需持有 mu
+func (r *Registry) invalidate() {
+	r.cached = nil
+}
+
+// runCheck 带超时执行单项检查，超时或 panic 视为不可用
+func runCheck(parent context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(parent, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- Unhealthy(fmt.Errorf("check panicked: %v", p), nil)
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+
+	var res Result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res = Unhealthy(fmt.Errorf("check timed out after %s", check.Timeout), nil)
+	}
+	if res.Status == "" {
+		res.Status = StatusHealthy
+	}
+	res.Critical = check.Critical
+	res.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	res.CheckedAt = time.Now()
+	return res
+}
+
+// overallStatus 单项结果对整体状态的影响：非关键依赖不可用只算降级
+func overallStatus(res Result) Status {
+	if res.Status == StatusUnhealthy && !res.Critical {
+		return StatusDegraded
+	}
+	return res.Status
+}
+
+// worse 返回两个状态中更差的一个
+func worse(a, b Status) Status {
+	rank := map[Status]int{StatusHealthy: 0, StatusDegraded: 1, StatusUnhealthy: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// SetDraining 标记进程进入停机排空阶段，此后 Ready 恒为 false
+func (r *Registry) SetDraining(draining bool) {
+	r.draining.Store(draining)
+}
+
+// Draining 是否处于停机排空阶段
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"time"
+
+	"justus/pkg/ffmpeg"
+	"justus/pkg/setting"
+	"justus/pkg/zincsearch"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// NewDefaultRegistry 按当前配置注册全部依赖检查
+// 数据库与 Redis 为关键依赖；日志服务、磁盘与 ffmpeg 失败仅使服务降级
+func NewDefaultRegistry(db *gorm.DB, rdb *redis.Client) *Registry {
+	r := NewRegistry(DefaultCacheTTL)
+
+	r.Register(Check{
+		Name:     "database",
+		Critical: true,
+		Run:      DatabaseCheck(func() *gorm.DB { return db }),
+	})
+	r.Register(Check{
+		Name:     "redis",
+		Critical: true,
+		Run:      RedisCheck(func() *redis.Client { return rdb }),
+	})
+
+	switch setting.LoggerSetting.LogType {
+	case setting.LogFileZinc:
+		r.Register(Check{
+			Name: "zincsearch",
+			Run:  PingCheck(zincsearch.NewClient().Ping),
+		})
+	case setting.LogFileSLS:
+		r.Register(Check{
+			Name: "sls",
+			Run:  TCPCheck(setting.LoggerSetting.SLS.Endpoint, "443"),
+		})
+	}
+
+	if setting.AppSetting.RuntimeRootPath != "" {
+		r.Register(Check{
+			Name: "disk",
+			Run:  DiskCheck(setting.AppSetting.RuntimeRootPath),
+		})
+	}
+
+	r.Register(Check{
+		Name:    "ffmpeg",
+		Timeout: time.Second,
+		Run:     BinaryCheck(ffmpeg.DetectPath),
+	})
+	return r
+}
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"justus/internal/container"
 	"justus/pkg/gredis"
 	"time"
@@ -15,16 +16,26 @@ func NewCache() container.Cache {
 }
 
 // Set 设置缓存
-func (c *CacheImpl) Set(key string, data interface{}, expiration time.Duration) error {
-	return gredis.Set(key, data, expiration)
+func (c *CacheImpl) Set(ctx context.Context, key string, data interface{}, expiration time.Duration) error {
+	return gredis.Set(ctx, key, data, expiration)
 }
 
 // Get 获取缓存
-func (c *CacheImpl) Get(key string) string {
-	return gredis.Get(key)
+func (c *CacheImpl) Get(ctx context.Context, key string) string {
+	return gredis.Get(ctx, key)
 }
 
 // Del 删除缓存
-func (c *CacheImpl) Del(key string) (int64, error) {
-	return gredis.Del(key)
+func (c *CacheImpl) Del(ctx context.Context, key string) (int64, error) {
+	return gredis.Del(ctx, key)
+}
+
+// Incr 原子自增
+func (c *CacheImpl) Incr(ctx context.Context, key string) (int64, error) {
+	return gredis.Incr(ctx, key)
+}
+
+// DelByPattern 按匹配模式批量删除
+func (c *CacheImpl) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	return gredis.DelByPattern(ctx, pattern)
 }
@@ -1,8 +1,11 @@
 package infrastructure
 
 import (
+	"context"
+
 	"justus/internal/container"
 	"justus/internal/global"
+	"justus/internal/reqctx"
 
 	"github.com/sirupsen/logrus"
 )
@@ -12,10 +15,15 @@ type LoggerImpl struct {
 	logger *logrus.Logger
 }
 
-// NewLogger 创建Logger实例
+// NewLogger 创建Logger实例（使用全局 Logger）
 func NewLogger() container.Logger {
+	return NewLoggerWith(global.Logger)
+}
+
+// NewLoggerWith 基于指定的 logrus 实例创建Logger
+func NewLoggerWith(logger *logrus.Logger) container.Logger {
 	return &LoggerImpl{
-		logger: global.Logger,
+		logger: logger,
 	}
 }
 
@@ -63,3 +71,8 @@ func (l *LoggerImpl) Errorf(format string, args ...interface{}) {
 func (l *LoggerImpl) WithFields(fields logrus.Fields) *logrus.Entry {
 	return l.logger.WithFields(fields)
 }
+
+// WithContext 附带请求ID、操作者等请求级字段
+func (l *LoggerImpl) WithContext(ctx context.Context) *logrus.Entry {
+	return l.logger.WithContext(ctx).WithFields(reqctx.Fields(ctx))
+}
@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"justus/internal/container"
+	"justus/pkg/setting"
+)
+
+// NewMailer 按配置创建邮件发送器；未配置 SMTP 主机时返回只写日志的实现
+func NewMailer(cfg *setting.Mail, logger container.Logger) container.Mailer {
+	if cfg == nil || cfg.Host == "" {
+		return &LogMailer{logger: logger}
+	}
+	return &SMTPMailer{cfg: *cfg}
+}
+
+// SMTPMailer 通过 SMTP（STARTTLS/PLAIN 认证）发送纯文本邮件
+type SMTPMailer struct {
+	cfg setting.Mail
+}
+
+// Send 发送邮件
+func (m *SMTPMailer) Send(ctx context.Context, msg container.MailMessage) error {
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid From %q: %w", m.cfg.From, err)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + strings.Join(msg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	// net/smtp 不支持 context，超时由调用方的请求超时兜底
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from.Address, msg.To, []byte(b.String()))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LogMailer 未配置 SMTP 时使用，只记录收件人与主题，便于开发环境从日志取链接
+type LogMailer struct {
+	logger container.Logger
+}
+
+// Send 写日志代替发送
+func (m *LogMailer) Send(ctx context.Context, msg container.MailMessage) error {
+	m.logger.WithContext(ctx).Infof("mail (not sent, SMTP not configured): to=%v subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,44 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPServerHook 以钩子形式托管 http.Server
+// 启动时同步监听端口（端口占用等错误直接返回），停止时先调用 onDrain 摘除就绪状态，
+// 等待 drainDelay 让负载均衡感知，再 Shutdown 等待进行中的请求完成
+// serveErr 接收 Serve 的非正常退出错误，供主进程感知并触发停止
+func HTTPServerHook(srv *http.Server, onDrain func(), drainDelay time.Duration, serveErr chan<- error) Hook {
+	return Hook{
+		Name: "http",
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					serveErr <- err
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if onDrain != nil {
+				onDrain()
+			}
+			if drainDelay > 0 {
+				select {
+				case <-time.After(drainDelay):
+				case <-ctx.Done():
+				}
+			}
+			srv.SetKeepAlivesEnabled(false)
+			return srv.Shutdown(ctx)
+		},
+	}
+}
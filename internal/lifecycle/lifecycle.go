@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook 生命周期钩子，OnStart/OnStop 均可为空
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Manager 按注册顺序启动、按逆序停止各组件
+// 例如：数据库 -> Redis -> HTTP 服务启动，停止时先停 HTTP 再关闭 Redis、数据库，最后刷新日志
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int // 已成功启动的钩子数量
+	stopped bool
+	logf    func(format string, args ...interface{})
+}
+
+// NewManager 创建生命周期管理器，logf 用于输出启动/停止过程，可为空
+func NewManager(logf func(format string, args ...interface{})) *Manager {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	return &Manager{logf: logf}
+}
+
+// Append 追加钩子，必须在 Start 之前调用
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start 依次执行 OnStart，任一失败时逆序停止已启动的钩子并返回错误
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			m.logf("lifecycle: starting %s", hook.Name)
+			if err := hook.OnStart(ctx); err != nil {
+				m.mu.Lock()
+				m.started = i
+				m.mu.Unlock()
+				startErr := fmt.Errorf("start %s: %w", hook.Name, err)
+				if stopErr := m.Stop(ctx); stopErr != nil {
+					return errors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+		}
+		m.mu.Lock()
+		m.started = i + 1
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop 逆序执行已启动钩子的 OnStop，单个失败不影响后续钩子；重复调用无副作用
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := m.hooks[:m.started]
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		m.logf("lifecycle: stopping %s", hook.Name)
+		if err := hook.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start_time"
+
+// RegisterGormCallbacks 注册 GORM 回调，记录每条语句的执行耗时
+func RegisterGormCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	operations := []struct {
+		name   string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, op := range operations {
+		if err := op.before("metrics:before_"+op.name, beforeStatement); err != nil {
+			return err
+		}
+		if err := op.after("metrics:after_"+op.name, afterStatement(op.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func beforeStatement(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func afterStatement(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		val, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := val.(time.Time)
+		if !ok {
+			return
+		}
+		status := "ok"
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			status = "error"
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueryDuration.WithLabelValues(operation, table, status).Observe(time.Since(start).Seconds())
+	}
+}
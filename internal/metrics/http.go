@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"justus/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute 未命中路由的统一标签，避免任意路径导致标签基数爆炸
+const unmatchedRoute = "unmatched"
+
+// Middleware HTTP 指标采集中间件
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpRequestsInFlight.Inc()
+		defer func() {
+			httpRequestsInFlight.Dec()
+
+			route := c.FullPath()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			status := strconv.Itoa(c.Writer.Status())
+			httpRequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+			httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+
+			if tenantVal, ok := c.Get("tenantId"); ok {
+				if tenantID, ok := tenantVal.(int); ok && tenantID > 0 {
+					tenantRequestsTotal.WithLabelValues(strconv.Itoa(tenantID)).Inc()
+				}
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// Handler 指标输出接口
+func Handler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	return gin.WrapH(h)
+}
+
+// Protect 指标接口访问控制：配置了 Token 时校验 Bearer Token，否则只允许白名单IP直连访问（release 模式必须配置 Token）
+func Protect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := setting.MetricsSetting
+		if cfg.Token != "" {
+			token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// 使用连接的对端地址：ClientIP 会采信 X-Forwarded-For，可被客户端伪造
+		if !ipAllowed(c.RemoteIP(), cfg.AllowIPs) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipAllowed 判断IP是否命中白名单（支持单IP与CIDR）
+func ipAllowed(clientIP string, allowList []string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range allowList {
+		if strings.Contains(allowed, "/") {
+			if _, network, err := net.ParseCIDR(allowed); err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"justus/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestProtectIgnoresForwardedFor 白名单按连接对端地址判断，伪造 X-Forwarded-For 无效
+func TestProtectIgnoresForwardedFor(t *testing.T) {
+	cfg := *setting.MetricsSetting
+	t.Cleanup(func() { *setting.MetricsSetting = cfg })
+	setting.MetricsSetting.Token = ""
+	setting.MetricsSetting.AllowIPs = []string{"127.0.0.1"}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/metrics", Protect(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, c := range []struct {
+		remote, xff string
+		want        int
+	}{
+		{"203.0.113.9:4000", "127.0.0.1", http.StatusForbidden},
+		{"127.0.0.1:4000", "", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.RemoteAddr = c.remote
+		if c.xff != "" {
+			req.Header.Set("X-Forwarded-For", c.xff)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Fatalf("remote=%s xff=%s: status=%d, want %d", c.remote, c.xff, w.Code, c.want)
+		}
+	}
+}
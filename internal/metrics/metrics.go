@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "justus"
+
+// Registry 应用独立的指标注册表，避免与第三方库的默认注册表互相污染
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求总数，按路由模板与状态码区分",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时分布",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "正在处理中的 HTTP 请求数",
+	})
+
+	tenantRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_requests_total",
+		Help:      "按租户统计的 HTTP 请求总数",
+	}, []string{"tenant_id"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM 语句执行耗时分布",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	redisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis 命令执行耗时分布",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		tenantRequestsTotal,
+		dbQueryDuration,
+		redisCommandDuration,
+	)
+}
+
+// RegisterDBStats 注册数据库连接池指标
+func RegisterDBStats(sqlDB *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisStartKey struct{}
+
+// RedisHook go-redis 钩子，记录命令耗时
+type RedisHook struct{}
+
+// NewRedisHook 创建 Redis 指标钩子
+func NewRedisHook() redis.Hook {
+	return RedisHook{}
+}
+
+// BeforeProcess 记录命令开始时间
+func (RedisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+// AfterProcess 上报单条命令耗时
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if start, ok := ctx.Value(redisStartKey{}).(time.Time); ok {
+		redisCommandDuration.WithLabelValues(cmd.Name(), redisStatus(cmd.Err())).Observe(time.Since(start).Seconds())
+	}
+	return nil
+}
+
+// BeforeProcessPipeline 记录管道开始时间
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+// AfterProcessPipeline 上报管道整体耗时
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	if start, ok := ctx.Value(redisStartKey{}).(time.Time); ok {
+		status := "ok"
+		for _, cmd := range cmds {
+			if redisStatus(cmd.Err()) == "error" {
+				status = "error"
+				break
+			}
+		}
+		redisCommandDuration.WithLabelValues("pipeline", status).Observe(time.Since(start).Seconds())
+	}
+	return nil
+}
+
+// redisStatus redis.Nil 表示key不存在，不计为错误
+func redisStatus(err error) string {
+	if err != nil && err != redis.Nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"justus/internal/global"
+	"justus/pkg/setting"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Setup 为传入的数据库与 Redis 客户端挂载指标采集，任一为 nil 时跳过对应部分
+func Setup(db *gorm.DB, rdb *redis.Client) {
+	if !setting.MetricsSetting.Enabled {
+		return
+	}
+
+	if db != nil {
+		if err := RegisterGormCallbacks(db); err != nil {
+			global.Logger.Warnf("注册GORM指标回调失败: %v", err)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			if err := RegisterDBStats(sqlDB, setting.DatabaseSetting.Name); err != nil {
+				global.Logger.Warnf("注册数据库连接池指标失败: %v", err)
+			}
+		}
+	}
+
+	if rdb != nil {
+		rdb.AddHook(NewRedisHook())
+	}
+}
@@ -31,8 +31,14 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
-		// 校验是否管理员用户
-		isAdminUser, err := models.IsAdminUser(uid)
+		// 校验是否管理员用户；带租户上下文时要求在该租户内的成员资格处于启用状态
+		var isAdminUser bool
+		var err error
+		if tenantVal, ok := c.Get("tenantId"); ok && tenantVal.(int) > 0 {
+			isAdminUser, err = models.IsAdminUserInTenant(c.Request.Context(), uid, uint(tenantVal.(int)))
+		} else {
+			isAdminUser, err = models.IsAdminUser(c.Request.Context(), uid)
+		}
 		if err != nil {
 			appG.Error(e.ERROR_DATABASE_QUERY)
 			c.Abort()
@@ -83,7 +89,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		tenantID := uint(tenantVal.(int))
 
 		// 基于租户白名单的权限校验
-		hasPermission, err := models.HasAdminPermissionInTenant(uid, permission, tenantID)
+		hasPermission, err := models.HasAdminPermissionInTenant(c.Request.Context(), uid, permission, tenantID)
 		if err != nil {
 			appG.Error(e.ERROR_DATABASE_QUERY)
 			c.Abort()
@@ -110,6 +110,11 @@ func outputRequestLog(c *gin.Context, blw *bodyLogWriter, requestBody string, du
 			logData["user_id"] = uid
 		}
 	}
+	if tenantID, exists := c.Get("tenantId"); exists {
+		if tid, ok := tenantID.(int); ok && tid > 0 {
+			logData["tenant_id"] = tid
+		}
+	}
 	if username, exists := c.Get("username"); exists {
 		if name, ok := username.(string); ok && name != "" {
 			logData["username"] = name
@@ -190,7 +195,7 @@ func outputRequestLog(c *gin.Context, blw *bodyLogWriter, requestBody string, du
 	logLevel := getLogLevel(c.Writer.Status())
 
 	// 输出标准JSON格式日志
-	global.Logger.WithFields(logrus.Fields(logData)).Log(logLevel, "HTTP Request")
+	global.Logger.WithContext(c.Request.Context()).WithFields(logrus.Fields(logData)).Log(logLevel, "HTTP Request")
 }
 
 // getLogLevel 根据HTTP状态码获取日志级别
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"errors"
+	"justus/internal/reqctx"
 	"justus/pkg/app"
 	"justus/pkg/e"
 	"justus/pkg/util"
@@ -55,6 +56,12 @@ func JWT() gin.HandlerFunc {
 				if len(claims.TenantIDs) > 0 {
 					c.Set("tenantIds", claims.TenantIDs)
 				}
+				// 操作者随 context 传入 service/repository
+				c.Request = c.Request.WithContext(reqctx.WithActor(c.Request.Context(), reqctx.Actor{
+					UserID:   userId,
+					TenantID: claims.TenantID,
+					IsSuper:  claims.IsSuper,
+				}))
 			}
 
 		}
@@ -0,0 +1,50 @@
+package locale
+
+import (
+	"context"
+
+	"justus/internal/reqctx"
+	"justus/pkg/glange"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header 客户端语言头，优先于 Accept-Language
+const Header = "lange"
+
+// Locale 从 lange 头或 Accept-Language 解析客户端语言，写入 gin 上下文（locale）与 c.Request 的 context
+// 无法匹配已加载的语言时不写入，由响应层保持默认中文提示
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if locale, ok := resolve(c); ok {
+			set(c, locale)
+		}
+		c.Next()
+	}
+}
+
+// UserFallback 请求未携带语言头时使用用户资料中的语言偏好（User.Lang），需挂在 JWT 之后
+func UserFallback(lookup func(ctx context.Context, userID int) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("locale") == "" {
+			if uid, ok := c.Get("userId"); ok {
+				if locale, ok := glange.Match(lookup(c.Request.Context(), uid.(int))); ok {
+					set(c, locale)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func resolve(c *gin.Context) (string, bool) {
+	if locale, ok := glange.Match(c.GetHeader(Header)); ok {
+		return locale, true
+	}
+	return glange.Match(c.GetHeader("Accept-Language"))
+}
+
+func set(c *gin.Context, locale string) {
+	c.Set("locale", locale)
+	c.Request = c.Request.WithContext(reqctx.WithLocale(c.Request.Context(), locale))
+}
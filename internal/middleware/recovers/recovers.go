@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"justus/internal/global"
+	"net/http"
 	"runtime"
 )
 
@@ -25,8 +26,9 @@ func Recover() gin.HandlerFunc {
 
 				}
 				//errInfo["error"] = string(debug.Stack()) //记录全部信息
-				global.Logger.WithFields(errInfo).Error("错误:", err, "\n", "错误位置:", errInfo["2"])
+				global.Logger.WithContext(c.Request.Context()).WithFields(errInfo).Error("错误:", err, "\n", "错误位置:", errInfo["2"])
 				//global.Logger.Error("捕获异常:", err)
+				c.AbortWithStatus(http.StatusInternalServerError)
 			}
 
 		}()
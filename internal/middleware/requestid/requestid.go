@@ -0,0 +1,38 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"justus/internal/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header 请求ID头，上游（网关/负载均衡）已生成时沿用
+const Header = "X-Request-ID"
+
+// maxLen 沿用上游请求ID的最大长度，超出时重新生成
+const maxLen = 128
+
+// RequestID 为每个请求分配请求ID，写入响应头、gin 上下文（request_id）与 c.Request 的 context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if id == "" || len(id) > maxLen {
+			id = newID()
+		}
+
+		c.Set("request_id", id)
+		c.Header(Header, id)
+		c.Request = c.Request.WithContext(reqctx.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// newID 生成 32 位十六进制随机ID
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,36 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"justus/internal/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, reqctx.RequestID(c.Request.Context()))
+	})
+
+	// 沿用上游请求ID
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(Header, "upstream-id")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(Header); got != "upstream-id" || w.Body.String() != "upstream-id" {
+		t.Fatalf("header=%q body=%q, want upstream-id", got, w.Body.String())
+	}
+
+	// 缺失时生成
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get(Header); len(got) != 32 || w.Body.String() != got {
+		t.Fatalf("generated header=%q body=%q", got, w.Body.String())
+	}
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Snapshot 请求统计快照
+type Snapshot struct {
+	TotalRequests     int64   `json:"total_requests"`
+	TotalErrors       int64   `json:"total_errors"`
+	RequestsToday     int64   `json:"requests_today"`
+	ErrorsToday       int64   `json:"errors_today"`
+	InFlight          int64   `json:"in_flight"`
+	ErrorRate         float64 `json:"error_rate"`
+	AverageResponseMs float64 `json:"average_response_ms"`
+}
+
+// collector 进程内请求计数器
+type collector struct {
+	total       int64
+	errors      int64
+	inFlight    int64
+	latencyNano int64
+
+	mu          sync.Mutex
+	day         string
+	todayTotal  int64
+	todayErrors int64
+}
+
+var defaultCollector = &collector{}
+
+// Stats 请求统计中间件：记录请求数、5xx错误数、在途请求数与累计耗时
+func Stats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		atomic.AddInt64(&defaultCollector.inFlight, 1)
+		defer func() {
+			atomic.AddInt64(&defaultCollector.inFlight, -1)
+			defaultCollector.record(c.Writer.Status() >= 500, time.Since(start))
+		}()
+
+		c.Next()
+	}
+}
+
+// record 记录一次请求
+func (s *collector) record(isError bool, latency time.Duration) {
+	atomic.AddInt64(&s.total, 1)
+	atomic.AddInt64(&s.latencyNano, int64(latency))
+	if isError {
+		atomic.AddInt64(&s.errors, 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollDay()
+	s.todayTotal++
+	if isError {
+		s.todayErrors++
+	}
+}
+
+// rollDay 跨天时重置当日计数，调用方需持有锁
+func (s *collector) rollDay() {
+	today := time.Now().Format("2006-01-02")
+	if s.day != today {
+		s.day = today
+		s.todayTotal = 0
+		s.todayErrors = 0
+	}
+}
+
+// GetSnapshot 获取当前统计快照
+func GetSnapshot() Snapshot {
+	s := defaultCollector
+	total := atomic.LoadInt64(&s.total)
+	errs := atomic.LoadInt64(&s.errors)
+
+	s.mu.Lock()
+	s.rollDay()
+	todayTotal, todayErrors := s.todayTotal, s.todayErrors
+	s.mu.Unlock()
+
+	snap := Snapshot{
+		TotalRequests: total,
+		TotalErrors:   errs,
+		RequestsToday: todayTotal,
+		ErrorsToday:   todayErrors,
+		InFlight:      atomic.LoadInt64(&s.inFlight),
+	}
+	if total > 0 {
+		snap.ErrorRate = float64(errs) / float64(total)
+		snap.AverageResponseMs = float64(atomic.LoadInt64(&s.latencyNano)) / float64(total) / float64(time.Millisecond)
+	}
+	return snap
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 邀请状态
+const (
+	InvitationPending  = 0
+	InvitationAccepted = 1
+	InvitationRevoked  = 2
+)
+
+// AdminInvitation 管理员邀请：按邮箱邀请加入租户，接受后创建账号（或复用同邮箱账号）并绑定角色
+type AdminInvitation struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;comment:邀请ID，主键"`
+	TenantID   uint      `json:"tenant_id" gorm:"not null;comment:租户ID;index:idx_invitation_tenant"`
+	Email      string    `json:"email" gorm:"not null;size:100;comment:被邀请邮箱;index:idx_invitation_email"`
+	TokenHash  string    `json:"-" gorm:"not null;size:64;comment:邀请令牌SHA-256，明文只出现在邮件中;uniqueIndex:uk_invitation_token"`
+	RoleIDs    string    `json:"-" gorm:"size:255;default:'';comment:接受后绑定的角色ID，逗号分隔"`
+	Department string    `json:"department" gorm:"size:100;default:'';comment:预设部门"`
+	Position   string    `json:"position" gorm:"size:50;default:'';comment:预设职位"`
+	Status     int       `json:"status" gorm:"default:0;comment:状态：0-待接受，1-已接受，2-已撤销;index:idx_invitation_status"`
+	InvitedBy  uint      `json:"invited_by" gorm:"default:0;comment:邀请人管理员ID"`
+	AcceptedBy uint      `json:"accepted_by" gorm:"default:0;comment:接受后对应的管理员ID"`
+	ExpiresAt  GormTime  `json:"expires_at" gorm:"comment:过期时间"`
+	AcceptedAt *GormTime `json:"accepted_at" gorm:"comment:接受时间"`
+	CreatedAt  GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
+	UpdatedAt  GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
+}
+
+// TableName 映射物理表
+func (AdminInvitation) TableName() string { return "ay_admin_invitations" }
+
+// Roles 解析预设角色ID
+func (inv *AdminInvitation) Roles() []uint {
+	var ids []uint
+	for _, part := range strings.Split(inv.RoleIDs, ",") {
+		if id, err := strconv.ParseUint(part, 10, 64); err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// SetRoles 写入预设角色ID
+func (inv *AdminInvitation) SetRoles(ids []uint) {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	inv.RoleIDs = strings.Join(parts, ",")
+}
+
+// Usable 邀请是否仍可接受
+func (inv *AdminInvitation) Usable(now time.Time) bool {
+	return inv.Status == InvitationPending && now.Before(inv.ExpiresAt.Time)
+}
+
+// CreateAdminInvitation 创建邀请，同租户同邮箱之前未接受的邀请一并撤销，保证只有最新链接有效
+func CreateAdminInvitation(ctx context.Context, inv *AdminInvitation) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&AdminInvitation{}).
+			Where("tenant_id = ? AND email = ? AND status = ?", inv.TenantID, inv.Email, InvitationPending).
+			Update("status", InvitationRevoked).Error; err != nil {
+			return err
+		}
+		return tx.Create(inv).Error
+	})
+}
+
+// GetAdminInvitationByTokenHash 按令牌哈希获取邀请
+func GetAdminInvitationByTokenHash(ctx context.Context, tokenHash string) (*AdminInvitation, error) {
+	var inv AdminInvitation
+	if err := db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListPendingAdminInvitations 租户内未接受且未过期的邀请
+func ListPendingAdminInvitations(ctx context.Context, tenantID uint) ([]AdminInvitation, error) {
+	var list []AdminInvitation
+	err := db.WithContext(ctx).
+		Where("tenant_id = ? AND status = ? AND expires_at > ?", tenantID, InvitationPending, time.Now()).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// RevokeAdminInvitation 撤销租户内的待接受邀请
+func RevokeAdminInvitation(ctx context.Context, id, tenantID uint) error {
+	result := db.WithContext(ctx).Model(&AdminInvitation{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", id, tenantID, InvitationPending).
+		Update("status", InvitationRevoked)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AcceptAdminInvitation 接受邀请：admin.ID 为 0 时创建账号，随后追加租户角色并标记邀请已接受
+// 以 status 作为条件更新，并发重复提交时只有一次成功
+func AcceptAdminInvitation(ctx context.Context, inv *AdminInvitation, admin *AdminUser) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := GormTime{Time: time.Now()}
+		result := tx.Model(&AdminInvitation{}).
+			Where("id = ? AND status = ?", inv.ID, InvitationPending).
+			Updates(map[string]interface{}{"status": InvitationAccepted, "accepted_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if admin.ID == 0 {
+			if err := tx.Create(admin).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&AdminInvitation{}).Where("id = ?", inv.ID).Update("accepted_by", admin.ID).Error; err != nil {
+			return err
+		}
+		return addAdminRoles(tx, admin.ID, inv.TenantID, inv.Roles(), inv.InvitedBy)
+	})
+}
+
+// addAdminRoles 追加租户角色，已存在的绑定保持不变
+func addAdminRoles(tx *gorm.DB, adminUserID, tenantID uint, roleIDs []uint, assignedBy uint) error {
+	status, err := memberStatus(tx, adminUserID, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, rid := range roleIDs {
+		row := AdminUserRole{AdminUserID: adminUserID, RoleID: rid, TenantID: tenantID, AssignedBy: assignedBy}
+		if err := tx.Where(AdminUserRole{AdminUserID: adminUserID, RoleID: rid, TenantID: tenantID}).
+			FirstOrCreate(&row).Error; err != nil {
+			return err
+		}
+	}
+	return setMemberStatus(tx, adminUserID, tenantID, status)
+}
@@ -1,10 +1,16 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"justus/internal/global"
-	"justus/pkg/setting"
+	"justus/pkg/query"
+	"justus/pkg/upload"
 	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AdminUser 后台管理用户模型
@@ -29,9 +35,18 @@ type AdminUser struct {
 	CreatedBy         uint      `json:"created_by" gorm:"default:0;comment:创建者ID"`
 	CreatedAt         GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间;index:idx_created_at"`
 	UpdatedAt         GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt         *GormTime `json:"deleted_at" gorm:"index;comment:软删除时间"`
+	DeletedAt         DeletedAt `json:"deleted_at" gorm:"index;comment:软删除时间"`
+
+	// MemberStatus 在当前租户内的成员状态，仅租户内查询时填充
+	MemberStatus *int `json:"member_status,omitempty" gorm:"-"`
 }
 
+// ErrLastSuperAdmin 操作会删除最后一个启用的超级管理员
+var ErrLastSuperAdmin = errors.New("cannot remove the last active super admin")
+
+// TableName 映射物理表
+func (AdminUser) TableName() string { return "ay_admin_users" }
+
 // AdminUserDetail 管理员用户详细信息结构体
 type AdminUserDetail struct {
 	ID               uint   `json:"id"`
@@ -43,6 +58,7 @@ type AdminUserDetail struct {
 	Department       string `json:"department"`
 	Position         string `json:"position"`
 	Status           int    `json:"status"`
+	MemberStatus     *int   `json:"member_status,omitempty"` // 在当前租户内的成员状态
 	IsSuper          bool   `json:"is_super"`
 	Role             string `json:"role,omitempty"` // 用户角色
 	LastLoginAt      string `json:"last_login_at"`
@@ -50,26 +66,24 @@ type AdminUserDetail struct {
 	FailedLoginCount int    `json:"failed_login_count"`
 	CreatedAt        string `json:"created_at"`
 	UpdatedAt        string `json:"updated_at"`
+
+	// Avatars 头像各变体（如 thumb、medium）的地址
+	Avatars map[string]string `json:"avatars,omitempty"`
 }
 
-// 获取头像完整URL
-func (au *AdminUser) getAvatarUrl() string {
-	if strings.Contains(au.Avatar, "http") {
-		return au.Avatar
-	} else if au.Avatar != "" {
-		return setting.AppSetting.ImageUrl + "/" + au.Avatar
-	}
-	return ""
+// 获取头像完整URL，variant 为空时返回原图，否则返回 upload.Image.Variants 中对应变体的地址
+func (au *AdminUser) getAvatarUrl(variant string) string {
+	return upload.ImageURL(au.Avatar, variant)
 }
 
-// Format 格式化管理员用户信息
-func (au *AdminUser) Format() *AdminUserDetail {
+// Format 格式化管理员用户信息（会查询角色）
+func (au *AdminUser) Format(ctx context.Context) *AdminUserDetail {
 	if au.ID <= 0 {
 		return nil
 	}
 
 	// 获取用户角色
-	roles, _ := GetAdminUserRoles(au.ID)
+	roles, _ := GetAdminUserRoles(ctx, au.ID)
 	var roleNames []string
 	for _, role := range roles {
 		roleNames = append(roleNames, role.Name)
@@ -90,11 +104,13 @@ func (au *AdminUser) Format() *AdminUserDetail {
 		Username:         au.Username,
 		Email:            au.Email,
 		Phone:            au.Phone,
-		Avatar:           au.getAvatarUrl(),
+		Avatar:           au.getAvatarUrl(""),
+		Avatars:          upload.ImageURLs(au.Avatar),
 		RealName:         au.RealName,
 		Department:       au.Department,
 		Position:         au.Position,
 		Status:           au.Status,
+		MemberStatus:     au.MemberStatus,
 		IsSuper:          au.IsSuper,
 		Role:             roleName,
 		LastLoginAt:      lastLoginAt,
@@ -106,9 +122,9 @@ func (au *AdminUser) Format() *AdminUserDetail {
 }
 
 // GetAdminUserRoles 获取管理员用户的角色列表
-func GetAdminUserRoles(adminUserID uint) ([]*Role, error) {
+func GetAdminUserRoles(ctx context.Context, adminUserID uint) ([]*Role, error) {
 	var roles []*Role
-	err := db.Table("ay_roles r").
+	err := db.WithContext(ctx).Table("ay_roles r").
 		Select("r.*").
 		Joins("JOIN ay_admin_user_roles aur ON r.id = aur.role_id").
 		Where("aur.admin_user_id = ? AND r.status = 1", adminUserID).
@@ -122,10 +138,10 @@ func GetAdminUserRoles(adminUserID uint) ([]*Role, error) {
 }
 
 // GetAdminUserInfo 获取管理员用户信息
-func (au *AdminUser) GetAdminUserInfo() (*AdminUser, error) {
+func (au *AdminUser) GetAdminUserInfo(ctx context.Context) (*AdminUser, error) {
 	var adminUser AdminUser
 	if au.ID > 0 {
-		err := db.Where("id = ?", au.ID).First(&adminUser).Error
+		err := db.WithContext(ctx).Where("id = ?", au.ID).First(&adminUser).Error
 		if err != nil {
 			global.Logger.Errorf("GetAdminUserInfo error: %v", err)
 			return &adminUser, err
@@ -137,9 +153,9 @@ func (au *AdminUser) GetAdminUserInfo() (*AdminUser, error) {
 }
 
 // GetAdminUserByUsername 根据用户名获取管理员用户
-func (au *AdminUser) GetAdminUserByUsername() (*AdminUser, error) {
+func (au *AdminUser) GetAdminUserByUsername(ctx context.Context) (*AdminUser, error) {
 	var adminUser AdminUser
-	err := db.Where("username = ?", au.Username).First(&adminUser).Error
+	err := db.WithContext(ctx).Where("username = ?", au.Username).First(&adminUser).Error
 	if err != nil {
 		global.Logger.Errorf("GetAdminUserByUsername error: %v", err)
 		return nil, err
@@ -147,47 +163,178 @@ func (au *AdminUser) GetAdminUserByUsername() (*AdminUser, error) {
 	return &adminUser, nil
 }
 
-// GetAdminUsers 获取管理员用户列表
-func GetAdminUsers(page, limit int, keyword, status, role string) ([]*AdminUser, int64, error) {
-	var adminUsers []*AdminUser
-	var total int64
+// GetAdminUserByEmail 根据邮箱获取管理员用户
+func GetAdminUserByEmail(ctx context.Context, email string) (*AdminUser, error) {
+	var adminUser AdminUser
+	if err := db.WithContext(ctx).Where("email = ?", email).First(&adminUser).Error; err != nil {
+		return nil, err
+	}
+	return &adminUser, nil
+}
 
-	query := db.Model(&AdminUser{})
+// AdminUserListSchema 管理员列表可过滤、排序与输出的字段
+var AdminUserListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":                 {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"username":           {Column: "username", Ops: query.Text, Sortable: true},
+		"email":              {Column: "email", Ops: query.Text},
+		"phone":              {Column: "phone", Ops: query.Text},
+		"real_name":          {Column: "real_name", Ops: query.Text, Sortable: true},
+		"department":         {Column: "department", Ops: query.Text, Sortable: true},
+		"position":           {Column: "position", Ops: query.Text, Sortable: true},
+		"status":             {Column: "status", Kind: query.Int, Ops: query.Exact},
+		"is_super":           {Column: "is_super", Kind: query.Bool, Ops: []query.Op{query.Eq}},
+		"login_count":        {Column: "login_count", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"last_login_at":      {Column: "last_login_at", Kind: query.Time, Ops: query.Range},
+		"created_at":         {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"avatar":             {},
+		"role":               {},
+		"failed_login_count": {},
+		"updated_at":         {},
+	},
+	Search:      []string{"username", "real_name", "email", "phone", "department"},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "created_at", Desc: true}},
+}
 
-	// 添加搜索条件
-	if keyword != "" {
-		query = query.Where("username LIKE ? OR real_name LIKE ? OR email LIKE ? OR phone LIKE ? OR department LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+// GetAdminUsers 查询在租户内绑定了角色的管理员
+func GetAdminUsers(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[*AdminUser], error) {
+	base := db.WithContext(ctx).Model(&AdminUser{}).
+		Where("id IN (SELECT admin_user_id FROM ay_admin_user_roles WHERE tenant_id = ?)", tenantID)
+	page, err := query.Find[*AdminUser](base, spec)
+	if err != nil {
+		return nil, err
 	}
-
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if err := fillMemberStatus(ctx, tenantID, page.Items); err != nil {
+		return nil, err
 	}
+	return page, nil
+}
 
-	// 如果有角色过滤条件
-	if role != "" {
-		query = query.Joins("JOIN ay_admin_user_roles aur ON ay_admin_users.id = aur.admin_user_id").
-			Joins("JOIN ay_roles r ON aur.role_id = r.id").
-			Where("r.name = ?", role)
+// GetAdminUserInTenant 获取在租户内绑定了角色的管理员；非成员按不存在处理
+func GetAdminUserInTenant(ctx context.Context, id, tenantID uint) (*AdminUser, error) {
+	var adminUser AdminUser
+	err := db.WithContext(ctx).
+		Where("id = ? AND id IN (SELECT admin_user_id FROM ay_admin_user_roles WHERE tenant_id = ?)", id, tenantID).
+		First(&adminUser).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := fillMemberStatus(ctx, tenantID, []*AdminUser{&adminUser}); err != nil {
+		return nil, err
 	}
+	return &adminUser, nil
+}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+// fillMemberStatus 批量填充管理员在租户内的成员状态
+func fillMemberStatus(ctx context.Context, tenantID uint, users []*AdminUser) error {
+	if len(users) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	var rows []AdminUserRole
+	if err := db.WithContext(ctx).Select("admin_user_id", "status").
+		Where("tenant_id = ? AND admin_user_id IN ?", tenantID, ids).
+		Find(&rows).Error; err != nil {
+		return err
 	}
+	status := make(map[uint]int, len(rows))
+	for _, r := range rows {
+		status[r.AdminUserID] = r.Status
+	}
+	for _, u := range users {
+		if s, ok := status[u.ID]; ok {
+			u.MemberStatus = &s
+		}
+	}
+	return nil
+}
 
-	// 分页查询
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&adminUsers).Error; err != nil {
-		return nil, 0, err
+// SetAdminMemberStatus 设置管理员在租户内的成员状态，不影响其在其他租户的访问
+func SetAdminMemberStatus(ctx context.Context, id, tenantID uint, status int) error {
+	res := db.WithContext(ctx).Model(&AdminUserRole{}).
+		Where("admin_user_id = ? AND tenant_id = ?", id, tenantID).
+		Update("status", status)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
+	return nil
+}
 
-	return adminUsers, total, nil
+// AdminUserInOtherTenants 管理员是否还属于 tenantID 以外的租户
+func AdminUserInOtherTenants(ctx context.Context, id, tenantID uint) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&AdminUserRole{}).
+		Where("admin_user_id = ? AND tenant_id <> ?", id, tenantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAdminUserInTenant 创建管理员并绑定租户角色（同一事务）
+func CreateAdminUserInTenant(ctx context.Context, au *AdminUser, tenantID uint, roleIDs []uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(au).Error; err != nil {
+			return err
+		}
+		return addAdminRoles(tx, au.ID, tenantID, roleIDs, au.CreatedBy)
+	})
+}
+
+// RemoveAdminUserFromTenant 解除管理员在租户内的全部角色；不再属于其他租户时改为软删除账号，
+// 并保留本租户的角色绑定，从回收站恢复后账号回到该租户
+// deleted 表示账号是否已被删除；删除的是最后一个启用的超级管理员时返回 ErrLastSuperAdmin
+func RemoveAdminUserFromTenant(ctx context.Context, id, tenantID uint) (deleted bool, err error) {
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var others int64
+		if err := tx.Model(&AdminUserRole{}).Where("admin_user_id = ? AND tenant_id <> ?", id, tenantID).Count(&others).Error; err != nil {
+			return err
+		}
+		if others > 0 {
+			return tx.Where("admin_user_id = ? AND tenant_id = ?", id, tenantID).Delete(&AdminUserRole{}).Error
+		}
+		// 锁住全部启用的超级管理员行再判断，避免并发删除各自看到“还有其他超管”
+		var supers []uint
+		if err := tx.Model(&AdminUser{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_super = ? AND status = 1", true).Pluck("id", &supers).Error; err != nil {
+			return err
+		}
+		if len(supers) == 1 && supers[0] == id {
+			return ErrLastSuperAdmin
+		}
+		deleted = true
+		return tx.Delete(&AdminUser{ID: id}).Error
+	})
+	return deleted, err
+}
+
+// purgeRelations 永久删除管理员前清理租户角色绑定
+func (*AdminUser) purgeRelations(tx *gorm.DB, ids []uint) error {
+	return tx.Where("admin_user_id IN ?", ids).Delete(&AdminUserRole{}).Error
+}
+
+// checkRestore 恢复的账号至少要在一个未删除的租户内保留角色绑定，否则无法登录任何租户
+func (*AdminUser) checkRestore(tx *gorm.DB, id uint) error {
+	var n int64
+	if err := tx.Table("ay_admin_user_roles aur").
+		Joins("JOIN ay_tenants t ON t.id = aur.tenant_id AND t.deleted_at IS NULL").
+		Where("aur.admin_user_id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRestoreOrphan
+	}
+	return nil
 }
 
 // CreateAdminUser 创建管理员用户
-func (au *AdminUser) CreateAdminUser() error {
-	err := db.Create(au).Error
+func (au *AdminUser) CreateAdminUser(ctx context.Context) error {
+	err := db.WithContext(ctx).Create(au).Error
 	if err != nil {
 		global.Logger.Errorf("CreateAdminUser error: %v", err)
 		return err
@@ -196,8 +343,8 @@ func (au *AdminUser) CreateAdminUser() error {
 }
 
 // UpdateAdminUser 更新管理员用户
-func (au *AdminUser) UpdateAdminUser() error {
-	err := db.Save(au).Error
+func (au *AdminUser) UpdateAdminUser(ctx context.Context) error {
+	err := db.WithContext(ctx).Save(au).Error
 	if err != nil {
 		global.Logger.Errorf("UpdateAdminUser error: %v", err)
 		return err
@@ -205,12 +352,21 @@ func (au *AdminUser) UpdateAdminUser() error {
 	return nil
 }
 
-// DeleteAdminUser 删除管理员用户
-func (au *AdminUser) DeleteAdminUser() error {
-	err := db.Delete(au).Error
+// DeleteAdminUser 删除管理员用户（软删除）
+func (au *AdminUser) DeleteAdminUser(ctx context.Context) error {
+	err := db.WithContext(ctx).Delete(au).Error
 	if err != nil {
 		global.Logger.Errorf("DeleteAdminUser error: %v", err)
 		return err
 	}
 	return nil
 }
+
+// CountAdminUsers 统计管理员总数
+func CountAdminUsers(ctx context.Context) (int64, error) {
+	var total int64
+	if err := db.WithContext(ctx).Model(&AdminUser{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+
+	"justus/pkg/query"
+)
+
+// 审计动作，格式为 资源.动作
+const (
+	AuditAdminUserStatus = "admin_user.status"
+	AuditAdminUserRoles  = "admin_user.roles"
+	AuditAdminUserRemove = "admin_user.remove"
+)
+
+// AuditLog 审计日志：记录管理操作的操作者、目标与结果，批量操作每条目标一条记录
+type AuditLog struct {
+	ID         uint     `json:"id" gorm:"primaryKey;autoIncrement;comment:审计日志ID，主键"`
+	TenantID   uint     `json:"tenant_id" gorm:"not null;default:0;comment:租户ID;index:idx_audit_tenant"`
+	ActorID    uint     `json:"actor_id" gorm:"not null;default:0;comment:操作者管理员ID;index:idx_audit_actor"`
+	Action     string   `json:"action" gorm:"not null;size:50;comment:动作，如admin_user.status;index:idx_audit_action"`
+	TargetType string   `json:"target_type" gorm:"not null;size:50;comment:目标类型"`
+	TargetID   uint     `json:"target_id" gorm:"not null;default:0;comment:目标ID;index:idx_audit_target"`
+	BatchID    string   `json:"batch_id" gorm:"size:32;default:'';comment:批量操作批次号，同一请求内相同;index:idx_audit_batch"`
+	Success    bool     `json:"success" gorm:"not null;comment:是否成功"`
+	Code       int      `json:"code" gorm:"default:200;comment:结果业务码"`
+	Detail     string   `json:"detail" gorm:"size:1000;default:'';comment:操作参数，JSON"`
+	RequestID  string   `json:"request_id" gorm:"size:64;default:'';comment:请求ID"`
+	CreatedAt  GormTime `json:"created_at" gorm:"autoCreateTime;comment:创建时间;index:idx_audit_created_at"`
+}
+
+// TableName 映射物理表
+func (AuditLog) TableName() string { return "ay_audit_logs" }
+
+// AuditLogListSchema 审计日志列表可过滤、排序与输出的字段
+var AuditLogListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"actor_id":    {Column: "actor_id", Kind: query.Int, Ops: query.Exact},
+		"action":      {Column: "action", Ops: query.Exact},
+		"target_type": {Column: "target_type", Ops: query.Exact},
+		"target_id":   {Column: "target_id", Kind: query.Int, Ops: query.Exact},
+		"batch_id":    {Column: "batch_id", Ops: query.Exact},
+		"success":     {Column: "success", Kind: query.Bool, Ops: []query.Op{query.Eq}},
+		"code":        {Column: "code", Kind: query.Int, Ops: query.Exact},
+		"created_at":  {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"tenant_id":   {},
+		"detail":      {},
+		"request_id":  {},
+	},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "id", Desc: true}},
+}
+
+// CreateAuditLogs 批量写入审计日志
+func CreateAuditLogs(ctx context.Context, logs []AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return db.WithContext(ctx).Create(&logs).Error
+}
+
+// ListAuditLogs 按租户查询审计日志
+func ListAuditLogs(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[AuditLog], error) {
+	return query.Find[AuditLog](db.WithContext(ctx).Model(&AuditLog{}).Where("tenant_id = ?", tenantID), spec)
+}
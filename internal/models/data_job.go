@@ -0,0 +1,140 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"justus/pkg/query"
+
+	"gorm.io/gorm"
+)
+
+// 导入导出任务类型
+const (
+	DataJobExport = "export"
+	DataJobImport = "import"
+)
+
+// 导入导出任务状态
+const (
+	DataJobPending   = 0
+	DataJobRunning   = 1
+	DataJobSucceeded = 2
+	DataJobFailed    = 3
+)
+
+// DataJob 导入导出后台任务：导出时 FileName 为结果文件，导入时为逐行校验报告（无错误行时为空）
+type DataJob struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;comment:任务ID，主键"`
+	TenantID   uint      `json:"tenant_id" gorm:"not null;default:0;comment:租户ID;index:idx_data_job_tenant"`
+	CreatedBy  uint      `json:"created_by" gorm:"not null;default:0;comment:发起人管理员ID"`
+	Kind       string    `json:"kind" gorm:"not null;size:10;comment:类型：export-导出，import-导入"`
+	Resource   string    `json:"resource" gorm:"not null;size:50;comment:数据资源，如users、roles、audit-logs"`
+	Format     string    `json:"format" gorm:"not null;size:10;comment:文件格式：xlsx、csv"`
+	Params     string    `json:"params" gorm:"size:1000;default:'';comment:导出时的列表查询参数"`
+	Status     int       `json:"status" gorm:"default:0;comment:状态：0-排队中，1-执行中，2-成功，3-失败;index:idx_data_job_status"`
+	Total      int64     `json:"total" gorm:"default:0;comment:总行数"`
+	Processed  int64     `json:"processed" gorm:"default:0;comment:已处理行数"`
+	Failed     int64     `json:"failed" gorm:"default:0;comment:导入失败行数"`
+	FileName   string    `json:"file_name" gorm:"size:255;default:'';comment:结果文件名（导出文件或导入报告）"`
+	SourceFile string    `json:"-" gorm:"size:255;default:'';comment:导入的上传文件路径"`
+	Error      string    `json:"error" gorm:"size:500;default:'';comment:任务失败原因"`
+	StartedAt  *GormTime `json:"started_at" gorm:"comment:开始时间"`
+	FinishedAt *GormTime `json:"finished_at" gorm:"comment:结束时间"`
+	CreatedAt  GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间;index:idx_data_job_created_at"`
+	UpdatedAt  GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
+}
+
+// TableName 映射物理表
+func (DataJob) TableName() string { return "ay_data_jobs" }
+
+// DataJobListSchema 导入导出任务列表可过滤、排序与输出的字段
+var DataJobListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"kind":        {Column: "kind", Ops: query.Exact},
+		"resource":    {Column: "resource", Ops: query.Exact},
+		"format":      {Column: "format", Ops: query.Exact},
+		"status":      {Column: "status", Kind: query.Int, Ops: query.Exact},
+		"created_by":  {Column: "created_by", Kind: query.Int, Ops: query.Exact},
+		"created_at":  {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"tenant_id":   {},
+		"params":      {},
+		"total":       {},
+		"processed":   {},
+		"failed":      {},
+		"file_name":   {},
+		"error":       {},
+		"started_at":  {},
+		"finished_at": {},
+		"updated_at":  {},
+		// download_url 由控制器按结果文件生成
+		"download_url": {},
+	},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "id", Desc: true}},
+}
+
+// CreateDataJob 创建任务
+func CreateDataJob(ctx context.Context, job *DataJob) error {
+	return db.WithContext(ctx).Create(job).Error
+}
+
+// GetDataJobForTenant 获取本租户的任务
+func GetDataJobForTenant(ctx context.Context, id, tenantID uint) (*DataJob, error) {
+	var job DataJob
+	if err := WithTenant(db.WithContext(ctx), tenantID).Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetDataJobByFile 按结果文件名查找本租户的任务
+func GetDataJobByFile(ctx context.Context, tenantID uint, name string) (*DataJob, error) {
+	var job DataJob
+	if err := WithTenant(db.WithContext(ctx), tenantID).Where("file_name = ?", name).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListDataJobs 按租户查询任务
+func ListDataJobs(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[DataJob], error) {
+	return query.Find[DataJob](WithTenant(db.WithContext(ctx).Model(&DataJob{}), tenantID), spec)
+}
+
+// UpdateDataJob 更新任务进度与状态
+func UpdateDataJob(ctx context.Context, id uint, columns map[string]interface{}) error {
+	return db.WithContext(ctx).Model(&DataJob{}).Where("id = ?", id).Updates(columns).Error
+}
+
+// CountActiveDataJobs 租户排队中与执行中的任务数
+func CountActiveDataJobs(ctx context.Context, tenantID uint) (int64, error) {
+	var n int64
+	err := WithTenant(db.WithContext(ctx).Model(&DataJob{}), tenantID).
+		Where("status IN ?", []int{DataJobPending, DataJobRunning}).Count(&n).Error
+	return n, err
+}
+
+// FailActiveDataJobs 将全部租户排队中与执行中的任务标记为失败，返回被标记的任务
+func FailActiveDataJobs(ctx context.Context, reason string) ([]DataJob, error) {
+	var jobs []DataJob
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status IN ?", []int{DataJobPending, DataJobRunning}).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		return tx.Model(&DataJob{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":      DataJobFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		}).Error
+	})
+	return jobs, err
+}
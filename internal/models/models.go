@@ -140,36 +140,51 @@ func (gd *GormDate) UnmarshalJSON(data []byte) error {
 // Setup 初始化数据库连接
 func Setup() {
 	var err error
+	db, err = Open(setting.DatabaseSetting, setting.ServerSetting.RunMode)
+	if err != nil {
+		global.Logger.Fatalf("models.Setup err: %v", err)
+	}
+}
+
+// Open 按配置建立数据库连接并设置连接池参数
+func Open(cfg *setting.Database, runMode string) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		setting.DatabaseSetting.User,
-		setting.DatabaseSetting.Password,
-		setting.DatabaseSetting.Host,
-		setting.DatabaseSetting.Name)
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Name)
 
 	// 配置GORM日志级别
 	var logLevel logger.LogLevel
-	if setting.ServerSetting.RunMode == "debug" {
+	if runMode == "debug" {
 		logLevel = logger.Info
 	} else {
 		logLevel = logger.Error
 	}
 
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	conn, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
+		// 将驱动错误翻译为 gorm.ErrDuplicatedKey 等通用错误，便于仓储层映射业务码
+		TranslateError: true,
 	})
-
 	if err != nil {
-		global.Logger.Fatalf("models.Setup err: %v", err)
+		return nil, err
 	}
 
-	sqlDB, err := db.DB()
+	sqlDB, err := conn.DB()
 	if err != nil {
-		global.Logger.Fatalf("db.DB() err: %v", err)
+		return nil, fmt.Errorf("db.DB(): %w", err)
 	}
 
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
+	return conn, nil
+}
+
+// SetDb 设置包级数据库连接（由 bootstrap 或测试注入）
+func SetDb(conn *gorm.DB) {
+	db = conn
 }
 
 // GetDb 获取数据库连接
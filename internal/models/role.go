@@ -1,11 +1,17 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"justus/internal/global"
+	"justus/pkg/query"
 
 	"gorm.io/gorm"
 )
 
+// ErrRoleInUse 角色仍绑定管理员，不能删除
+var ErrRoleInUse = errors.New("role is still assigned to admin users")
+
 // Role 角色模型
 type Role struct {
 	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement;comment:角色ID，主键"`
@@ -18,9 +24,10 @@ type Role struct {
 	IsSystem    bool      `json:"is_system" gorm:"default:false;comment:是否系统角色：true-系统内置不可删除，false-普通角色"`
 	SortOrder   int       `json:"sort_order" gorm:"default:0;comment:排序字段，数字越小越靠前;index:idx_sort_order"`
 	CreatedBy   uint      `json:"created_by" gorm:"default:0;comment:创建者ID"`
+	Version     uint      `json:"version" gorm:"not null;default:1;comment:乐观锁版本号"`
 	CreatedAt   GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
 	UpdatedAt   GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt   *GormTime `json:"deleted_at" gorm:"index;comment:软删除时间"`
+	DeletedAt   DeletedAt `json:"deleted_at" gorm:"index;comment:软删除时间"`
 }
 
 // Permission 权限模型
@@ -43,7 +50,7 @@ type Permission struct {
 	IsSystem    bool      `json:"is_system" gorm:"default:false;comment:是否系统权限：true-系统内置不可删除，false-普通权限"`
 	CreatedAt   GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
 	UpdatedAt   GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt   *GormTime `json:"deleted_at" gorm:"index;comment:软删除时间"`
+	DeletedAt   DeletedAt `json:"deleted_at" gorm:"index;comment:软删除时间"`
 }
 
 // RolePermission 角色权限关联模型
@@ -73,6 +80,7 @@ type AdminUserRole struct {
 	AdminUserID uint      `json:"admin_user_id" gorm:"not null;comment:管理员ID，外键关联ay_admin_users.id;index:idx_admin_user_id;uniqueIndex:uk_admin_role,priority:1"`
 	RoleID      uint      `json:"role_id" gorm:"not null;comment:角色ID，外键关联ay_roles.id;index:idx_role_id;uniqueIndex:uk_admin_role,priority:2"`
 	TenantID    uint      `json:"tenant_id" gorm:"not null;default:0;comment:租户ID;index:idx_admin_role_tenant;uniqueIndex:uk_admin_role,priority:3"`
+	Status      int       `json:"status" gorm:"not null;default:1;comment:租户内成员状态：1-正常，0-禁用，2-锁定，同一管理员在同一租户的各行保持一致"`
 	AssignedBy  uint      `json:"assigned_by" gorm:"default:0;comment:分配者ID，记录是谁给这个用户分配的角色;index:idx_assigned_by"`
 	ExpiresAt   *GormTime `json:"expires_at" gorm:"comment:角色过期时间，NULL表示永不过期;index:idx_expires_at"`
 	CreatedAt   GormTime  `json:"created_at" gorm:"autoCreateTime;comment:分配时间"`
@@ -89,9 +97,9 @@ func (RolePermission) TableName() string { return "ay_role_permissions" }
 func (AdminUserRole) TableName() string  { return "ay_admin_user_roles" }
 
 // GetRoleInfo 获取角色信息
-func (r *Role) GetRoleInfo() (*Role, error) {
+func (r *Role) GetRoleInfo(ctx context.Context) (*Role, error) {
 	var role Role
-	err := db.Where("id = ?", r.ID).First(&role).Error
+	err := db.WithContext(ctx).Where("id = ?", r.ID).First(&role).Error
 	if err != nil {
 		global.Logger.Errorf("GetRoleInfo error: %v", err)
 		return nil, err
@@ -100,9 +108,9 @@ func (r *Role) GetRoleInfo() (*Role, error) {
 }
 
 // GetRoleByName 根据名称获取角色
-func (r *Role) GetRoleByName() (*Role, error) {
+func (r *Role) GetRoleByName(ctx context.Context) (*Role, error) {
 	var role Role
-	err := db.Where("name = ?", r.Name).First(&role).Error
+	err := db.WithContext(ctx).Where("name = ?", r.Name).First(&role).Error
 	if err != nil {
 		global.Logger.Errorf("GetRoleByName error: %v", err)
 		return nil, err
@@ -111,9 +119,9 @@ func (r *Role) GetRoleByName() (*Role, error) {
 }
 
 // GetAllRoles 获取所有角色
-func (r *Role) GetAllRoles() ([]*Role, error) {
+func (r *Role) GetAllRoles(ctx context.Context) ([]*Role, error) {
 	var roles []*Role
-	err := db.Where("status = ?", 1).Find(&roles).Error
+	err := db.WithContext(ctx).Where("status = ?", 1).Find(&roles).Error
 	if err != nil {
 		global.Logger.Errorf("GetAllRoles error: %v", err)
 		return nil, err
@@ -124,9 +132,9 @@ func (r *Role) GetAllRoles() ([]*Role, error) {
 // 已废弃的方法移除：GetUserRoles
 
 // GetRolePermissions 获取角色的权限列表
-func GetRolePermissions(roleID int) ([]*Permission, error) {
+func GetRolePermissions(ctx context.Context, roleID int) ([]*Permission, error) {
 	var permissions []*Permission
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Select("p.*").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Where("rp.role_id = ?", roleID).
@@ -142,9 +150,9 @@ func GetRolePermissions(roleID int) ([]*Permission, error) {
 // 已废弃的方法移除：GetUserPermissions
 
 // GetAdminUserPermissions 获取管理员用户的所有权限
-func GetAdminUserPermissions(adminUserID int) ([]*Permission, error) {
+func GetAdminUserPermissions(ctx context.Context, adminUserID int) ([]*Permission, error) {
 	var permissions []*Permission
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Select("DISTINCT p.*").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
@@ -159,9 +167,9 @@ func GetAdminUserPermissions(adminUserID int) ([]*Permission, error) {
 }
 
 // GetAdminUserPermissionIDs 获取管理员用户的所有权限ID（便于快速求交集与构建菜单）
-func GetAdminUserPermissionIDs(adminUserID int) ([]uint, error) {
+func GetAdminUserPermissionIDs(ctx context.Context, adminUserID int) ([]uint, error) {
 	var ids []uint
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Select("DISTINCT p.id").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
@@ -175,13 +183,13 @@ func GetAdminUserPermissionIDs(adminUserID int) ([]uint, error) {
 }
 
 // GetAdminUserPermissionIDsInTenant 获取管理员在指定租户的权限ID集合
-func GetAdminUserPermissionIDsInTenant(adminUserID int, tenantID uint) ([]uint, error) {
+func GetAdminUserPermissionIDsInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]uint, error) {
 	var ids []uint
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Select("DISTINCT p.id").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
-		Where("aur.admin_user_id = ? AND aur.tenant_id = ?", adminUserID, tenantID).
+		Where("aur.admin_user_id = ? AND aur.tenant_id = ? AND aur.status = 1", adminUserID, tenantID).
 		Pluck("p.id", &ids).Error
 	if err != nil {
 		global.Logger.Errorf("GetAdminUserPermissionIDsInTenant error: %v", err)
@@ -191,13 +199,13 @@ func GetAdminUserPermissionIDsInTenant(adminUserID int, tenantID uint) ([]uint,
 }
 
 // GetAdminUserPermissionNamesInTenant 获取管理员在指定租户的权限名称集合
-func GetAdminUserPermissionNamesInTenant(adminUserID int, tenantID uint) ([]string, error) {
+func GetAdminUserPermissionNamesInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]string, error) {
 	var names []string
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Select("DISTINCT p.name").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
-		Where("aur.admin_user_id = ? AND aur.tenant_id = ?", adminUserID, tenantID).
+		Where("aur.admin_user_id = ? AND aur.tenant_id = ? AND aur.status = 1", adminUserID, tenantID).
 		Pluck("p.name", &names).Error
 	if err != nil {
 		global.Logger.Errorf("GetAdminUserPermissionNamesInTenant error: %v", err)
@@ -207,12 +215,12 @@ func GetAdminUserPermissionNamesInTenant(adminUserID int, tenantID uint) ([]stri
 }
 
 // GetRolesByIDsAndTenant 获取指定租户可用的角色（包含系统级角色 tenant_id=0）
-func GetRolesByIDsAndTenant(roleIDs []int, tenantID uint) ([]Role, error) {
+func GetRolesByIDsAndTenant(ctx context.Context, roleIDs []int, tenantID uint) ([]Role, error) {
 	if len(roleIDs) == 0 {
 		return []Role{}, nil
 	}
 	var roles []Role
-	if err := db.Table("ay_roles").
+	if err := db.WithContext(ctx).Table("ay_roles").
 		Where("id IN ?", roleIDs).
 		Where("status = 1").
 		Where("tenant_id IN ?", []uint{0, tenantID}).
@@ -223,9 +231,13 @@ func GetRolesByIDsAndTenant(roleIDs []int, tenantID uint) ([]Role, error) {
 	return roles, nil
 }
 
-// AssignRolesToAdminInTenant 在指定租户下为管理员设置角色（覆盖式）
-func AssignRolesToAdminInTenant(adminUserID uint, tenantID uint, roleIDs []uint) error {
-	return db.Transaction(func(tx *gorm.DB) error {
+// AssignRolesToAdminInTenant 在指定租户下为管理员设置角色（覆盖式），保留原有的成员状态
+func AssignRolesToAdminInTenant(ctx context.Context, adminUserID uint, tenantID uint, roleIDs []uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		status, err := memberStatus(tx, adminUserID, tenantID)
+		if err != nil {
+			return err
+		}
 		if err := tx.Table("ay_admin_user_roles").
 			Where("admin_user_id = ? AND tenant_id = ?", adminUserID, tenantID).
 			Delete(&AdminUserRole{}).Error; err != nil {
@@ -245,59 +257,85 @@ func AssignRolesToAdminInTenant(adminUserID uint, tenantID uint, roleIDs []uint)
 		if err := tx.Table("ay_admin_user_roles").Create(&rows).Error; err != nil {
 			return err
 		}
-		return nil
+		return setMemberStatus(tx, adminUserID, tenantID, status)
 	})
 }
 
-// ListTenantRoles 按租户分页查询角色（仅本租户角色，不含系统级）
-func ListTenantRoles(tenantID uint, keyword string, status string, page, limit int) ([]Role, int64, error) {
-	var (
-		roles []Role
-		total int64
-	)
-	query := db.Model(&Role{}).Where("tenant_id = ?", tenantID)
-	if keyword != "" {
-		like := "%" + keyword + "%"
-		query = query.Where("name LIKE ? OR display_name LIKE ?", like, like)
+// memberStatus 管理员在租户内的成员状态，尚未绑定角色时视为正常
+func memberStatus(tx *gorm.DB, adminUserID, tenantID uint) (int, error) {
+	var statuses []int
+	if err := tx.Model(&AdminUserRole{}).
+		Where("admin_user_id = ? AND tenant_id = ?", adminUserID, tenantID).
+		Limit(1).Pluck("status", &statuses).Error; err != nil {
+		return 0, err
 	}
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if len(statuses) == 0 {
+		return 1, nil
 	}
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Order("sort_order ASC, id DESC").Find(&roles).Error; err != nil {
-		return nil, 0, err
+	return statuses[0], nil
+}
+
+// setMemberStatus 新建的绑定行以默认值 1 写入，成员被禁用时需要回写原状态
+func setMemberStatus(tx *gorm.DB, adminUserID, tenantID uint, status int) error {
+	if status == 1 {
+		return nil
 	}
-	return roles, total, nil
+	return tx.Model(&AdminUserRole{}).
+		Where("admin_user_id = ? AND tenant_id = ?", adminUserID, tenantID).
+		Update("status", status).Error
+}
+
+// RoleListSchema 角色列表可过滤、排序与输出的字段
+var RoleListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":           {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"name":         {Column: "name", Ops: query.Text, Sortable: true},
+		"display_name": {Column: "display_name", Ops: query.Text, Sortable: true},
+		"description":  {Column: "description"},
+		"level":        {Column: "level", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"status":       {Column: "status", Kind: query.Int, Ops: query.Exact},
+		"is_system":    {Column: "is_system", Kind: query.Bool, Ops: []query.Op{query.Eq}},
+		"sort_order":   {Column: "sort_order", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"created_at":   {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"updated_at":   {Column: "updated_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"tenant_id":    {},
+		"created_by":   {},
+	},
+	Search:      []string{"name", "display_name"},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "sort_order"}, {Field: "id", Desc: true}},
+}
+
+// ListTenantRoles 按租户查询角色（仅本租户角色，不含系统级）
+func ListTenantRoles(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[Role], error) {
+	return query.Find[Role](WithTenant(db.WithContext(ctx).Model(&Role{}), tenantID), spec)
 }
 
 // GetRoleByIDForTenant 获取本租户的角色（不包含系统级）
-func GetRoleByIDForTenant(roleID uint, tenantID uint) (*Role, error) {
+func GetRoleByIDForTenant(ctx context.Context, roleID uint, tenantID uint) (*Role, error) {
 	var role Role
-	if err := db.Where("id = ? AND tenant_id = ?", roleID, tenantID).First(&role).Error; err != nil {
+	if err := db.WithContext(ctx).Where("id = ? AND tenant_id = ?", roleID, tenantID).First(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
 // GetPermissionIDsOfRole 获取角色绑定的权限ID集合
-func GetPermissionIDsOfRole(roleID uint) ([]uint, error) {
+func GetPermissionIDsOfRole(ctx context.Context, roleID uint) ([]uint, error) {
 	var ids []uint
-	if err := db.Table("ay_role_permissions").Where("role_id = ?", roleID).Pluck("permission_id", &ids).Error; err != nil {
+	if err := db.WithContext(ctx).Table("ay_role_permissions").Where("role_id = ?", roleID).Pluck("permission_id", &ids).Error; err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
 // GetPermissionsByNames 批量根据名称获取权限
-func GetPermissionsByNames(names []string) ([]Permission, error) {
+func GetPermissionsByNames(ctx context.Context, names []string) ([]Permission, error) {
 	var list []Permission
 	if len(names) == 0 {
 		return list, nil
 	}
-	if err := db.Table("ay_permissions").Where("name IN ?", names).Find(&list).Error; err != nil {
+	if err := db.WithContext(ctx).Table("ay_permissions").Where("name IN ?", names).Find(&list).Error; err != nil {
 		global.Logger.Errorf("GetPermissionsByNames error: %v", err)
 		return nil, err
 	}
@@ -305,7 +343,7 @@ func GetPermissionsByNames(names []string) ([]Permission, error) {
 }
 
 // CreateRoleForTenant 在指定租户创建角色
-func CreateRoleForTenant(tenantID uint, name, displayName, description string, status int) (*Role, error) {
+func CreateRoleForTenant(ctx context.Context, tenantID uint, name, displayName, description string, status int) (*Role, error) {
 	role := &Role{
 		TenantID:    tenantID,
 		Name:        name,
@@ -313,45 +351,68 @@ func CreateRoleForTenant(tenantID uint, name, displayName, description string, s
 		Description: description,
 		Status:      status,
 	}
-	if err := db.Create(role).Error; err != nil {
+	if err := db.WithContext(ctx).Create(role).Error; err != nil {
 		return nil, err
 	}
 	return role, nil
 }
 
-// UpdateRoleForTenant 更新本租户的角色（不允许编辑系统级角色）
-func UpdateRoleForTenant(roleID uint, tenantID uint, displayName, description string, status int) error {
-	// 只允许更新本租户角色
-	return db.Model(&Role{}).
-		Where("id = ? AND tenant_id = ?", roleID, tenantID).
-		Updates(map[string]interface{}{
-			"display_name": displayName,
-			"description":  description,
-			"status":       status,
-		}).Error
+// RolePatch 角色部分更新，nil 字段保持不变
+type RolePatch struct {
+	DisplayName *string
+	Description *string
+	Status      *int
 }
 
-// DeleteRoleForTenant 删除本租户角色（需无绑定）
-func DeleteRoleForTenant(roleID uint, tenantID uint) error {
-	// 检查绑定
-	var cnt int64
-	if err := db.Table("ay_admin_user_roles").Where("role_id = ? AND tenant_id = ?", roleID, tenantID).Count(&cnt).Error; err != nil {
-		return err
+// UpdateRoleForTenant 更新本租户的角色（乐观锁，不允许编辑系统级角色）
+// 角色不存在或属于其他租户时返回 gorm.ErrRecordNotFound，version 为 0 时不校验版本
+func UpdateRoleForTenant(ctx context.Context, roleID uint, tenantID uint, version uint, patch RolePatch) (*Role, error) {
+	cols := make(map[string]interface{})
+	setColumn(cols, "display_name", patch.DisplayName)
+	setColumn(cols, "description", patch.Description)
+	setColumn(cols, "status", patch.Status)
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		return q.Where("id = ? AND tenant_id = ?", roleID, tenantID)
 	}
-	if cnt > 0 {
-		return gorm.ErrInvalidData
+	if err := updateVersioned(db.WithContext(ctx), &Role{}, scope, version, cols); err != nil {
+		return nil, err
 	}
-	// 删除关联权限
-	if err := db.Table("ay_role_permissions").Where("role_id = ?", roleID).Delete(&RolePermission{}).Error; err != nil {
+	return GetRoleByIDForTenant(ctx, roleID, tenantID)
+}
+
+// DeleteRoleForTenant 删除本租户角色（需无绑定，软删除进入回收站）
+func DeleteRoleForTenant(ctx context.Context, roleID uint, tenantID uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 校验归属，避免跨租户清空他人角色的权限
+		var role Role
+		if err := tx.Where("id = ? AND tenant_id = ?", roleID, tenantID).First(&role).Error; err != nil {
+			return err
+		}
+		// 检查绑定
+		var cnt int64
+		if err := tx.Table("ay_admin_user_roles").Where("role_id = ? AND tenant_id = ?", roleID, tenantID).Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return ErrRoleInUse
+		}
+		// 软删除角色；权限关联保留到永久删除，便于从回收站恢复
+		return tx.Delete(&role).Error
+	})
+}
+
+// purgeRelations 永久删除角色前清理权限与管理员绑定
+func (*Role) purgeRelations(tx *gorm.DB, ids []uint) error {
+	if err := tx.Where("role_id IN ?", ids).Delete(&RolePermission{}).Error; err != nil {
 		return err
 	}
-	// 删除角色
-	return db.Where("id = ? AND tenant_id = ?", roleID, tenantID).Delete(&Role{}).Error
+	return tx.Where("role_id IN ?", ids).Delete(&AdminUserRole{}).Error
 }
 
 // ReplaceRolePermissions 覆盖式替换角色的权限集合
-func ReplaceRolePermissions(roleID uint, permissionIDs []uint) error {
-	return db.Transaction(func(tx *gorm.DB) error {
+func ReplaceRolePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Table("ay_role_permissions").Where("role_id = ?", roleID).Delete(&RolePermission{}).Error; err != nil {
 			return err
 		}
@@ -369,10 +430,45 @@ func ReplaceRolePermissions(roleID uint, permissionIDs []uint) error {
 	})
 }
 
+// ListPermissions 获取全部权限（按模块、排序字段排序）
+func ListPermissions(ctx context.Context) ([]Permission, error) {
+	var list []Permission
+	if err := db.WithContext(ctx).Order("module ASC, sort_order ASC, id ASC").Find(&list).Error; err != nil {
+		global.Logger.Errorf("ListPermissions error: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreatePermission 创建权限，name 唯一
+func CreatePermission(ctx context.Context, p *Permission) error {
+	if err := db.WithContext(ctx).Create(p).Error; err != nil {
+		global.Logger.Errorf("CreatePermission error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetMenuPermissionsByIDs 获取指定ID中的菜单权限（按排序字段排序）
+func GetMenuPermissionsByIDs(ctx context.Context, ids []uint) ([]Permission, error) {
+	var list []Permission
+	if len(ids) == 0 {
+		return list, nil
+	}
+	if err := db.WithContext(ctx).Where("id IN ?", ids).
+		Where("is_menu = ?", true).
+		Order("sort_order ASC, id ASC").
+		Find(&list).Error; err != nil {
+		global.Logger.Errorf("GetMenuPermissionsByIDs error: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
 // GetPermissionByName 根据权限名获取权限
-func GetPermissionByName(name string) (*Permission, error) {
+func GetPermissionByName(ctx context.Context, name string) (*Permission, error) {
 	var p Permission
-	if err := db.Where("name = ?", name).First(&p).Error; err != nil {
+	if err := db.WithContext(ctx).Where("name = ?", name).First(&p).Error; err != nil {
 		global.Logger.Errorf("GetPermissionByName error: %v", err)
 		return nil, err
 	}
@@ -381,16 +477,16 @@ func GetPermissionByName(name string) (*Permission, error) {
 
 // HasAdminPermissionInTenant 基于租户白名单的权限校验
 // 语义：管理员拥有该权限（基于角色） 且 租户白名单允许该权限
-func HasAdminPermissionInTenant(adminUserID int, permissionName string, tenantID uint) (bool, error) {
+func HasAdminPermissionInTenant(ctx context.Context, adminUserID int, permissionName string, tenantID uint) (bool, error) {
 	// 先查权限ID
-	perm, err := GetPermissionByName(permissionName)
+	perm, err := GetPermissionByName(ctx, permissionName)
 	if err != nil {
 		return false, err
 	}
 
 	// 租户白名单必须允许
 	var whiteCount int64
-	if err := db.Table("ay_tenant_permissions").
+	if err := db.WithContext(ctx).Table("ay_tenant_permissions").
 		Where("tenant_id = ? AND permission_id = ?", tenantID, perm.ID).
 		Count(&whiteCount).Error; err != nil {
 		global.Logger.Errorf("HasAdminPermissionInTenant whitelist check error: %v", err)
@@ -402,10 +498,10 @@ func HasAdminPermissionInTenant(adminUserID int, permissionName string, tenantID
 
 	// 用户是否在该租户拥有该权限（通过租户内角色）
 	var count int64
-	if err := db.Table("ay_permissions p").
+	if err := db.WithContext(ctx).Table("ay_permissions p").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
-		Where("aur.admin_user_id = ? AND aur.tenant_id = ? AND p.name = ?", adminUserID, tenantID, permissionName).
+		Where("aur.admin_user_id = ? AND aur.tenant_id = ? AND aur.status = 1 AND p.name = ?", adminUserID, tenantID, permissionName).
 		Count(&count).Error; err != nil {
 		global.Logger.Errorf("HasAdminPermissionInTenant role check error: %v", err)
 		return false, err
@@ -416,9 +512,9 @@ func HasAdminPermissionInTenant(adminUserID int, permissionName string, tenantID
 // 已废弃的方法移除：HasPermission
 
 // HasAdminPermission 检查管理员用户是否有指定权限
-func HasAdminPermission(adminUserID int, permissionName string) (bool, error) {
+func HasAdminPermission(ctx context.Context, adminUserID int, permissionName string) (bool, error) {
 	var count int64
-	err := db.Table("ay_permissions p").
+	err := db.WithContext(ctx).Table("ay_permissions p").
 		Joins("JOIN ay_role_permissions rp ON p.id = rp.permission_id").
 		Joins("JOIN ay_admin_user_roles aur ON rp.role_id = aur.role_id").
 		Where("aur.admin_user_id = ? AND p.name = ?", adminUserID, permissionName).
@@ -433,11 +529,28 @@ func HasAdminPermission(adminUserID int, permissionName string) (bool, error) {
 
 // 已废弃的方法移除：IsAdmin
 
+// IsAdminUserInTenant 检查管理员在租户内是否拥有 admin 角色且成员状态正常；账号或租户在回收站中时视为无权限
+func IsAdminUserInTenant(ctx context.Context, adminUserID int, tenantID uint) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Table("ay_roles r").
+		Joins("JOIN ay_admin_user_roles aur ON r.id = aur.role_id").
+		Joins("JOIN ay_admin_users au ON au.id = aur.admin_user_id AND au.deleted_at IS NULL").
+		Joins("JOIN ay_tenants t ON t.id = aur.tenant_id AND t.deleted_at IS NULL").
+		Where("aur.admin_user_id = ? AND aur.tenant_id = ? AND aur.status = 1 AND r.name = 'admin' AND r.status = 1", adminUserID, tenantID).
+		Count(&count).Error
+	if err != nil {
+		global.Logger.Errorf("IsAdminUserInTenant error: %v", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // IsAdminUser 检查是否为管理员用户
-func IsAdminUser(adminUserID int) (bool, error) {
+func IsAdminUser(ctx context.Context, adminUserID int) (bool, error) {
 	var count int64
-	err := db.Table("ay_roles r").
+	err := db.WithContext(ctx).Table("ay_roles r").
 		Joins("JOIN ay_admin_user_roles aur ON r.id = aur.role_id").
+		Joins("JOIN ay_admin_users au ON au.id = aur.admin_user_id AND au.deleted_at IS NULL").
 		Where("aur.admin_user_id = ? AND r.name = 'admin' AND r.status = 1", adminUserID).
 		Count(&count).Error
 
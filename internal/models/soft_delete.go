@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"justus/pkg/query"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DeletedAt 软删除时间，序列化格式与 GormTime 一致
+// GORM 只对实现了 Query/Update/DeleteClauses 的字段类型启用软删除（原 *GormTime 会被物理删除），
+// 这里复用 gorm.DeletedAt 的子句：查询自动追加 deleted_at IS NULL，Delete 改为写入删除时间，
+// 需要访问已删除记录时使用 Unscoped()
+type DeletedAt GormTime
+
+// Scan 实现sql.Scanner接口
+func (d *DeletedAt) Scan(value interface{}) error {
+	return (*GormTime)(d).Scan(value)
+}
+
+// Value 实现driver.Valuer接口，零值写入 NULL
+func (d DeletedAt) Value() (driver.Value, error) {
+	return GormTime(d).Value()
+}
+
+// MarshalJSON 实现JSON序列化，未删除时输出 null
+func (d DeletedAt) MarshalJSON() ([]byte, error) {
+	return GormTime(d).MarshalJSON()
+}
+
+// UnmarshalJSON 实现JSON反序列化
+func (d *DeletedAt) UnmarshalJSON(data []byte) error {
+	return (*GormTime)(d).UnmarshalJSON(data)
+}
+
+// Deleted 是否已软删除
+func (d DeletedAt) Deleted() bool {
+	return !d.Time.IsZero()
+}
+
+// QueryClauses 查询时过滤已删除记录
+func (DeletedAt) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{gorm.SoftDeleteQueryClause{Field: f, ZeroValue: sql.NullString{}}}
+}
+
+// UpdateClauses 更新时同样只作用于未删除记录
+func (DeletedAt) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{gorm.SoftDeleteUpdateClause{Field: f, ZeroValue: sql.NullString{}}}
+}
+
+// DeleteClauses Delete 改写为 UPDATE ... SET deleted_at = 当前时间
+func (DeletedAt) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{gorm.SoftDeleteDeleteClause{Field: f, ZeroValue: sql.NullString{}}}
+}
+
+// ErrRestoreOrphan 记录依赖的数据已被永久删除，恢复后无法使用
+var ErrRestoreOrphan = errors.New("models: trashed record depends on purged data")
+
+// purgeable 永久删除前需要清理关联数据的模型
+type purgeable interface {
+	purgeRelations(tx *gorm.DB, ids []uint) error
+}
+
+// restorable 恢复前需要校验关联数据仍然可用的模型
+type restorable interface {
+	checkRestore(tx *gorm.DB, id uint) error
+}
+
+// TrashSchema 回收站列表的查询白名单：在模型列表字段基础上增加 deleted_at，默认按删除时间倒序
+func TrashSchema(base *query.Schema) *query.Schema {
+	fields := make(map[string]query.Field, len(base.Fields)+1)
+	for name, f := range base.Fields {
+		fields[name] = f
+	}
+	fields["deleted_at"] = query.Field{Column: "deleted_at", Kind: query.Time, Ops: query.Range, Sortable: true}
+	return &query.Schema{
+		Fields:       fields,
+		Search:       base.Search,
+		Key:          base.Key,
+		DefaultSort:  []query.Sort{{Field: "deleted_at", Desc: true}},
+		DefaultLimit: base.DefaultLimit,
+		MaxLimit:     base.MaxLimit,
+	}
+}
+
+// ListTrashed 查询已软删除的记录；scope 用于租户隔离等业务条件
+func ListTrashed[T any](ctx context.Context, scope func(*gorm.DB) *gorm.DB, spec *query.Spec) (*query.Page[*T], error) {
+	base := db.WithContext(ctx).Unscoped().Model(new(T)).Where("deleted_at IS NOT NULL")
+	if scope != nil {
+		base = scope(base)
+	}
+	return query.Find[*T](base, spec)
+}
+
+// RestoreTrashed 恢复已软删除的记录，记录不存在或未删除时返回 gorm.ErrRecordNotFound
+// 唯一键已被新记录占用时返回 gorm.ErrDuplicatedKey，关联数据已被永久删除时返回 ErrRestoreOrphan
+func RestoreTrashed[T any](ctx context.Context, id uint, scope func(*gorm.DB) *gorm.DB) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Unscoped().Model(new(T)).Where("id = ? AND deleted_at IS NOT NULL", id)
+		if scope != nil {
+			q = scope(q)
+		}
+		result := q.Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if r, ok := any(new(T)).(restorable); ok {
+			return r.checkRestore(tx, id)
+		}
+		return nil
+	})
+}
+
+// PurgeTrashed 永久删除一条已软删除的记录及其关联数据
+func PurgeTrashed[T any](ctx context.Context, id uint, scope func(*gorm.DB) *gorm.DB) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Unscoped().Model(new(T)).Where("id = ? AND deleted_at IS NOT NULL", id)
+		if scope != nil {
+			q = scope(q)
+		}
+		var ids []uint
+		if err := q.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return purge[T](tx, ids)
+	})
+}
+
+// PurgeTrashedBefore 永久删除在 cutoff 之前软删除的记录，返回删除条数
+func PurgeTrashedBefore[T any](ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(new(T)).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		n = int64(len(ids))
+		return purge[T](tx, ids)
+	})
+	return n, err
+}
+
+// purge 先清理关联数据，再物理删除
+func purge[T any](tx *gorm.DB, ids []uint) error {
+	if p, ok := any(new(T)).(purgeable); ok {
+		if err := p.purgeRelations(tx, ids); err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(new(T)).Error
+}
+
+// 回收站资源名，对应 /admin/v1/trash/:resource
+const (
+	TrashUsers      = "users"
+	TrashAdminUsers = "admin-users"
+	TrashRoles      = "roles"
+	TrashTenants    = "tenants"
+)
+
+// TrashSchemas 各回收站资源的列表查询白名单
+var TrashSchemas = map[string]*query.Schema{
+	TrashUsers:      TrashSchema(UserListSchema),
+	TrashAdminUsers: TrashSchema(AdminUserListSchema),
+	TrashRoles:      TrashSchema(RoleListSchema),
+	TrashTenants:    TrashSchema(TenantListSchema),
+}
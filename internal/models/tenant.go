@@ -1,5 +1,13 @@
 package models
 
+import (
+	"context"
+
+	"justus/pkg/query"
+
+	"gorm.io/gorm"
+)
+
 // Tenant 租户模型（共享表模式）
 type Tenant struct {
 	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement;comment:租户ID，主键"`
@@ -8,9 +16,10 @@ type Tenant struct {
 	Status      int       `json:"status" gorm:"default:1;comment:状态：1-启用，0-禁用;index:idx_tenant_status"`
 	Plan        string    `json:"plan" gorm:"size:50;default:'';comment:套餐/版本"`
 	OwnerUserID uint      `json:"owner_user_id" gorm:"default:0;comment:拥有者管理员ID"`
+	Version     uint      `json:"version" gorm:"not null;default:1;comment:乐观锁版本号"`
 	CreatedAt   GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
 	UpdatedAt   GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt   *GormTime `json:"deleted_at" gorm:"index;comment:软删除时间"`
+	DeletedAt   DeletedAt `json:"deleted_at" gorm:"index;comment:软删除时间"`
 }
 
 // TenantPermission 租户权限白名单
@@ -25,10 +34,28 @@ type TenantPermission struct {
 func (Tenant) TableName() string           { return "ay_tenants" }
 func (TenantPermission) TableName() string { return "ay_tenant_permissions" }
 
+// TenantListSchema 租户列表可过滤、排序与输出的字段
+var TenantListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":            {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"code":          {Column: "code", Ops: query.Text, Sortable: true},
+		"name":          {Column: "name", Ops: query.Text, Sortable: true},
+		"status":        {Column: "status", Kind: query.Int, Ops: query.Exact},
+		"plan":          {Column: "plan", Ops: query.Exact},
+		"owner_user_id": {Column: "owner_user_id", Kind: query.Int, Ops: query.Exact},
+		"created_at":    {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"updated_at":    {Column: "updated_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"version":       {},
+	},
+	Search:      []string{"code", "name"},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "id", Desc: true}},
+}
+
 // GetTenantPermissionIDs 获取租户的白名单权限ID集合
-func GetTenantPermissionIDs(tenantID uint) ([]uint, error) {
+func GetTenantPermissionIDs(ctx context.Context, tenantID uint) ([]uint, error) {
 	var ids []uint
-	err := db.Model(&TenantPermission{}).
+	err := db.WithContext(ctx).Model(&TenantPermission{}).
 		Where("tenant_id = ?", tenantID).
 		Pluck("permission_id", &ids).Error
 	if err != nil {
@@ -38,10 +65,79 @@ func GetTenantPermissionIDs(tenantID uint) ([]uint, error) {
 }
 
 // GetTenantByID 获取租户信息
-func GetTenantByID(tenantID uint) (*Tenant, error) {
+func GetTenantByID(ctx context.Context, tenantID uint) (*Tenant, error) {
 	var t Tenant
-	if err := db.Where("id = ?", tenantID).First(&t).Error; err != nil {
+	if err := db.WithContext(ctx).Where("id = ?", tenantID).First(&t).Error; err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
+
+// TenantPatch 租户部分更新，nil 字段保持不变
+type TenantPatch struct {
+	Code        *string
+	Name        *string
+	Status      *int
+	Plan        *string
+	OwnerUserID *uint
+}
+
+// UpdateTenant 更新租户（乐观锁），version 为 0 时不校验版本，返回更新后的租户
+func UpdateTenant(ctx context.Context, tenantID uint, version uint, patch TenantPatch) (*Tenant, error) {
+	cols := make(map[string]interface{})
+	setColumn(cols, "code", patch.Code)
+	setColumn(cols, "name", patch.Name)
+	setColumn(cols, "status", patch.Status)
+	setColumn(cols, "plan", patch.Plan)
+	setColumn(cols, "owner_user_id", patch.OwnerUserID)
+
+	if err := updateVersioned(db.WithContext(ctx), &Tenant{}, byID(tenantID), version, cols); err != nil {
+		return nil, err
+	}
+	return GetTenantByID(ctx, tenantID)
+}
+
+// ReplaceTenantPermissions 覆盖式替换租户的白名单权限集合
+func ReplaceTenantPermissions(ctx context.Context, tenantID uint, permissionIDs []uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&TenantPermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+		rows := make([]TenantPermission, 0, len(permissionIDs))
+		for _, pid := range permissionIDs {
+			rows = append(rows, TenantPermission{TenantID: tenantID, PermissionID: pid})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// DeleteTenant 软删除租户；白名单、租户角色与管理员绑定保留到永久删除，便于从回收站恢复
+func DeleteTenant(ctx context.Context, tenantID uint) error {
+	result := db.WithContext(ctx).Delete(&Tenant{ID: tenantID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeRelations 永久删除租户前清理白名单、租户角色及其权限与管理员绑定；
+// 只属于该租户且已进入回收站的管理员账号随之无法恢复，由回收站到期清理
+func (*Tenant) purgeRelations(tx *gorm.DB, ids []uint) error {
+	if err := tx.Where("tenant_id IN ?", ids).Delete(&TenantPermission{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("tenant_id IN ?", ids).Delete(&AdminUserRole{}).Error; err != nil {
+		return err
+	}
+	roles := tx.Unscoped().Model(&Role{}).Select("id").Where("tenant_id IN ?", ids)
+	if err := tx.Where("role_id IN (?)", roles).Delete(&RolePermission{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("tenant_id IN ?", ids).Delete(&Role{}).Error
+}
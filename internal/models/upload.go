@@ -0,0 +1,111 @@
+package models
+
+import (
+	"context"
+
+	"justus/pkg/query"
+)
+
+// 上传文件分类
+const (
+	UploadAvatar = "avatar"
+	UploadImage  = "image"
+	UploadFile   = "file"
+)
+
+// Upload 租户上传的文件：存储对象按内容 MD5 命名，同租户相同内容只保存一条记录，
+// 不同租户可引用同一对象，删除时仅在无记录引用后才删除对象
+type Upload struct {
+	ID          uint     `json:"id" gorm:"primaryKey;autoIncrement;comment:文件ID，主键"`
+	TenantID    uint     `json:"tenant_id" gorm:"not null;default:0;comment:租户ID;index:idx_upload_tenant_md5,priority:1"`
+	UploadedBy  uint     `json:"uploaded_by" gorm:"not null;default:0;comment:上传者管理员ID"`
+	Category    string   `json:"category" gorm:"not null;size:20;comment:分类：avatar、image、file"`
+	Name        string   `json:"name" gorm:"size:255;default:'';comment:原始文件名"`
+	Key         string   `json:"key" gorm:"not null;size:255;comment:存储键;index:idx_upload_key"`
+	ContentType string   `json:"content_type" gorm:"size:100;default:'';comment:嗅探得到的内容类型"`
+	Size        int64    `json:"size" gorm:"not null;default:0;comment:文件大小（字节）"`
+	MD5         string   `json:"md5" gorm:"column:md5;not null;size:32;comment:内容MD5;index:idx_upload_tenant_md5,priority:2"`
+	CreatedAt   GormTime `json:"created_at" gorm:"autoCreateTime;comment:创建时间;index:idx_upload_created_at"`
+}
+
+// TableName 映射物理表
+func (Upload) TableName() string { return "ay_uploads" }
+
+// UploadListSchema 上传文件列表可过滤、排序与输出的字段
+var UploadListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":           {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"category":     {Column: "category", Ops: query.Exact},
+		"name":         {Column: "name", Ops: query.Text, Sortable: true},
+		"content_type": {Column: "content_type", Ops: query.Exact},
+		"size":         {Column: "size", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"uploaded_by":  {Column: "uploaded_by", Kind: query.Int, Ops: query.Exact},
+		"created_at":   {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"tenant_id":    {},
+		"key":          {},
+		"md5":          {},
+		// url 由控制器按存储后端生成
+		"url": {},
+	},
+	Search:      []string{"name"},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "id", Desc: true}},
+}
+
+// CreateUpload 写入上传记录
+func CreateUpload(ctx context.Context, u *Upload) error {
+	return db.WithContext(ctx).Create(u).Error
+}
+
+// GetUploadForTenant 获取本租户的上传记录
+func GetUploadForTenant(ctx context.Context, id, tenantID uint) (*Upload, error) {
+	var u Upload
+	if err := WithTenant(db.WithContext(ctx), tenantID).Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetUploadByMD5 按内容 MD5 查找本租户已有的上传记录
+func GetUploadByMD5(ctx context.Context, tenantID uint, md5 string) (*Upload, error) {
+	var u Upload
+	if err := WithTenant(db.WithContext(ctx), tenantID).Where("md5 = ?", md5).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetUploadByKey 按存储键查找本租户的上传记录
+func GetUploadByKey(ctx context.Context, tenantID uint, key string) (*Upload, error) {
+	var u Upload
+	if err := WithTenant(db.WithContext(ctx), tenantID).Where("`key` = ?", key).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ListUploads 按租户查询上传记录
+func ListUploads(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[Upload], error) {
+	return query.Find[Upload](WithTenant(db.WithContext(ctx).Model(&Upload{}), tenantID), spec)
+}
+
+// DeleteUpload 删除上传记录，返回删除后仍引用同一存储键的记录数
+func DeleteUpload(ctx context.Context, u *Upload) (int64, error) {
+	if err := db.WithContext(ctx).Delete(&Upload{}, u.ID).Error; err != nil {
+		return 0, err
+	}
+	var n int64
+	err := db.WithContext(ctx).Model(&Upload{}).Where("`key` = ?", u.Key).Count(&n).Error
+	return n, err
+}
+
+// UploadUsage 租户已用存储（字节）与文件数
+func UploadUsage(ctx context.Context, tenantID uint) (used int64, files int64, err error) {
+	var row struct {
+		Used  int64
+		Files int64
+	}
+	err = WithTenant(db.WithContext(ctx).Model(&Upload{}), tenantID).
+		Select("COALESCE(SUM(size), 0) AS used, COUNT(*) AS files").Scan(&row).Error
+	return row.Used, row.Files, err
+}
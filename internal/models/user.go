@@ -1,10 +1,12 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"justus/internal/global"
-	"justus/pkg/setting"
-	"strings"
+	"justus/pkg/query"
+	"justus/pkg/upload"
+	"time"
 )
 
 // User 普通用户模型
@@ -27,11 +29,15 @@ type User struct {
 	LastLoginAt   *GormTime `json:"last_login_at" gorm:"comment:最后登录时间;index:idx_last_login"`
 	LastLoginIP   string    `json:"last_login_ip" gorm:"size:45;default:'';comment:最后登录IP地址"`
 	LoginCount    int       `json:"login_count" gorm:"default:0;comment:登录次数统计"`
+	Version       uint      `json:"version" gorm:"not null;default:1;comment:乐观锁版本号"`
 	CreatedAt     GormTime  `json:"created_at" gorm:"autoCreateTime;comment:创建时间;index:idx_created_at"`
 	UpdatedAt     GormTime  `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt     *GormTime `json:"deleted_at" gorm:"index;comment:软删除时间"`
+	DeletedAt     DeletedAt `json:"deleted_at" gorm:"index;comment:软删除时间"`
 }
 
+// TableName 映射物理表
+func (User) TableName() string { return "ay_users" }
+
 // UserInfo 普通用户信息结构体
 type UserInfo struct {
 	ID        int    `json:"id"`
@@ -43,17 +49,16 @@ type UserInfo struct {
 	LastName  string `json:"last_name"`
 	FullName  string `json:"full_name"`
 	Lang      string `json:"lang"`
-	Status    int    `json:"status"` // 用户状态
+	Status    int    `json:"status"`  // 用户状态
+	Version   uint   `json:"version"` // 乐观锁版本号
+
+	// Avatars 头像各变体（如 thumb、medium）的地址
+	Avatars map[string]string `json:"avatars,omitempty"`
 }
 
-// 图片地址拼接
-func (u *User) getUrl() string {
-	if strings.Contains(u.Avatar, "http") {
-		return u.Avatar
-	} else if u.Avatar != "" {
-		return setting.AppSetting.ImageUrl + "/" + u.Avatar
-	}
-	return ""
+// 图片地址拼接，variant 为空时返回原图，否则返回 upload.Image.Variants 中对应变体的地址
+func (u *User) getUrl(variant string) string {
+	return upload.ImageURL(u.Avatar, variant)
 }
 
 // Format 格式化普通用户信息
@@ -76,20 +81,22 @@ func (u *User) Format() *UserInfo {
 		Username:  u.Username,
 		Email:     u.Email,
 		Phone:     u.Phone,
-		Avatar:    u.getUrl(),
+		Avatar:    u.getUrl(""),
+		Avatars:   upload.ImageURLs(u.Avatar),
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		FullName:  fullName,
 		Lang:      u.Lang,
 		Status:    u.Status,
+		Version:   u.Version,
 	}
 }
 
 // GetUserInfo 获取用户信息
-func (u *User) GetUserInfo() (*User, error) {
+func (u *User) GetUserInfo(ctx context.Context) (*User, error) {
 	var user User
 	if u.ID > 0 {
-		err := db.Where("id = ?", u.ID).First(&user).Error
+		err := db.WithContext(ctx).Where("id = ?", u.ID).First(&user).Error
 		if err != nil {
 			global.Logger.Errorf("GetUserInfo error: %v", err)
 			return &user, err
@@ -102,48 +109,42 @@ func (u *User) GetUserInfo() (*User, error) {
 }
 
 // GetUsersByIDs 根据ID列表获取用户
-func (u *User) GetUsersByIDs(Ids []int) ([]*User, error) {
+func (u *User) GetUsersByIDs(ctx context.Context, Ids []int) ([]*User, error) {
 	var users []*User
 	if len(Ids) > 0 {
-		db.Where("id in (?)", Ids).Find(&users)
+		db.WithContext(ctx).Where("id in (?)", Ids).Find(&users)
 	}
 	return users, nil
 }
 
-// GetUsers 获取普通用户列表（API使用）
-func GetUsers(page, limit int, keyword, status string) ([]*User, int64, error) {
-	var users []*User
-	var total int64
-
-	query := db.Model(&User{})
-
-	// 添加搜索条件
-	if keyword != "" {
-		query = query.Where("first_name LIKE ? OR last_name LIKE ? OR phone LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
-	}
-
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
-
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
-		return nil, 0, err
-	}
+// UserListSchema 用户列表可过滤、排序与输出的字段
+var UserListSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Kind: query.Int, Ops: query.Range, Sortable: true},
+		"username":   {Column: "username", Ops: query.Text, Sortable: true},
+		"email":      {Column: "email", Ops: query.Text},
+		"phone":      {Column: "phone", Ops: query.Text},
+		"first_name": {Column: "first_name", Ops: query.Text, Sortable: true},
+		"last_name":  {Column: "last_name", Ops: query.Text, Sortable: true},
+		"lang":       {Column: "lang", Ops: query.Exact},
+		"status":     {Column: "status", Kind: query.Int, Ops: query.Exact},
+		"created_at": {Column: "created_at", Kind: query.Time, Ops: query.Range, Sortable: true},
+		"avatar":     {},
+		"full_name":  {},
+	},
+	Search:      []string{"first_name", "last_name", "phone"},
+	Key:         "id",
+	DefaultSort: []query.Sort{{Field: "id"}},
+}
 
-	return users, total, nil
+// GetUsers 按查询规格获取普通用户列表
+func GetUsers(ctx context.Context, spec *query.Spec) (*query.Page[*User], error) {
+	return query.Find[*User](db.WithContext(ctx).Model(&User{}), spec)
 }
 
 // CreateUser 创建普通用户
-func (u *User) CreateUser() error {
-	err := db.Create(u).Error
+func (u *User) CreateUser(ctx context.Context) error {
+	err := db.WithContext(ctx).Create(u).Error
 	if err != nil {
 		global.Logger.Errorf("CreateUser error: %v", err)
 		return err
@@ -151,22 +152,83 @@ func (u *User) CreateUser() error {
 	return nil
 }
 
-// UpdateUser 更新用户信息
-func (u *User) UpdateUser() error {
-	err := db.Save(u).Error
+// UpdateUser 整体更新用户信息（乐观锁）
+// 以 u.Version 为前置版本，版本不一致返回 ErrVersionConflict；成功后 u.Version 为新版本
+func (u *User) UpdateUser(ctx context.Context) error {
+	expected := u.Version
+	u.Version = expected + 1
+	result := db.WithContext(ctx).Model(u).Where("version = ?", expected).
+		Select("*").Omit("id", "created_at", "deleted_at").Updates(u)
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = versionMiss(db.WithContext(ctx), &User{}, byID(u.ID))
+	}
 	if err != nil {
+		u.Version = expected
 		global.Logger.Errorf("UpdateUser error: %v", err)
 		return err
 	}
 	return nil
 }
 
-// DeleteUser 删除用户
-func (u *User) DeleteUser() error {
-	err := db.Delete(u).Error
+// UserPatch 用户部分更新，nil 字段保持不变
+type UserPatch struct {
+	FirstName *string
+	LastName  *string
+	Phone     *string
+	Lang      *string
+	Avatar    *string
+	Status    *int
+}
+
+// columns 只包含提供了值的列
+func (p UserPatch) columns() map[string]interface{} {
+	cols := make(map[string]interface{})
+	setColumn(cols, "first_name", p.FirstName)
+	setColumn(cols, "last_name", p.LastName)
+	setColumn(cols, "phone", p.Phone)
+	setColumn(cols, "lang", p.Lang)
+	setColumn(cols, "avatar", p.Avatar)
+	setColumn(cols, "status", p.Status)
+	return cols
+}
+
+// PatchUser 部分更新用户（乐观锁），version 为 0 时不校验版本，返回更新后的用户
+func PatchUser(ctx context.Context, id uint, version uint, patch UserPatch) (*User, error) {
+	if err := updateVersioned(db.WithContext(ctx), &User{}, byID(id), version, patch.columns()); err != nil {
+		return nil, err
+	}
+	var u User
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// DeleteUser 删除用户（软删除，进入回收站）
+func (u *User) DeleteUser(ctx context.Context) error {
+	err := db.WithContext(ctx).Delete(u).Error
 	if err != nil {
 		global.Logger.Errorf("DeleteUser error: %v", err)
 		return err
 	}
 	return nil
 }
+
+// CountUsers 统计普通用户总数
+func CountUsers(ctx context.Context) (int64, error) {
+	var total int64
+	if err := db.WithContext(ctx).Model(&User{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// CountActiveUsersSince 统计指定时间之后登录过的普通用户数
+func CountActiveUsersSince(ctx context.Context, since time.Time) (int64, error) {
+	var total int64
+	if err := db.WithContext(ctx).Model(&User{}).Where("last_login_at >= ?", since).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
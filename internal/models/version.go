@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict 乐观锁冲突：记录在读取之后已被其他请求修改
+var ErrVersionConflict = errors.New("record version conflict")
+
+// updateVersioned 以版本号为条件更新列并递增版本（乐观锁）
+// scope 定位目标记录（主键、租户等条件）；version 为 0 时不校验版本。
+// 没有可更新的列时只校验版本，不产生写入。
+func updateVersioned(tx *gorm.DB, model interface{}, scope func(*gorm.DB) *gorm.DB, version uint, columns map[string]interface{}) error {
+	q := scope(tx.Model(model))
+	if version > 0 {
+		q = q.Where("version = ?", version)
+	}
+	if len(columns) == 0 {
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+		return versionMiss(tx, model, scope)
+	}
+
+	values := make(map[string]interface{}, len(columns)+1)
+	for k, v := range columns {
+		values[k] = v
+	}
+	values["version"] = gorm.Expr("version + 1")
+	result := q.Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	return versionMiss(tx, model, scope)
+}
+
+// versionMiss 条件更新未命中时区分原因：记录不存在返回 gorm.ErrRecordNotFound，否则为版本冲突
+func versionMiss(tx *gorm.DB, model interface{}, scope func(*gorm.DB) *gorm.DB) error {
+	var n int64
+	if err := scope(tx.Model(model)).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
+
+// byID 按主键定位记录
+func byID(id uint) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB { return q.Where("id = ?", id) }
+}
+
+// setColumn 字段提供了值时写入待更新列
+func setColumn[T any](cols map[string]interface{}, column string, v *T) {
+	if v != nil {
+		cols[column] = *v
+	}
+}
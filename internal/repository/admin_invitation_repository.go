@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+)
+
+// AdminInvitationRepositoryImpl 管理员邀请仓储实现
+type AdminInvitationRepositoryImpl struct {
+	logger container.Logger
+}
+
+// NewAdminInvitationRepository 创建管理员邀请仓储实例
+func NewAdminInvitationRepository(logger container.Logger) container.AdminInvitationRepository {
+	return &AdminInvitationRepositoryImpl{logger: logger}
+}
+
+// Create 创建邀请（同邮箱旧邀请自动撤销）
+func (r *AdminInvitationRepositoryImpl) Create(ctx context.Context, inv *models.AdminInvitation) error {
+	err := models.CreateAdminInvitation(ctx, inv)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to create invitation for tenant %d: %v", inv.TenantID, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_INSERT, 0, 0)
+}
+
+// GetByTokenHash 按令牌哈希获取邀请
+func (r *AdminInvitationRepositoryImpl) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AdminInvitation, error) {
+	inv, err := models.GetAdminInvitationByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_INVITATION_INVALID, 0)
+	}
+	return inv, nil
+}
+
+// ListPending 租户内待接受的邀请
+func (r *AdminInvitationRepositoryImpl) ListPending(ctx context.Context, tenantID uint) ([]models.AdminInvitation, error) {
+	list, err := models.ListPendingAdminInvitations(ctx, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list invitations for tenant %d: %v", tenantID, err)
+	}
+	return list, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// Revoke 撤销待接受的邀请
+func (r *AdminInvitationRepositoryImpl) Revoke(ctx context.Context, id, tenantID uint) error {
+	err := models.RevokeAdminInvitation(ctx, id, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to revoke invitation %d for tenant %d: %v", id, tenantID, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_UPDATE, e.ERROR_INVITATION_INVALID, 0)
+}
+
+// Accept 接受邀请
+func (r *AdminInvitationRepositoryImpl) Accept(ctx context.Context, inv *models.AdminInvitation, admin *models.AdminUser) error {
+	err := models.AcceptAdminInvitation(ctx, inv, admin)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to accept invitation %d: %v", inv.ID, err)
+	}
+	return dbError(err, e.ERROR_ADMIN_CREATE_FAIL, e.ERROR_INVITATION_INVALID, e.ERROR_USER_ALREADY_EXIST)
+}
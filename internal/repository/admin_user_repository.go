@@ -1,8 +1,13 @@
 package repository
 
 import (
+	"context"
+	"errors"
+
 	"justus/internal/container"
 	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
 )
 
 // AdminUserRepositoryImpl 管理员用户仓储实现
@@ -20,83 +25,164 @@ func NewAdminUserRepository(logger container.Logger, cache container.Cache) cont
 }
 
 // GetByID 根据ID获取管理员用户信息
-func (r *AdminUserRepositoryImpl) GetByID(id int) (*models.AdminUser, error) {
-	r.logger.Infof("Getting admin user by ID: %d", id)
+func (r *AdminUserRepositoryImpl) GetByID(ctx context.Context, id int) (*models.AdminUser, error) {
+	if id <= 0 {
+		return nil, e.New(e.ERROR_ADMIN_NOT_FOUND)
+	}
+	r.logger.WithContext(ctx).Infof("Getting admin user by ID: %d", id)
 
 	adminUser := models.AdminUser{
 		ID: uint(id),
 	}
-	result, err := adminUser.GetAdminUserInfo()
+	result, err := adminUser.GetAdminUserInfo(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to get admin user by ID %d: %v", id, err)
+		r.logger.WithContext(ctx).Errorf("Failed to get admin user by ID %d: %v", id, err)
 	} else {
-		r.logger.Debugf("Successfully retrieved admin user: %d", id)
+		r.logger.WithContext(ctx).Debugf("Successfully retrieved admin user: %d", id)
 	}
 
-	return result, err
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_ADMIN_NOT_FOUND, 0)
+	}
+	return result, nil
 }
 
 // GetByUsername 根据用户名获取管理员用户信息
-func (r *AdminUserRepositoryImpl) GetByUsername(username string) (*models.AdminUser, error) {
-	r.logger.Infof("Getting admin user by username: %s", username)
+func (r *AdminUserRepositoryImpl) GetByUsername(ctx context.Context, username string) (*models.AdminUser, error) {
+	r.logger.WithContext(ctx).Infof("Getting admin user by username: %s", username)
 
 	adminUser := models.AdminUser{
 		Username: username,
 	}
-	result, err := adminUser.GetAdminUserByUsername()
+	result, err := adminUser.GetAdminUserByUsername(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to get admin user by username %s: %v", username, err)
+		r.logger.WithContext(ctx).Errorf("Failed to get admin user by username %s: %v", username, err)
 	} else {
-		r.logger.Debugf("Successfully retrieved admin user by username: %s", username)
+		r.logger.WithContext(ctx).Debugf("Successfully retrieved admin user by username: %s", username)
+	}
+
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_ADMIN_NOT_FOUND, 0)
+	}
+	return result, nil
+}
+
+// GetByEmail 根据邮箱获取管理员用户信息
+func (r *AdminUserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*models.AdminUser, error) {
+	result, err := models.GetAdminUserByEmail(ctx, email)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_ADMIN_NOT_FOUND, 0)
 	}
+	return result, nil
+}
+
+// ListByTenant 按查询规格获取租户内的管理员列表
+func (r *AdminUserRepositoryImpl) ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[*models.AdminUser], error) {
+	result, err := models.GetAdminUsers(ctx, tenantID, spec)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list admin users for tenant %d: %v", tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return result, nil
+}
+
+// GetInTenant 获取租户内的管理员
+func (r *AdminUserRepositoryImpl) GetInTenant(ctx context.Context, id, tenantID uint) (*models.AdminUser, error) {
+	result, err := models.GetAdminUserInTenant(ctx, id, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to get admin user %d in tenant %d: %v", id, tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_ADMIN_NOT_FOUND, 0)
+	}
+	return result, nil
+}
+
+// CreateInTenant 创建管理员并绑定租户角色
+func (r *AdminUserRepositoryImpl) CreateInTenant(ctx context.Context, user *models.AdminUser, tenantID uint, roleIDs []uint) error {
+	r.logger.WithContext(ctx).Infof("Creating admin user %s in tenant %d", user.Username, tenantID)
+
+	err := models.CreateAdminUserInTenant(ctx, user, tenantID, roleIDs)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to create admin user in tenant %d: %v", tenantID, err)
+	}
+	return dbError(err, e.ERROR_ADMIN_CREATE_FAIL, 0, e.ERROR_USER_ALREADY_EXIST)
+}
+
+// RemoveFromTenant 将管理员移出租户
+func (r *AdminUserRepositoryImpl) RemoveFromTenant(ctx context.Context, id, tenantID uint) (bool, error) {
+	r.logger.WithContext(ctx).Infof("Removing admin user %d from tenant %d", id, tenantID)
+
+	deleted, err := models.RemoveAdminUserFromTenant(ctx, id, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to remove admin user %d from tenant %d: %v", id, tenantID, err)
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			return false, e.Wrap(e.ERROR_ADMIN_LAST_SUPER, err)
+		}
+		return false, dbError(err, e.ERROR_ADMIN_DELETE_FAIL, e.ERROR_ADMIN_NOT_FOUND, 0)
+	}
+	return deleted, nil
+}
+
+// SetMemberStatus 设置管理员在租户内的成员状态
+func (r *AdminUserRepositoryImpl) SetMemberStatus(ctx context.Context, id, tenantID uint, status int) error {
+	r.logger.WithContext(ctx).Infof("Setting member status of admin user %d in tenant %d to %d", id, tenantID, status)
+
+	err := models.SetAdminMemberStatus(ctx, id, tenantID, status)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to set member status of admin user %d in tenant %d: %v", id, tenantID, err)
+	}
+	return dbError(err, e.ERROR_ADMIN_UPDATE_FAIL, e.ERROR_ADMIN_NOT_FOUND, 0)
+}
 
-	return result, err
+// InOtherTenants 管理员是否还属于其他租户
+func (r *AdminUserRepositoryImpl) InOtherTenants(ctx context.Context, id, tenantID uint) (bool, error) {
+	other, err := models.AdminUserInOtherTenants(ctx, id, tenantID)
+	return other, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
 }
 
 // Create 创建管理员用户
-func (r *AdminUserRepositoryImpl) Create(user *models.AdminUser) error {
-	r.logger.Infof("Creating admin user: %s", user.Username)
+func (r *AdminUserRepositoryImpl) Create(ctx context.Context, user *models.AdminUser) error {
+	r.logger.WithContext(ctx).Infof("Creating admin user: %s", user.Username)
 
-	err := user.CreateAdminUser()
+	err := user.CreateAdminUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to create admin user: %v", err)
+		r.logger.WithContext(ctx).Errorf("Failed to create admin user: %v", err)
 	} else {
-		r.logger.Infof("Successfully created admin user with ID: %d", user.ID)
+		r.logger.WithContext(ctx).Infof("Successfully created admin user with ID: %d", user.ID)
 	}
 
-	return err
+	return dbError(err, e.ERROR_ADMIN_CREATE_FAIL, 0, e.ERROR_USER_ALREADY_EXIST)
 }
 
 // Update 更新管理员用户
-func (r *AdminUserRepositoryImpl) Update(user *models.AdminUser) error {
-	r.logger.Infof("Updating admin user ID: %d", user.ID)
+func (r *AdminUserRepositoryImpl) Update(ctx context.Context, user *models.AdminUser) error {
+	r.logger.WithContext(ctx).Infof("Updating admin user ID: %d", user.ID)
 
-	err := user.UpdateAdminUser()
+	err := user.UpdateAdminUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to update admin user ID %d: %v", user.ID, err)
+		r.logger.WithContext(ctx).Errorf("Failed to update admin user ID %d: %v", user.ID, err)
 	} else {
-		r.logger.Infof("Successfully updated admin user ID: %d", user.ID)
+		r.logger.WithContext(ctx).Infof("Successfully updated admin user ID: %d", user.ID)
 	}
 
-	return err
+	return dbError(err, e.ERROR_ADMIN_UPDATE_FAIL, e.ERROR_ADMIN_NOT_FOUND, 0)
 }
 
 // Delete 删除管理员用户
-func (r *AdminUserRepositoryImpl) Delete(id int) error {
-	r.logger.Infof("Deleting admin user ID: %d", id)
+func (r *AdminUserRepositoryImpl) Delete(ctx context.Context, id int) error {
+	r.logger.WithContext(ctx).Infof("Deleting admin user ID: %d", id)
 
 	adminUser := models.AdminUser{ID: uint(id)}
-	err := adminUser.DeleteAdminUser()
+	err := adminUser.DeleteAdminUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to delete admin user ID %d: %v", id, err)
+		r.logger.WithContext(ctx).Errorf("Failed to delete admin user ID %d: %v", id, err)
 	} else {
-		r.logger.Infof("Successfully deleted admin user ID: %d", id)
+		r.logger.WithContext(ctx).Infof("Successfully deleted admin user ID: %d", id)
 	}
 
-	return err
+	return dbError(err, e.ERROR_ADMIN_DELETE_FAIL, e.ERROR_ADMIN_NOT_FOUND, 0)
 }
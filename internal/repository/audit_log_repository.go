@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
+)
+
+// AuditLogRepositoryImpl 审计日志仓储实现
+type AuditLogRepositoryImpl struct {
+	logger container.Logger
+}
+
+// NewAuditLogRepository 创建审计日志仓储实例
+func NewAuditLogRepository(logger container.Logger) container.AuditLogRepository {
+	return &AuditLogRepositoryImpl{logger: logger}
+}
+
+// Create 批量写入审计日志
+func (r *AuditLogRepositoryImpl) Create(ctx context.Context, logs []models.AuditLog) error {
+	err := models.CreateAuditLogs(ctx, logs)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to write %d audit logs: %v", len(logs), err)
+	}
+	return dbError(err, e.ERROR_DATABASE_INSERT, 0, 0)
+}
+
+// ListByTenant 按租户查询审计日志
+func (r *AuditLogRepositoryImpl) ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.AuditLog], error) {
+	page, err := models.ListAuditLogs(ctx, tenantID, spec)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list audit logs for tenant %d: %v", tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return page, nil
+}
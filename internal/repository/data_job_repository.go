@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
+)
+
+// DataJobRepositoryImpl 导入导出任务仓储实现
+type DataJobRepositoryImpl struct {
+	logger container.Logger
+}
+
+// NewDataJobRepository 创建导入导出任务仓储实例
+func NewDataJobRepository(logger container.Logger) container.DataJobRepository {
+	return &DataJobRepositoryImpl{logger: logger}
+}
+
+// Create 创建任务
+func (r *DataJobRepositoryImpl) Create(ctx context.Context, job *models.DataJob) error {
+	err := models.CreateDataJob(ctx, job)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to create %s job for %s: %v", job.Kind, job.Resource, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_INSERT, 0, 0)
+}
+
+// GetForTenant 获取本租户的任务
+func (r *DataJobRepositoryImpl) GetForTenant(ctx context.Context, id, tenantID uint) (*models.DataJob, error) {
+	job, err := models.GetDataJobForTenant(ctx, id, tenantID)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_DATA_JOB_NOT_FOUND, 0)
+	}
+	return job, nil
+}
+
+// GetByFile 按结果文件名获取本租户的任务
+func (r *DataJobRepositoryImpl) GetByFile(ctx context.Context, tenantID uint, name string) (*models.DataJob, error) {
+	job, err := models.GetDataJobByFile(ctx, tenantID, name)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_DATA_JOB_NOT_FOUND, 0)
+	}
+	return job, nil
+}
+
+// ListByTenant 按租户查询任务
+func (r *DataJobRepositoryImpl) ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.DataJob], error) {
+	page, err := models.ListDataJobs(ctx, tenantID, spec)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list data jobs for tenant %d: %v", tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return page, nil
+}
+
+// Update 更新任务进度与状态
+func (r *DataJobRepositoryImpl) Update(ctx context.Context, id uint, columns map[string]interface{}) error {
+	err := models.UpdateDataJob(ctx, id, columns)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to update data job %d: %v", id, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_UPDATE, 0, 0)
+}
+
+// CountActive 租户排队中与执行中的任务数
+func (r *DataJobRepositoryImpl) CountActive(ctx context.Context, tenantID uint) (int64, error) {
+	n, err := models.CountActiveDataJobs(ctx, tenantID)
+	return n, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// FailActive 将全部排队中与执行中的任务标记为失败
+func (r *DataJobRepositoryImpl) FailActive(ctx context.Context, reason string) ([]models.DataJob, error) {
+	jobs, err := models.FailActiveDataJobs(ctx, reason)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to fail active data jobs: %v", err)
+	}
+	return jobs, dbError(err, e.ERROR_DATABASE_UPDATE, 0, 0)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+
+	"justus/internal/models"
+	"justus/pkg/e"
+
+	"gorm.io/gorm"
+)
+
+// dbError 将 GORM 错误映射为应用错误
+// 记录不存在映射为 notFound，唯一键冲突映射为 conflict（为 0 时不做区分），乐观锁冲突统一映射为 ERROR_VERSION_CONFLICT，
+// 其余归为 fallback，原始错误保留为 Cause
+func dbError(err error, fallback, notFound, conflict int) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrVersionConflict):
+		return e.Wrap(e.ERROR_VERSION_CONFLICT, err)
+	case notFound != 0 && errors.Is(err, gorm.ErrRecordNotFound):
+		return e.Wrap(notFound, err)
+	case conflict != 0 && errors.Is(err, gorm.ErrDuplicatedKey):
+		return e.Wrap(conflict, err)
+	}
+	return e.Wrap(fallback, err)
+}
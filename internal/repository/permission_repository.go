@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+)
+
+// PermissionRepositoryImpl 权限仓储实现
+type PermissionRepositoryImpl struct {
+	logger container.Logger
+	cache  container.Cache
+}
+
+// NewPermissionRepository 创建权限仓储实例
+func NewPermissionRepository(logger container.Logger, cache container.Cache) container.PermissionRepository {
+	return &PermissionRepositoryImpl{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+// List 获取全部权限
+func (r *PermissionRepositoryImpl) List(ctx context.Context) ([]models.Permission, error) {
+	perms, err := models.ListPermissions(ctx)
+	return perms, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// GetByName 根据权限名获取权限
+func (r *PermissionRepositoryImpl) GetByName(ctx context.Context, name string) (*models.Permission, error) {
+	perm, err := models.GetPermissionByName(ctx, name)
+	return perm, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// Create 创建权限
+func (r *PermissionRepositoryImpl) Create(ctx context.Context, p *models.Permission) error {
+	r.logger.WithContext(ctx).Infof("Creating permission: %s", p.Name)
+	return dbError(models.CreatePermission(ctx, p), e.ERROR_DATABASE_INSERT, 0, e.ERROR_PERMISSION_EXIST)
+}
+
+// GetMenusByIDs 获取指定ID中的菜单权限
+func (r *PermissionRepositoryImpl) GetMenusByIDs(ctx context.Context, ids []uint) ([]models.Permission, error) {
+	menus, err := models.GetMenuPermissionsByIDs(ctx, ids)
+	return menus, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// GetAdminPermissionIDsInTenant 获取管理员在租户内的权限ID
+func (r *PermissionRepositoryImpl) GetAdminPermissionIDsInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]uint, error) {
+	ids, err := models.GetAdminUserPermissionIDsInTenant(ctx, adminUserID, tenantID)
+	return ids, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// GetAdminPermissionNamesInTenant 获取管理员在租户内的权限名称
+func (r *PermissionRepositoryImpl) GetAdminPermissionNamesInTenant(ctx context.Context, adminUserID int, tenantID uint) ([]string, error) {
+	names, err := models.GetAdminUserPermissionNamesInTenant(ctx, adminUserID, tenantID)
+	return names, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
+)
+
+// RoleRepositoryImpl 角色仓储实现
+type RoleRepositoryImpl struct {
+	logger container.Logger
+	cache  container.Cache
+}
+
+// NewRoleRepository 创建角色仓储实例
+func NewRoleRepository(logger container.Logger, cache container.Cache) container.RoleRepository {
+	return &RoleRepositoryImpl{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+// ListByTenant 按查询规格获取租户角色（不含系统级角色）
+func (r *RoleRepositoryImpl) ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Role], error) {
+	result, err := models.ListTenantRoles(ctx, tenantID, spec)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list roles for tenant %d: %v", tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return result, nil
+}
+
+// GetByIDForTenant 获取本租户的角色
+func (r *RoleRepositoryImpl) GetByIDForTenant(ctx context.Context, id, tenantID uint) (*models.Role, error) {
+	role, err := models.GetRoleByIDForTenant(ctx, id, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to get role %d for tenant %d: %v", id, tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_ROLE_NOT_FOUND, 0)
+	}
+	return role, nil
+}
+
+// GetByIDsAndTenant 获取租户可用的角色（含系统级角色）
+func (r *RoleRepositoryImpl) GetByIDsAndTenant(ctx context.Context, ids []int, tenantID uint) ([]models.Role, error) {
+	roles, err := models.GetRolesByIDsAndTenant(ctx, ids, tenantID)
+	return roles, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// Create 创建角色
+func (r *RoleRepositoryImpl) Create(ctx context.Context, role *models.Role) error {
+	r.logger.WithContext(ctx).Infof("Creating role: tenant_id=%d, name=%s", role.TenantID, role.Name)
+
+	created, err := models.CreateRoleForTenant(ctx, role.TenantID, role.Name, role.DisplayName, role.Description, role.Status)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to create role: %v", err)
+		return dbError(err, e.ERROR_ROLE_CREATE_FAIL, 0, e.ERROR_ROLE_ALREADY_EXIST)
+	}
+	*role = *created
+	return nil
+}
+
+// UpdateForTenant 更新本租户角色（乐观锁），version 为 0 时不校验版本
+func (r *RoleRepositoryImpl) UpdateForTenant(ctx context.Context, id, tenantID, version uint, patch models.RolePatch) (*models.Role, error) {
+	role, err := models.UpdateRoleForTenant(ctx, id, tenantID, version, patch)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to update role %d for tenant %d: %v", id, tenantID, err)
+		return nil, dbError(err, e.ERROR_ROLE_UPDATE_FAIL, e.ERROR_ROLE_NOT_FOUND, 0)
+	}
+	return role, nil
+}
+
+// DeleteForTenant 删除本租户角色（需无管理员绑定）
+func (r *RoleRepositoryImpl) DeleteForTenant(ctx context.Context, id, tenantID uint) error {
+	r.logger.WithContext(ctx).Infof("Deleting role: tenant_id=%d, id=%d", tenantID, id)
+
+	err := models.DeleteRoleForTenant(ctx, id, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to delete role %d for tenant %d: %v", id, tenantID, err)
+	}
+	if errors.Is(err, models.ErrRoleInUse) {
+		return e.Wrap(e.ERROR_ROLE_IN_USE, err)
+	}
+	return dbError(err, e.ERROR_ROLE_DELETE_FAIL, e.ERROR_ROLE_NOT_FOUND, 0)
+}
+
+// GetPermissionIDs 获取角色绑定的权限ID
+func (r *RoleRepositoryImpl) GetPermissionIDs(ctx context.Context, roleID uint) ([]uint, error) {
+	ids, err := models.GetPermissionIDsOfRole(ctx, roleID)
+	return ids, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// ReplacePermissions 覆盖式替换角色权限
+func (r *RoleRepositoryImpl) ReplacePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
+	err := models.ReplaceRolePermissions(ctx, roleID, permissionIDs)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to replace permissions of role %d: %v", roleID, err)
+	}
+	return dbError(err, e.ERROR_ROLE_UPDATE_FAIL, 0, 0)
+}
+
+// AssignToAdminInTenant 覆盖式设置管理员在租户下的角色
+func (r *RoleRepositoryImpl) AssignToAdminInTenant(ctx context.Context, adminUserID, tenantID uint, roleIDs []uint) error {
+	err := models.AssignRolesToAdminInTenant(ctx, adminUserID, tenantID, roleIDs)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to assign roles to admin %d in tenant %d: %v", adminUserID, tenantID, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_UPDATE, 0, 0)
+}
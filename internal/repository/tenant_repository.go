@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+)
+
+// TenantRepositoryImpl 租户仓储实现
+type TenantRepositoryImpl struct {
+	logger container.Logger
+	cache  container.Cache
+}
+
+// NewTenantRepository 创建租户仓储实例
+func NewTenantRepository(logger container.Logger, cache container.Cache) container.TenantRepository {
+	return &TenantRepositoryImpl{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+// GetByID 获取租户信息
+func (r *TenantRepositoryImpl) GetByID(ctx context.Context, id uint) (*models.Tenant, error) {
+	tenant, err := models.GetTenantByID(ctx, id)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to get tenant %d: %v", id, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_TENANT_NOT_FOUND, 0)
+	}
+	return tenant, nil
+}
+
+// Update 更新租户（乐观锁），version 为 0 时不校验版本
+func (r *TenantRepositoryImpl) Update(ctx context.Context, id, version uint, patch models.TenantPatch) (*models.Tenant, error) {
+	r.logger.WithContext(ctx).Infof("Updating tenant %d, version: %d", id, version)
+
+	tenant, err := models.UpdateTenant(ctx, id, version, patch)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to update tenant %d: %v", id, err)
+		return nil, dbError(err, e.ERROR_DATABASE_UPDATE, e.ERROR_TENANT_NOT_FOUND, e.ERROR_TENANT_CODE_EXIST)
+	}
+	return tenant, nil
+}
+
+// GetPermissionIDs 获取租户白名单权限ID
+func (r *TenantRepositoryImpl) GetPermissionIDs(ctx context.Context, tenantID uint) ([]uint, error) {
+	ids, err := models.GetTenantPermissionIDs(ctx, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to get permission whitelist of tenant %d: %v", tenantID, err)
+	}
+	return ids, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
+
+// ReplacePermissions 覆盖式替换租户白名单
+func (r *TenantRepositoryImpl) ReplacePermissions(ctx context.Context, tenantID uint, permissionIDs []uint) error {
+	r.logger.WithContext(ctx).Infof("Replacing permission whitelist of tenant %d: %d permissions", tenantID, len(permissionIDs))
+
+	err := models.ReplaceTenantPermissions(ctx, tenantID, permissionIDs)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to replace permission whitelist of tenant %d: %v", tenantID, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_UPDATE, 0, 0)
+}
+
+// Delete 软删除租户
+func (r *TenantRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	r.logger.WithContext(ctx).Infof("Deleting tenant %d", id)
+
+	err := models.DeleteTenant(ctx, id)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to delete tenant %d: %v", id, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_DELETE, e.ERROR_TENANT_NOT_FOUND, 0)
+}
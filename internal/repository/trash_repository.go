@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
+
+	"gorm.io/gorm"
+)
+
+// TrashRepositoryImpl 回收站仓储实现，按资源名分派到对应模型
+type TrashRepositoryImpl struct {
+	logger container.Logger
+}
+
+// NewTrashRepository 创建回收站仓储实例
+func NewTrashRepository(logger container.Logger) container.TrashRepository {
+	return &TrashRepositoryImpl{logger: logger}
+}
+
+// List 已删除记录列表
+func (r *TrashRepositoryImpl) List(ctx context.Context, resource string, tenantID uint, spec *query.Spec) (*container.TrashPage, error) {
+	scope := tenantScope(resource, tenantID)
+	var (
+		page *container.TrashPage
+		err  error
+	)
+	switch resource {
+	case models.TrashUsers:
+		page, err = listTrashed[models.User](ctx, scope, spec)
+	case models.TrashAdminUsers:
+		page, err = listTrashed[models.AdminUser](ctx, scope, spec)
+	case models.TrashRoles:
+		page, err = listTrashed[models.Role](ctx, scope, spec)
+	case models.TrashTenants:
+		page, err = listTrashed[models.Tenant](ctx, scope, spec)
+	default:
+		return nil, e.NotFound(e.ERROR_TRASH_NOT_FOUND)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list trashed %s: %v", resource, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return page, nil
+}
+
+// Restore 恢复已删除记录
+func (r *TrashRepositoryImpl) Restore(ctx context.Context, resource string, tenantID, id uint) error {
+	scope := tenantScope(resource, tenantID)
+	var err error
+	switch resource {
+	case models.TrashUsers:
+		err = models.RestoreTrashed[models.User](ctx, id, scope)
+	case models.TrashAdminUsers:
+		err = models.RestoreTrashed[models.AdminUser](ctx, id, scope)
+	case models.TrashRoles:
+		err = models.RestoreTrashed[models.Role](ctx, id, scope)
+	case models.TrashTenants:
+		err = models.RestoreTrashed[models.Tenant](ctx, id, scope)
+	default:
+		return e.NotFound(e.ERROR_TRASH_NOT_FOUND)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to restore %s %d: %v", resource, id, err)
+	}
+	if errors.Is(err, models.ErrRestoreOrphan) {
+		return e.Wrap(e.ERROR_TRASH_RESTORE_ORPHAN, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_UPDATE, e.ERROR_TRASH_NOT_FOUND, e.ERROR_TRASH_RESTORE_CONFLICT)
+}
+
+// Purge 永久删除已删除记录
+func (r *TrashRepositoryImpl) Purge(ctx context.Context, resource string, tenantID, id uint) error {
+	scope := tenantScope(resource, tenantID)
+	var err error
+	switch resource {
+	case models.TrashUsers:
+		err = models.PurgeTrashed[models.User](ctx, id, scope)
+	case models.TrashAdminUsers:
+		err = models.PurgeTrashed[models.AdminUser](ctx, id, scope)
+	case models.TrashRoles:
+		err = models.PurgeTrashed[models.Role](ctx, id, scope)
+	case models.TrashTenants:
+		err = models.PurgeTrashed[models.Tenant](ctx, id, scope)
+	default:
+		return e.NotFound(e.ERROR_TRASH_NOT_FOUND)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to purge %s %d: %v", resource, id, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_DELETE, e.ERROR_TRASH_NOT_FOUND, 0)
+}
+
+// PurgeBefore 永久删除 cutoff 之前软删除的记录
+func (r *TrashRepositoryImpl) PurgeBefore(ctx context.Context, resource string, cutoff time.Time) (int64, error) {
+	var (
+		n   int64
+		err error
+	)
+	switch resource {
+	case models.TrashUsers:
+		n, err = models.PurgeTrashedBefore[models.User](ctx, cutoff)
+	case models.TrashAdminUsers:
+		n, err = models.PurgeTrashedBefore[models.AdminUser](ctx, cutoff)
+	case models.TrashRoles:
+		n, err = models.PurgeTrashedBefore[models.Role](ctx, cutoff)
+	case models.TrashTenants:
+		n, err = models.PurgeTrashedBefore[models.Tenant](ctx, cutoff)
+	default:
+		return 0, e.NotFound(e.ERROR_TRASH_NOT_FOUND)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to purge expired %s: %v", resource, err)
+	}
+	return n, dbError(err, e.ERROR_DATABASE_DELETE, 0, 0)
+}
+
+// listTrashed 查询并包装为与模型无关的分页结果
+func listTrashed[T any](ctx context.Context, scope func(*gorm.DB) *gorm.DB, spec *query.Spec) (*container.TrashPage, error) {
+	page, err := models.ListTrashed[T](ctx, scope, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &container.TrashPage{Items: page.Items, Pagination: page.Pagination}, nil
+}
+
+// tenantScope 租户级资源（角色）按租户隔离，其余资源不加条件
+func tenantScope(resource string, tenantID uint) func(*gorm.DB) *gorm.DB {
+	if resource != models.TrashRoles || tenantID == 0 {
+		return nil
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return models.WithTenant(db, tenantID)
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
+
+	"gorm.io/gorm"
+)
+
+// UploadRepositoryImpl 上传文件仓储实现
+type UploadRepositoryImpl struct {
+	logger container.Logger
+}
+
+// NewUploadRepository 创建上传文件仓储实例
+func NewUploadRepository(logger container.Logger) container.UploadRepository {
+	return &UploadRepositoryImpl{logger: logger}
+}
+
+// Create 写入上传记录
+func (r *UploadRepositoryImpl) Create(ctx context.Context, u *models.Upload) error {
+	err := models.CreateUpload(ctx, u)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to create upload record %s: %v", u.Key, err)
+	}
+	return dbError(err, e.ERROR_DATABASE_INSERT, 0, 0)
+}
+
+// GetForTenant 获取本租户的上传记录
+func (r *UploadRepositoryImpl) GetForTenant(ctx context.Context, id, tenantID uint) (*models.Upload, error) {
+	u, err := models.GetUploadForTenant(ctx, id, tenantID)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_UPLOAD_NOT_FOUND, 0)
+	}
+	return u, nil
+}
+
+// FindByMD5 查找本租户相同内容的记录，不存在时返回 nil
+func (r *UploadRepositoryImpl) FindByMD5(ctx context.Context, tenantID uint, md5 string) (*models.Upload, error) {
+	u, err := models.GetUploadByMD5(ctx, tenantID, md5)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return u, nil
+}
+
+// GetByKey 按存储键获取本租户的上传记录
+func (r *UploadRepositoryImpl) GetByKey(ctx context.Context, tenantID uint, key string) (*models.Upload, error) {
+	u, err := models.GetUploadByKey(ctx, tenantID, key)
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_UPLOAD_NOT_FOUND, 0)
+	}
+	return u, nil
+}
+
+// ListByTenant 按租户查询上传记录
+func (r *UploadRepositoryImpl) ListByTenant(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Upload], error) {
+	page, err := models.ListUploads(ctx, tenantID, spec)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to list uploads for tenant %d: %v", tenantID, err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+	}
+	return page, nil
+}
+
+// Delete 删除上传记录，返回仍引用同一存储键的记录数
+func (r *UploadRepositoryImpl) Delete(ctx context.Context, u *models.Upload) (int64, error) {
+	refs, err := models.DeleteUpload(ctx, u)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to delete upload %d: %v", u.ID, err)
+		return 0, dbError(err, e.ERROR_DATABASE_DELETE, 0, 0)
+	}
+	return refs, nil
+}
+
+// Usage 租户已用存储（字节）与文件数
+func (r *UploadRepositoryImpl) Usage(ctx context.Context, tenantID uint) (int64, int64, error) {
+	used, files, err := models.UploadUsage(ctx, tenantID)
+	return used, files, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
+}
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
+
 	"justus/internal/container"
 	"justus/internal/models"
+	"justus/pkg/e"
+	"justus/pkg/query"
 )
 
 // UserRepositoryImpl 用户仓储实现
@@ -20,102 +24,119 @@ func NewUserRepository(logger container.Logger, cache container.Cache) container
 }
 
 // GetByID 根据ID获取用户信息
-func (r *UserRepositoryImpl) GetByID(id int) (*models.User, error) {
-	r.logger.Infof("Getting user by ID: %d", id)
+func (r *UserRepositoryImpl) GetByID(ctx context.Context, id int) (*models.User, error) {
+	if id <= 0 {
+		return nil, e.New(e.ERROR_USER_NOT_FOUND)
+	}
+	r.logger.WithContext(ctx).Infof("Getting user by ID: %d", id)
 
 	user := models.User{
 		ID: uint(id),
 	}
-	result, err := user.GetUserInfo()
+	result, err := user.GetUserInfo(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to get user by ID %d: %v", id, err)
+		r.logger.WithContext(ctx).Errorf("Failed to get user by ID %d: %v", id, err)
 	} else {
-		r.logger.Debugf("Successfully retrieved user: %d", id)
+		r.logger.WithContext(ctx).Debugf("Successfully retrieved user: %d", id)
 	}
 
-	return result, err
+	if err != nil {
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, e.ERROR_USER_NOT_FOUND, 0)
+	}
+	return result, nil
 }
 
 // GetByIDs 批量获取用户信息
-func (r *UserRepositoryImpl) GetByIDs(ids []int) ([]*models.User, error) {
-	r.logger.Infof("Getting users by IDs: %v", ids)
+func (r *UserRepositoryImpl) GetByIDs(ctx context.Context, ids []int) ([]*models.User, error) {
+	r.logger.WithContext(ctx).Infof("Getting users by IDs: %v", ids)
 
 	user := models.User{}
-	result, err := user.GetUsersByIDs(ids)
+	result, err := user.GetUsersByIDs(ctx, ids)
 
 	if err != nil {
-		r.logger.Errorf("Failed to get users by IDs %v: %v", ids, err)
+		r.logger.WithContext(ctx).Errorf("Failed to get users by IDs %v: %v", ids, err)
 	} else {
-		r.logger.Debugf("Successfully retrieved %d users", len(result))
+		r.logger.WithContext(ctx).Debugf("Successfully retrieved %d users", len(result))
 	}
 
-	return result, err
+	return result, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
 }
 
-// GetUsers 获取用户列表
-func (r *UserRepositoryImpl) GetUsers(page, limit int, keyword, status string) ([]*models.User, int64, error) {
-	r.logger.Infof("Getting users list - page: %d, limit: %d, keyword: %s, status: %s", page, limit, keyword, status)
+// GetUsers 按查询规格获取用户列表
+func (r *UserRepositoryImpl) GetUsers(ctx context.Context, spec *query.Spec) (*query.Page[*models.User], error) {
+	r.logger.WithContext(ctx).Infof("Getting users list - page: %d, limit: %d, keyword: %s, cursor: %t", spec.Page, spec.Limit, spec.Keyword, spec.Cursor())
 
-	result, total, err := models.GetUsers(page, limit, keyword, status)
+	result, err := models.GetUsers(ctx, spec)
 
 	if err != nil {
-		r.logger.Errorf("Failed to get users list: %v", err)
-	} else {
-		r.logger.Debugf("Successfully retrieved %d users out of %d total", len(result), total)
+		r.logger.WithContext(ctx).Errorf("Failed to get users list: %v", err)
+		return nil, dbError(err, e.ERROR_DATABASE_QUERY, 0, 0)
 	}
-
-	return result, total, err
+	r.logger.WithContext(ctx).Debugf("Successfully retrieved %d users", len(result.Items))
+	return result, nil
 }
 
 // Create 创建用户
-func (r *UserRepositoryImpl) Create(user *models.User) error {
-	r.logger.Infof("Creating user: %s %s", user.FirstName, user.LastName)
+func (r *UserRepositoryImpl) Create(ctx context.Context, user *models.User) error {
+	r.logger.WithContext(ctx).Infof("Creating user: %s %s", user.FirstName, user.LastName)
 
-	err := user.CreateUser()
+	err := user.CreateUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to create user: %v", err)
+		r.logger.WithContext(ctx).Errorf("Failed to create user: %v", err)
 	} else {
-		r.logger.Infof("Successfully created user with ID: %d", user.ID)
+		r.logger.WithContext(ctx).Infof("Successfully created user with ID: %d", user.ID)
 		// 可以在这里清理相关缓存
 		// r.cache.Del(fmt.Sprintf("user:%d", user.ID))
 	}
 
-	return err
+	return dbError(err, e.ERROR_USER_CREATE_FAIL, 0, e.ERROR_USER_ALREADY_EXIST)
 }
 
 // Update 更新用户
-func (r *UserRepositoryImpl) Update(user *models.User) error {
-	r.logger.Infof("Updating user ID: %d", user.ID)
+func (r *UserRepositoryImpl) Update(ctx context.Context, user *models.User) error {
+	r.logger.WithContext(ctx).Infof("Updating user ID: %d", user.ID)
 
-	err := user.UpdateUser()
+	err := user.UpdateUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to update user ID %d: %v", user.ID, err)
+		r.logger.WithContext(ctx).Errorf("Failed to update user ID %d: %v", user.ID, err)
 	} else {
-		r.logger.Infof("Successfully updated user ID: %d", user.ID)
+		r.logger.WithContext(ctx).Infof("Successfully updated user ID: %d", user.ID)
 		// 可以在这里清理相关缓存
 		// r.cache.Del(fmt.Sprintf("user:%d", user.ID))
 	}
 
-	return err
+	return dbError(err, e.ERROR_USER_UPDATE_FAIL, e.ERROR_USER_NOT_FOUND, 0)
+}
+
+// Patch 部分更新用户，version 为 0 时不校验版本
+func (r *UserRepositoryImpl) Patch(ctx context.Context, id int, version uint, patch models.UserPatch) (*models.User, error) {
+	r.logger.WithContext(ctx).Infof("Patching user ID: %d, version: %d", id, version)
+
+	user, err := models.PatchUser(ctx, uint(id), version, patch)
+	if err != nil {
+		r.logger.WithContext(ctx).Errorf("Failed to patch user ID %d: %v", id, err)
+		return nil, dbError(err, e.ERROR_USER_UPDATE_FAIL, e.ERROR_USER_NOT_FOUND, 0)
+	}
+	return user, nil
 }
 
 // Delete 删除用户
-func (r *UserRepositoryImpl) Delete(id int) error {
-	r.logger.Infof("Deleting user ID: %d", id)
+func (r *UserRepositoryImpl) Delete(ctx context.Context, id int) error {
+	r.logger.WithContext(ctx).Infof("Deleting user ID: %d", id)
 
 	user := models.User{ID: uint(id)}
-	err := user.DeleteUser()
+	err := user.DeleteUser(ctx)
 
 	if err != nil {
-		r.logger.Errorf("Failed to delete user ID %d: %v", id, err)
+		r.logger.WithContext(ctx).Errorf("Failed to delete user ID %d: %v", id, err)
 	} else {
-		r.logger.Infof("Successfully deleted user ID: %d", id)
+		r.logger.WithContext(ctx).Infof("Successfully deleted user ID: %d", id)
 		// 可以在这里清理相关缓存
 		// r.cache.Del(fmt.Sprintf("user:%d", id))
 	}
 
-	return err
+	return dbError(err, e.ERROR_USER_DELETE_FAIL, e.ERROR_USER_NOT_FOUND, 0)
 }
@@ -0,0 +1,113 @@
+// Package reqctx 在 context.Context 中携带请求级信息（请求ID、操作者），
+// 由 HTTP 中间件写入，沿 controller → service → repository → models/gredis 传递
+package reqctx
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	actorKey
+	localeKey
+)
+
+// Actor 发起请求的操作者
+type Actor struct {
+	UserID   int
+	TenantID int
+	IsSuper  bool
+}
+
+// WithRequestID 写入请求ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID 读取请求ID，不存在时返回空串
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithActor 写入操作者
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFrom 读取操作者
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(actorKey).(Actor)
+	return actor, ok
+}
+
+// WithLocale 写入客户端语言
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// Locale 读取客户端语言，未指定时返回空串
+func Locale(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(localeKey).(string)
+	return locale
+}
+
+// Fields 请求级日志字段：请求ID、trace/span ID 与操作者
+func Fields(ctx context.Context) logrus.Fields {
+	fields := logrus.Fields{}
+	if id := RequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields["trace_id"] = sc.TraceID().String()
+		fields["span_id"] = sc.SpanID().String()
+	}
+	if actor, ok := ActorFrom(ctx); ok {
+		if actor.UserID != 0 {
+			fields["user_id"] = actor.UserID
+		}
+		if actor.TenantID != 0 {
+			fields["tenant_id"] = actor.TenantID
+		}
+		if actor.IsSuper {
+			fields["is_super"] = true
+		}
+	}
+	return fields
+}
+
+// LogHook 为携带 context 的日志条目（logger.WithContext(ctx)）补充请求级字段
+// 需先于 SLS/Zinc 等输出型 Hook 注册，后者才能带上这些字段；条目中已有的同名字段不覆盖
+type LogHook struct{}
+
+// Levels 全部级别
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 写入请求级字段
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for k, v := range Fields(entry.Context) {
+		if _, exists := entry.Data[k]; !exists {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
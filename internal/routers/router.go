@@ -1,29 +1,51 @@
 package routers
 
 import (
+	"net/http"
+	"path"
+	"strings"
+
+	"justus/internal/metrics"
 	"justus/internal/middleware/admin"
 	"justus/internal/middleware/api_require"
 	"justus/internal/middleware/bodyLog"
 	"justus/internal/middleware/cors"
 	"justus/internal/middleware/jwt"
+	"justus/internal/middleware/locale"
 	"justus/internal/middleware/recovers"
+	"justus/internal/middleware/requestid"
+	"justus/internal/middleware/stats"
 	tenantmw "justus/internal/middleware/tenant"
+	"justus/internal/tracing"
 	"justus/internal/wire"
+	"justus/pkg/setting"
+	"justus/pkg/upload"
 
 	"github.com/gin-gonic/gin"
 )
 
 // InitRouterWith 使用依赖注入初始化路由
-func InitRouterWith() (*gin.Engine, error) {
+func InitRouterWith(deps wire.Deps) (*gin.Engine, error) {
 	// 组装依赖
-	app, err := wire.WireApp()
+	app, err := wire.WireApp(deps)
 	if err != nil {
 		return nil, err
 	}
+	return NewRouter(app), nil
+}
 
+// NewRouter 基于已组装的应用上下文注册中间件与路由
+func NewRouter(app *wire.AppContext) *gin.Engine {
 	r := gin.New()
-	// 中间件顺序：Logger -> Recover -> CORS -> BodyLog
-	r.Use(gin.Logger(), recovers.Recover(), cors.Cors(), bodyLog.GinBodyLogMiddleware())
+	// 中间件顺序：RequestID -> Tracing -> Locale -> Logger -> Stats -> Metrics -> Recover -> CORS -> BodyLog
+	// Tracing 紧随 RequestID，使后续全部中间件与 GORM/Redis 调用都落在请求 span 内
+	// Locale 需早于任何可能直接响应的中间件，保证错误提示也按客户端语言输出
+	// Stats/Metrics 位于 Recover 之外，才能统计到 panic 后被标记为 500 的请求
+	r.Use(requestid.RequestID(), tracing.Middleware(), locale.Locale(), gin.Logger(), stats.Stats())
+	if setting.MetricsSetting.Enabled {
+		r.Use(metrics.Middleware())
+	}
+	r.Use(recovers.Recover(), cors.Cors(), bodyLog.GinBodyLogMiddleware())
 
 	// 健康检查接口（无需认证）
 	r.GET("/health", app.HealthController.Health)
@@ -31,10 +53,29 @@ func InitRouterWith() (*gin.Engine, error) {
 	r.GET("/ready", app.HealthController.Readiness)
 	r.GET("/live", app.HealthController.Liveness)
 
+	// Prometheus 指标（受 Token/IP 白名单保护，不对外公开）
+	if setting.MetricsSetting.Enabled {
+		r.GET(setting.MetricsSetting.Path, metrics.Protect(), metrics.Handler())
+	}
+
+	// 导出文件、私有上传与二维码：凭签名链接下载（地址见 signurl.URL），过期或被篡改的链接拒绝访问
+	r.GET("/files/*path", app.FileController.Serve)
+	r.HEAD("/files/*path", app.FileController.Serve)
+
+	// 本地存储的公开上传文件（存储键由内容 MD5 生成，地址见 upload.Storage.URL），私有文件不走静态目录
+	if local, ok := app.Container.Storage.(*upload.LocalStorage); ok {
+		if path := strings.Trim(setting.AppSetting.ImageSavePath, "/"); path != "" {
+			r.Group("/"+path, denyPrivateUploads()).Static("/", local.Root())
+		}
+	}
+	// 图片变体（缩略图等），不存在时按 upload.Image.Variants 从原图生成后重定向
+	r.GET("/variants/:variant/*key", app.ImageController.Variant)
+
 	// API模块路由组
 	apiGroup := r.Group("/api/v1")
 	// apiGroup.Use(api_require.Common())
 	apiGroup.Use(jwt.JWT())
+	apiGroup.Use(locale.UserFallback(app.Container.UserService.GetUserLang))
 	{
 		apiGroup.Any("/test", app.TestController.Test)
 
@@ -42,10 +83,19 @@ func InitRouterWith() (*gin.Engine, error) {
 		apiGroup.GET("/users/:id", app.UserController.GetUser)
 		apiGroup.POST("/users", app.UserController.CreateUser)
 		apiGroup.PUT("/users/:id", app.UserController.UpdateUser)
+		apiGroup.PATCH("/users/:id", app.UserController.PatchUser)
 		apiGroup.DELETE("/users/:id", app.UserController.DeleteUser)
 
 		apiGroup.GET("/profile", app.UserController.GetProfile)
 		apiGroup.PUT("/profile", app.UserController.UpdateProfile)
+		apiGroup.PATCH("/profile", app.UserController.PatchProfile)
+	}
+
+	// 接受管理员邀请（凭邮件令牌，无需登录）
+	invitationGroup := r.Group("/admin/v1/invitations")
+	invitationGroup.Use(api_require.Common())
+	{
+		invitationGroup.POST("/accept", app.AdminUserController.AcceptInvitation)
 	}
 
 	// Admin模块路由组 - 面向管理员
@@ -62,10 +112,60 @@ func InitRouterWith() (*gin.Engine, error) {
 			userMgmt.GET("/:id", app.UserManagementController.GetUser)
 			userMgmt.POST("", app.UserManagementController.CreateUser)
 			userMgmt.PUT("/:id", app.UserManagementController.UpdateUser)
+			userMgmt.PATCH("/:id", app.UserManagementController.PatchUser)
 			userMgmt.DELETE("/:id", app.UserManagementController.DeleteUser)
 			userMgmt.PUT("/:id/status", app.UserManagementController.UpdateUserStatus)
 		}
 
+		// 管理员账号
+		adminUserMgmt := adminGroup.Group("/admin-users")
+		{
+			adminUserMgmt.GET("", app.AdminUserController.GetAdminUsers)
+			adminUserMgmt.GET("/:id", app.AdminUserController.GetAdminUser)
+			adminUserMgmt.POST("", app.AdminUserController.CreateAdminUser)
+			adminUserMgmt.PUT("/:id", app.AdminUserController.UpdateAdminUser)
+			adminUserMgmt.PUT("/:id/status", app.AdminUserController.UpdateAdminUserStatus)
+			adminUserMgmt.DELETE("/:id", app.AdminUserController.DeleteAdminUser)
+
+			adminUserMgmt.GET("/invitations", app.AdminUserController.GetInvitations)
+			adminUserMgmt.POST("/invitations", app.AdminUserController.InviteAdminUser)
+			adminUserMgmt.DELETE("/invitations/:id", app.AdminUserController.RevokeInvitation)
+
+			adminUserMgmt.POST("/bulk/status", app.AdminUserController.BulkUpdateAdminUserStatus)
+			adminUserMgmt.POST("/bulk/roles", app.AdminUserController.BulkAssignRoles)
+			adminUserMgmt.POST("/bulk/delete", app.AdminUserController.BulkDeleteAdminUsers)
+		}
+
+		// 审计日志
+		adminGroup.GET("/audit-logs", app.AuditLogController.GetAuditLogs)
+
+		// 导入导出（后台任务）
+		adminGroup.POST("/exports/:resource", app.DataJobController.Export)
+		adminGroup.POST("/imports/:resource", app.DataJobController.Import)
+		adminGroup.GET("/data-jobs", app.DataJobController.GetDataJobs)
+		adminGroup.GET("/data-jobs/:id", app.DataJobController.GetDataJob)
+
+		// 文件上传
+		uploadMgmt := adminGroup.Group("/uploads")
+		{
+			uploadMgmt.POST("", app.UploadController.Upload)
+			uploadMgmt.GET("", app.UploadController.GetUploads)
+			uploadMgmt.GET("/usage", app.UploadController.GetUploadUsage)
+			uploadMgmt.GET("/:id", app.UploadController.GetUpload)
+			uploadMgmt.DELETE("/:id", app.UploadController.DeleteUpload)
+		}
+
+		// 二维码（PNG/SVG/JPG，可叠加上传图片作为 Logo）
+		adminGroup.GET("/qrcodes", app.QrCodeController.Generate)
+
+		// 回收站
+		trashMgmt := adminGroup.Group("/trash")
+		{
+			trashMgmt.GET("/:resource", app.TrashController.GetTrash)
+			trashMgmt.POST("/:resource/:id/restore", app.TrashController.RestoreTrash)
+			trashMgmt.DELETE("/:resource/:id", app.TrashController.PurgeTrash)
+		}
+
 		// 系统管理
 		systemMgmt := adminGroup.Group("/system")
 		{
@@ -84,10 +184,12 @@ func InitRouterWith() (*gin.Engine, error) {
 			roleMgmt.GET("/:id", app.RoleController.GetRole)
 			roleMgmt.POST("", app.RoleController.CreateRole)
 			roleMgmt.PUT("/:id", app.RoleController.UpdateRole)
+			roleMgmt.PATCH("/:id", app.RoleController.PatchRole)
 			roleMgmt.DELETE("/:id", app.RoleController.DeleteRole)
 			roleMgmt.PUT("/:id/permissions", app.RoleController.UpdateRolePermissions)
 
 			roleMgmt.GET("/permissions", app.RoleController.GetPermissions)
+			roleMgmt.POST("/permissions", app.RoleController.CreatePermission)
 			roleMgmt.POST("/assign", app.RoleController.AssignRole)
 		}
 
@@ -98,9 +200,13 @@ func InitRouterWith() (*gin.Engine, error) {
 			menuMgmt.GET("/vben", app.MenuController.GetMyMenusVben)
 		}
 
-		// 超级管理员配置租户菜单白名单
+		// 超级管理员维护租户资料与菜单白名单
 		tenantMenuMgmt := adminGroup.Group("/tenants")
 		{
+			tenantMenuMgmt.GET(":id", app.TenantController.GetTenant)
+			tenantMenuMgmt.PUT(":id", app.TenantController.UpdateTenant)
+			tenantMenuMgmt.PATCH(":id", app.TenantController.PatchTenant)
+			tenantMenuMgmt.DELETE(":id", app.TenantController.DeleteTenant)
 			tenantMenuMgmt.GET(":id/menus", app.MenuController.GetTenantMenus)
 			tenantMenuMgmt.PUT(":id/menus", app.MenuController.UpdateTenantMenus)
 		}
@@ -122,5 +228,17 @@ func InitRouterWith() (*gin.Engine, error) {
 	r.NoRoute(func(c *gin.Context) { c.JSON(404, gin.H{"code": 404, "msg": "not found"}) })
 	r.NoMethod(func(c *gin.Context) { c.JSON(405, gin.H{"code": 405, "msg": "method not allowed"}) })
 
-	return r, nil
+	return r
+}
+
+// denyPrivateUploads 静态目录下的私有文件（upload.PrivatePrefix）按不存在处理，只能通过签名链接访问
+func denyPrivateUploads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 先规整路径，防止 /uploads/images/../files/… 绕过
+		if upload.IsPrivate(strings.TrimPrefix(path.Clean(c.Param("filepath")), "/")) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Next()
+	}
 }
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/reqctx"
+	"justus/pkg/e"
+	"justus/pkg/setting"
+	"justus/pkg/util"
+)
+
+// defaultInviteTTL 未加载配置时的邀请有效期
+const defaultInviteTTL = 72 * time.Hour
+
+// AdminInvitationServiceImpl 管理员邀请服务实现
+type AdminInvitationServiceImpl struct {
+	invitationRepo container.AdminInvitationRepository
+	adminUserRepo  container.AdminUserRepository
+	roleRepo       container.RoleRepository
+	tenantRepo     container.TenantRepository
+	menuService    container.MenuService
+	mailer         container.Mailer
+	logger         container.Logger
+	cache          container.Cache
+}
+
+// NewAdminInvitationService 创建管理员邀请服务实例
+func NewAdminInvitationService(invitationRepo container.AdminInvitationRepository, adminUserRepo container.AdminUserRepository, roleRepo container.RoleRepository, tenantRepo container.TenantRepository, menuService container.MenuService, mailer container.Mailer, logger container.Logger, cache container.Cache) container.AdminInvitationService {
+	return &AdminInvitationServiceImpl{
+		invitationRepo: invitationRepo,
+		adminUserRepo:  adminUserRepo,
+		roleRepo:       roleRepo,
+		tenantRepo:     tenantRepo,
+		menuService:    menuService,
+		mailer:         mailer,
+		logger:         logger,
+		cache:          cache,
+	}
+}
+
+// Invite 生成邀请并发送邮件；令牌明文只出现在邮件链接中，库里保存其 SHA-256
+func (s *AdminInvitationServiceImpl) Invite(ctx context.Context, tenantID uint, in container.InvitationInput) (*models.AdminInvitation, error) {
+	if err := checkTenantRoles(ctx, s.roleRepo, tenantID, in.RoleIDs); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, e.Internal(err)
+	}
+	ttl := defaultInviteTTL
+	if setting.MailSetting != nil && setting.MailSetting.InviteTTL > 0 {
+		ttl = setting.MailSetting.InviteTTL
+	}
+	actor, _ := reqctx.ActorFrom(ctx)
+	inv := &models.AdminInvitation{
+		TenantID:   tenantID,
+		Email:      strings.ToLower(strings.TrimSpace(in.Email)),
+		TokenHash:  hashInviteToken(token),
+		Department: in.Department,
+		Position:   in.Position,
+		Status:     models.InvitationPending,
+		InvitedBy:  uint(actor.UserID),
+		ExpiresAt:  models.GormTime{Time: time.Now().Add(ttl)},
+	}
+	inv.SetRoles(in.RoleIDs)
+	if err := s.invitationRepo.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	msg := container.MailMessage{
+		To:      []string{inv.Email},
+		Subject: fmt.Sprintf("邀请加入 %s 管理后台", tenant.Name),
+		Body: fmt.Sprintf("您被邀请加入 %s 的管理后台。\n\n请在 %s 前打开以下链接完成注册：\n%s\n\n如非本人操作请忽略此邮件。",
+			tenant.Name, inv.ExpiresAt.Format("2006-01-02 15:04"), inviteLink(token)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		// 邮件失败时撤销邀请，避免留下无人知晓令牌的待接受记录
+		_ = s.invitationRepo.Revoke(ctx, inv.ID, tenantID)
+		return nil, e.Wrap(e.ERROR, err)
+	}
+
+	s.logger.WithContext(ctx).Infof("AdminInvitationService: invitation %d sent to %s for tenant %d", inv.ID, inv.Email, tenantID)
+	return inv, nil
+}
+
+// ListPending 租户内待接受的邀请
+func (s *AdminInvitationServiceImpl) ListPending(ctx context.Context, tenantID uint) ([]models.AdminInvitation, error) {
+	return s.invitationRepo.ListPending(ctx, tenantID)
+}
+
+// Revoke 撤销邀请
+func (s *AdminInvitationServiceImpl) Revoke(ctx context.Context, tenantID, id uint) error {
+	return s.invitationRepo.Revoke(ctx, id, tenantID)
+}
+
+// Accept 接受邀请：邮箱已有账号时校验其密码并追加租户角色，否则用提交的资料创建账号
+func (s *AdminInvitationServiceImpl) Accept(ctx context.Context, in container.AcceptInvitationInput) (*models.AdminUser, error) {
+	inv, err := s.invitationRepo.GetByTokenHash(ctx, hashInviteToken(in.Token))
+	if err != nil {
+		return nil, err
+	}
+	if !inv.Usable(time.Now()) {
+		return nil, e.New(e.ERROR_INVITATION_INVALID)
+	}
+
+	admin, err := s.adminUserRepo.GetByEmail(ctx, inv.Email)
+	switch {
+	case err == nil:
+		if !util.CheckPassword(admin.Password, in.Password) {
+			return nil, e.New(e.ERROR_AUTH)
+		}
+	case errors.Is(err, e.New(e.ERROR_ADMIN_NOT_FOUND)):
+		if in.Username == "" {
+			return nil, e.Validation(e.FieldError{Field: "username", Rule: "required", Message: "新账号需要填写用户名"})
+		}
+		hashed, err := util.EncryptPassword(in.Password)
+		if err != nil {
+			return nil, e.Wrap(e.ERROR_ADMIN_CREATE_FAIL, err)
+		}
+		admin = &models.AdminUser{
+			Username:   in.Username,
+			Password:   hashed,
+			Email:      inv.Email,
+			RealName:   in.RealName,
+			Department: inv.Department,
+			Position:   inv.Position,
+			Status:     1,
+			CreatedBy:  inv.InvitedBy,
+		}
+	default:
+		return nil, err
+	}
+
+	if err := s.invitationRepo.Accept(ctx, inv, admin); err != nil {
+		return nil, err
+	}
+	invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, inv.TenantID)
+
+	s.logger.WithContext(ctx).Infof("AdminInvitationService: invitation %d accepted by admin %d", inv.ID, admin.ID)
+	return admin, nil
+}
+
+// newInviteToken 32 字节随机令牌，base64url 编码
+func newInviteToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashInviteToken 令牌入库前取 SHA-256
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// inviteLink 邀请落地页链接
+func inviteLink(token string) string {
+	base := ""
+	if setting.MailSetting != nil {
+		base = setting.MailSetting.InviteURL
+	}
+	if base == "" && setting.AppSetting != nil {
+		base = strings.TrimRight(setting.AppSetting.PrefixUrl, "/") + "/admin/invite"
+	}
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
@@ -1,107 +1,419 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"justus/internal/container"
 	"justus/internal/models"
+	"justus/internal/reqctx"
+	"justus/pkg/e"
+	"justus/pkg/query"
+	"justus/pkg/rediskey"
+	"justus/pkg/util"
+	"time"
 )
 
 // AdminUserServiceImpl 管理员用户服务实现
 type AdminUserServiceImpl struct {
 	adminUserRepo container.AdminUserRepository
+	roleRepo      container.RoleRepository
+	tenantRepo    container.TenantRepository
+	menuService   container.MenuService
+	auditRepo     container.AuditLogRepository
 	logger        container.Logger
 	cache         container.Cache
 }
 
 // NewAdminUserService 创建管理员用户服务实例
-func NewAdminUserService(adminUserRepo container.AdminUserRepository, logger container.Logger, cache container.Cache) container.AdminUserService {
+func NewAdminUserService(adminUserRepo container.AdminUserRepository, roleRepo container.RoleRepository, tenantRepo container.TenantRepository, menuService container.MenuService, auditRepo container.AuditLogRepository, logger container.Logger, cache container.Cache) container.AdminUserService {
 	return &AdminUserServiceImpl{
 		adminUserRepo: adminUserRepo,
+		roleRepo:      roleRepo,
+		tenantRepo:    tenantRepo,
+		menuService:   menuService,
+		auditRepo:     auditRepo,
 		logger:        logger,
 		cache:         cache,
 	}
 }
 
 // GetAdminUserInfo 获取管理员用户信息
-func (s *AdminUserServiceImpl) GetAdminUserInfo(id int) (*models.AdminUser, error) {
-	s.logger.Infof("AdminUserService: Getting admin user info for ID: %d", id)
+func (s *AdminUserServiceImpl) GetAdminUserInfo(ctx context.Context, id int) (*models.AdminUser, error) {
+	s.logger.WithContext(ctx).Infof("AdminUserService: Getting admin user info for ID: %d", id)
 
-	user, err := s.adminUserRepo.GetByID(id)
+	user, err := s.adminUserRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Errorf("AdminUserService: Failed to get admin user info for ID %d: %v", id, err)
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to get admin user info for ID %d: %v", id, err)
 		return nil, err
 	}
 
-	s.logger.Debugf("AdminUserService: Successfully retrieved admin user info for ID: %d", id)
+	s.logger.WithContext(ctx).Debugf("AdminUserService: Successfully retrieved admin user info for ID: %d", id)
 	return user, nil
 }
 
 // GetByUsername 根据用户名获取管理员用户信息
-func (s *AdminUserServiceImpl) GetByUsername(username string) (*models.AdminUser, error) {
-	s.logger.Infof("AdminUserService: Getting admin user by username: %s", username)
+func (s *AdminUserServiceImpl) GetByUsername(ctx context.Context, username string) (*models.AdminUser, error) {
+	s.logger.WithContext(ctx).Infof("AdminUserService: Getting admin user by username: %s", username)
 
-	user, err := s.adminUserRepo.GetByUsername(username)
+	user, err := s.adminUserRepo.GetByUsername(ctx, username)
 	if err != nil {
-		s.logger.Errorf("AdminUserService: Failed to get admin user by username %s: %v", username, err)
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to get admin user by username %s: %v", username, err)
 		return nil, err
 	}
 
-	s.logger.Debugf("AdminUserService: Successfully retrieved admin user by username: %s", username)
+	s.logger.WithContext(ctx).Debugf("AdminUserService: Successfully retrieved admin user by username: %s", username)
 	return user, nil
 }
 
+// ListAdminUsers 获取租户内的管理员列表
+func (s *AdminUserServiceImpl) ListAdminUsers(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[*models.AdminUser], error) {
+	result, err := s.adminUserRepo.ListByTenant(ctx, tenantID, spec)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to list admin users for tenant %d: %v", tenantID, err)
+		return nil, err
+	}
+	return result, nil
+}
+
 // CreateAdminUser 创建管理员用户
-func (s *AdminUserServiceImpl) CreateAdminUser(user *models.AdminUser) error {
-	s.logger.Infof("AdminUserService: Creating admin user: %s", user.Username)
+func (s *AdminUserServiceImpl) CreateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	s.logger.WithContext(ctx).Infof("AdminUserService: Creating admin user: %s", user.Username)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证管理员权限
 	// - 密码加密
 	// - 记录创建日志等
 
-	err := s.adminUserRepo.Create(user)
+	err := s.adminUserRepo.Create(ctx, user)
 	if err != nil {
-		s.logger.Errorf("AdminUserService: Failed to create admin user: %v", err)
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to create admin user: %v", err)
 		return err
 	}
 
-	s.logger.Infof("AdminUserService: Admin user created successfully with ID: %d", user.ID)
+	s.logger.WithContext(ctx).Infof("AdminUserService: Admin user created successfully with ID: %d", user.ID)
 	return nil
 }
 
 // UpdateAdminUser 更新管理员用户
-func (s *AdminUserServiceImpl) UpdateAdminUser(user *models.AdminUser) error {
-	s.logger.Infof("AdminUserService: Updating admin user ID: %d", user.ID)
+func (s *AdminUserServiceImpl) UpdateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	s.logger.WithContext(ctx).Infof("AdminUserService: Updating admin user ID: %d", user.ID)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证更新权限
 	// - 数据验证
 	// - 记录更新日志等
 
-	err := s.adminUserRepo.Update(user)
+	err := s.adminUserRepo.Update(ctx, user)
 	if err != nil {
-		s.logger.Errorf("AdminUserService: Failed to update admin user ID %d: %v", user.ID, err)
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to update admin user ID %d: %v", user.ID, err)
 		return err
 	}
 
-	s.logger.Infof("AdminUserService: Admin user ID %d updated successfully", user.ID)
+	s.logger.WithContext(ctx).Infof("AdminUserService: Admin user ID %d updated successfully", user.ID)
 	return nil
 }
 
 // DeleteAdminUser 删除管理员用户
-func (s *AdminUserServiceImpl) DeleteAdminUser(id int) error {
-	s.logger.Infof("AdminUserService: Deleting admin user ID: %d", id)
+func (s *AdminUserServiceImpl) DeleteAdminUser(ctx context.Context, id int) error {
+	s.logger.WithContext(ctx).Infof("AdminUserService: Deleting admin user ID: %d", id)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证删除权限
 	// - 记录删除日志
 	// - 清理相关数据等
 
-	err := s.adminUserRepo.Delete(id)
+	err := s.adminUserRepo.Delete(ctx, id)
 	if err != nil {
-		s.logger.Errorf("AdminUserService: Failed to delete admin user ID %d: %v", id, err)
+		s.logger.WithContext(ctx).Errorf("AdminUserService: Failed to delete admin user ID %d: %v", id, err)
 		return err
 	}
 
-	s.logger.Infof("AdminUserService: Admin user ID %d deleted successfully", id)
+	s.logger.WithContext(ctx).Infof("AdminUserService: Admin user ID %d deleted successfully", id)
 	return nil
 }
+
+// GetAdminUserInTenant 获取租户内的管理员
+func (s *AdminUserServiceImpl) GetAdminUserInTenant(ctx context.Context, tenantID, id uint) (*models.AdminUser, error) {
+	return s.adminUserRepo.GetInTenant(ctx, id, tenantID)
+}
+
+// CreateAdminUserInTenant 创建管理员并绑定租户角色
+func (s *AdminUserServiceImpl) CreateAdminUserInTenant(ctx context.Context, tenantID uint, in container.AdminUserInput) (*models.AdminUser, error) {
+	if err := checkTenantRoles(ctx, s.roleRepo, tenantID, in.RoleIDs); err != nil {
+		return nil, err
+	}
+	hashed, err := util.EncryptPassword(in.Password)
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_ADMIN_CREATE_FAIL, err)
+	}
+	actor, _ := reqctx.ActorFrom(ctx)
+	user := &models.AdminUser{
+		Username:   in.Username,
+		Password:   hashed,
+		Email:      in.Email,
+		Phone:      in.Phone,
+		RealName:   in.RealName,
+		Department: in.Department,
+		Position:   in.Position,
+		Avatar:     in.Avatar,
+		Status:     1,
+		CreatedBy:  uint(actor.UserID),
+	}
+	if err := s.adminUserRepo.CreateInTenant(ctx, user, tenantID, in.RoleIDs); err != nil {
+		return nil, err
+	}
+	invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, tenantID)
+
+	s.logger.WithContext(ctx).Infof("AdminUserService: admin user %d created in tenant %d", user.ID, tenantID)
+	return user, nil
+}
+
+// UpdateAdminUserInTenant 更新租户内管理员资料，可选重置密码与租户角色
+func (s *AdminUserServiceImpl) UpdateAdminUserInTenant(ctx context.Context, tenantID, id uint, in container.AdminUserInput) (*models.AdminUser, error) {
+	user, err := s.adminUserRepo.GetInTenant(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	actor, _ := reqctx.ActorFrom(ctx)
+	if user.IsSuper && !actor.IsSuper {
+		return nil, e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+	}
+	// 密码与邮箱属于账号本身，账号还属于其他租户时只有超级管理员可以修改
+	if !actor.IsSuper && (in.Password != "" || in.Email != user.Email) {
+		other, err := s.adminUserRepo.InOtherTenants(ctx, id, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if other {
+			return nil, e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+		}
+	}
+	if in.RoleIDs != nil {
+		if err := checkTenantRoles(ctx, s.roleRepo, tenantID, in.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Email = in.Email
+	user.Phone = in.Phone
+	user.RealName = in.RealName
+	user.Department = in.Department
+	user.Position = in.Position
+	user.Avatar = in.Avatar
+	if in.Password != "" {
+		hashed, err := util.EncryptPassword(in.Password)
+		if err != nil {
+			return nil, e.Wrap(e.ERROR_ADMIN_UPDATE_FAIL, err)
+		}
+		user.Password = hashed
+		user.PasswordChangedAt = &models.GormTime{Time: time.Now()}
+	}
+	if err := s.adminUserRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if in.RoleIDs != nil {
+		if err := s.roleRepo.AssignToAdminInTenant(ctx, user.ID, tenantID, in.RoleIDs); err != nil {
+			return nil, err
+		}
+		invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, tenantID)
+	}
+	return user, nil
+}
+
+// UpdateAdminUserStatus 启用/禁用/锁定管理员在租户内的成员资格；禁用与锁定受保护规则约束
+// 只修改成员状态，账号在其他租户的访问不受影响
+func (s *AdminUserServiceImpl) UpdateAdminUserStatus(ctx context.Context, tenantID, id uint, status int) error {
+	user, err := s.adminUserRepo.GetInTenant(ctx, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if status != 1 {
+		if err := s.guardRemoval(ctx, tenantID, user); err != nil {
+			return err
+		}
+	} else if actor, _ := reqctx.ActorFrom(ctx); user.IsSuper && !actor.IsSuper {
+		return e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+	}
+	if err := s.adminUserRepo.SetMemberStatus(ctx, id, tenantID, status); err != nil {
+		return err
+	}
+	invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, tenantID)
+	return nil
+}
+
+// RemoveAdminUserFromTenant 将管理员移出租户，不再属于任何租户时删除账号
+func (s *AdminUserServiceImpl) RemoveAdminUserFromTenant(ctx context.Context, tenantID, id uint) (bool, error) {
+	user, err := s.adminUserRepo.GetInTenant(ctx, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+	if err := s.guardRemoval(ctx, tenantID, user); err != nil {
+		return false, err
+	}
+	deleted, err := s.adminUserRepo.RemoveFromTenant(ctx, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+	invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, tenantID)
+
+	s.logger.WithContext(ctx).Infof("AdminUserService: admin user %d removed from tenant %d, account_deleted=%t", id, tenantID, deleted)
+	return deleted, nil
+}
+
+// BulkUpdateAdminUserStatus 批量启用/禁用/锁定租户内管理员，逐条应用单条接口的保护规则
+func (s *AdminUserServiceImpl) BulkUpdateAdminUserStatus(ctx context.Context, tenantID uint, ids []uint, status int) []container.BulkResult {
+	results := bulkEach(ids, func(id uint) error {
+		return s.UpdateAdminUserStatus(ctx, tenantID, id, status)
+	})
+	s.audit(ctx, tenantID, models.AuditAdminUserStatus, results, map[string]interface{}{"status": status})
+	return results
+}
+
+// BulkAssignRoles 批量覆盖式设置管理员在租户内的角色
+func (s *AdminUserServiceImpl) BulkAssignRoles(ctx context.Context, tenantID uint, ids, roleIDs []uint) ([]container.BulkResult, error) {
+	if err := checkTenantRoles(ctx, s.roleRepo, tenantID, roleIDs); err != nil {
+		return nil, err
+	}
+	changed := false
+	results := bulkEach(ids, func(id uint) error {
+		// 与 UpdateAdminUserInTenant 调整角色时的校验一致
+		user, err := s.adminUserRepo.GetInTenant(ctx, id, tenantID)
+		if err != nil {
+			return err
+		}
+		if actor, _ := reqctx.ActorFrom(ctx); user.IsSuper && !actor.IsSuper {
+			return e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+		}
+		if err := s.roleRepo.AssignToAdminInTenant(ctx, id, tenantID, roleIDs); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if changed {
+		invalidateTenantAuthz(ctx, s.cache, s.menuService, s.logger, tenantID)
+	}
+	s.audit(ctx, tenantID, models.AuditAdminUserRoles, results, map[string]interface{}{"role_ids": roleIDs})
+	return results, nil
+}
+
+// BulkRemoveAdminUsersFromTenant 批量将管理员移出租户
+func (s *AdminUserServiceImpl) BulkRemoveAdminUsersFromTenant(ctx context.Context, tenantID uint, ids []uint) []container.BulkResult {
+	results := bulkEach(ids, func(id uint) error {
+		_, err := s.RemoveAdminUserFromTenant(ctx, tenantID, id)
+		return err
+	})
+	s.audit(ctx, tenantID, models.AuditAdminUserRemove, results, nil)
+	return results
+}
+
+// audit 批量操作每条记录写一条审计日志；写入失败只记日志，不影响已完成的操作
+func (s *AdminUserServiceImpl) audit(ctx context.Context, tenantID uint, action string, results []container.BulkResult, detail map[string]interface{}) {
+	if s.auditRepo == nil || len(results) == 0 {
+		return
+	}
+	var raw []byte
+	if detail != nil {
+		raw, _ = json.Marshal(detail)
+	}
+	actor, _ := reqctx.ActorFrom(ctx)
+	batchID := newBatchID()
+	logs := make([]models.AuditLog, 0, len(results))
+	for _, r := range results {
+		code := e.SUCCESS
+		if r.Err != nil {
+			code = e.FromError(r.Err).Code
+		}
+		logs = append(logs, models.AuditLog{
+			TenantID:   tenantID,
+			ActorID:    uint(actor.UserID),
+			Action:     action,
+			TargetType: "admin_user",
+			TargetID:   r.ID,
+			BatchID:    batchID,
+			Success:    r.Err == nil,
+			Code:       code,
+			Detail:     string(raw),
+			RequestID:  reqctx.RequestID(ctx),
+		})
+	}
+	if err := s.auditRepo.Create(ctx, logs); err != nil {
+		s.logger.WithContext(ctx).Errorf("AdminUserService: write audit logs for %s failed: %v", action, err)
+	}
+}
+
+// bulkEach 按顺序逐条执行，重复的 ID 只处理一次
+func bulkEach(ids []uint, fn func(id uint) error) []container.BulkResult {
+	seen := make(map[uint]struct{}, len(ids))
+	results := make([]container.BulkResult, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		results = append(results, container.BulkResult{ID: id, Err: fn(id)})
+	}
+	return results
+}
+
+// newBatchID 批次号，关联同一次批量操作的审计日志
+func newBatchID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// guardRemoval 移出或禁用前的保护：不能操作自己与租户所有者，非超级管理员不能操作超级管理员；
+// 最后一个启用的超级管理员由 RemoveFromTenant 在删除账号的同一事务内加锁校验
+func (s *AdminUserServiceImpl) guardRemoval(ctx context.Context, tenantID uint, user *models.AdminUser) error {
+	actor, _ := reqctx.ActorFrom(ctx)
+	if actor.UserID != 0 && uint(actor.UserID) == user.ID {
+		return e.New(e.ERROR_ADMIN_SELF_OPERATION)
+	}
+	if user.IsSuper && !actor.IsSuper {
+		return e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+	}
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant.OwnerUserID != 0 && tenant.OwnerUserID == user.ID {
+		return e.New(e.ERROR_ADMIN_OWNER_PROTECT)
+	}
+	return nil
+}
+
+// checkTenantRoles 校验角色均属于当前租户或为系统级角色
+func checkTenantRoles(ctx context.Context, roleRepo container.RoleRepository, tenantID uint, roleIDs []uint) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	ids := make([]int, len(roleIDs))
+	for i, id := range roleIDs {
+		ids[i] = int(id)
+	}
+	roles, err := roleRepo.GetByIDsAndTenant(ctx, ids, tenantID)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(roleIDs) {
+		return e.Validation(e.FieldError{Field: "role_ids", Rule: "exists", Message: "包含不存在或不属于当前租户的角色"})
+	}
+	return nil
+}
+
+// invalidateTenantAuthz 成员或角色变化后失效租户权限码缓存与菜单树缓存
+func invalidateTenantAuthz(ctx context.Context, cache container.Cache, menus container.MenuService, logger container.Logger, tenantID uint) {
+	if cache != nil {
+		if _, err := cache.DelByPattern(ctx, rediskey.TenantPermsPattern(tenantID)); err != nil {
+			logger.WithContext(ctx).Errorf("purge tenant perms cache error: tenant_id=%d, err=%v", tenantID, err)
+		}
+	}
+	if menus != nil {
+		menus.InvalidateTenant(ctx, tenantID)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"justus/internal/container"
+	"justus/pkg/e"
+	"justus/pkg/rediskey"
+)
+
+// 缓存分类
+const (
+	CacheCategoryMenus       = "menus"
+	CacheCategoryPermissions = "permissions"
+	CacheCategoryTenant      = "tenant"
+	CacheCategoryUser        = "user"
+	CacheCategoryAll         = "all"
+)
+
+var (
+	// ErrUnknownCacheCategory 未知的缓存分类
+	ErrUnknownCacheCategory = e.Validation(e.FieldError{Field: "type", Rule: "oneof", Param: "menus permissions tenant user all", Message: "必须是以下值之一: menus permissions tenant user all"})
+	// ErrCacheCategoryPlatformOnly 平台级分类不允许按租户清理
+	ErrCacheCategoryPlatformOnly = e.Forbidden(e.ERROR_PERMISSION_DENIED).WithCause(errors.New("cache category is platform level"))
+)
+
+// CacheServiceImpl 缓存管理服务实现
+type CacheServiceImpl struct {
+	cache  container.Cache
+	logger container.Logger
+}
+
+// NewCacheService 创建缓存管理服务实例
+func NewCacheService(cache container.Cache, logger container.Logger) container.CacheService {
+	return &CacheServiceImpl{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Clear 按分类清理缓存，返回每个命名空间删除的key数量
+// scope.AllTenants 为 true 时清理全部租户（仅平台超级管理员），否则只清理 scope.TenantID
+func (s *CacheServiceImpl) Clear(ctx context.Context, category string, scope container.CacheScope) (map[string]int64, error) {
+	patterns, err := cachePatterns(category, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]int64, len(patterns))
+	for _, pattern := range patterns {
+		n, err := s.cache.DelByPattern(ctx, pattern)
+		if err != nil {
+			s.logger.WithContext(ctx).Errorf("CacheService: Failed to clear cache pattern %s: %v", pattern, err)
+			return removed, e.Wrap(e.ERROR_CACHE_DEL, err)
+		}
+		removed[pattern] = n
+	}
+
+	s.logger.WithContext(ctx).Infof("CacheService: Cache cleared - category: %s, tenant_id: %d, all_tenants: %v, removed: %v",
+		category, scope.TenantID, scope.AllTenants, removed)
+	return removed, nil
+}
+
+// cachePatterns 将缓存分类映射为 rediskey 命名空间
+// 菜单世代号不在任何分类内：删除会使其归零，旧世代的菜单树可能重新生效
+func cachePatterns(category string, scope container.CacheScope) ([]string, error) {
+	switch category {
+	case CacheCategoryMenus:
+		return menuPatterns(scope), nil
+	case CacheCategoryPermissions:
+		return permPatterns(scope), nil
+	case CacheCategoryTenant:
+		return append(menuPatterns(scope), permPatterns(scope)...), nil
+	case CacheCategoryUser:
+		// 普通用户缓存（如语言偏好）为平台级数据，不属于任何租户
+		if !scope.AllTenants {
+			return nil, ErrCacheCategoryPlatformOnly
+		}
+		return []string{rediskey.UserPattern()}, nil
+	case CacheCategoryAll:
+		patterns := append(menuPatterns(scope), permPatterns(scope)...)
+		if scope.AllTenants {
+			patterns = append(patterns, rediskey.UserPattern())
+		}
+		return patterns, nil
+	default:
+		return nil, ErrUnknownCacheCategory
+	}
+}
+
+// menuPatterns 菜单白名单与菜单树
+func menuPatterns(scope container.CacheScope) []string {
+	if scope.AllTenants {
+		return []string{rediskey.AllTenantsMenuWhitelistPattern(), rediskey.AllTenantsMenuTreePattern()}
+	}
+	return []string{rediskey.TenantMenuWhitelistKey(scope.TenantID), rediskey.TenantMenuTreePattern(scope.TenantID)}
+}
+
+// permPatterns 权限码缓存
+func permPatterns(scope container.CacheScope) []string {
+	if scope.AllTenants {
+		return []string{rediskey.AllTenantsModulePattern("perms")}
+	}
+	return []string{rediskey.TenantPermsPattern(scope.TenantID)}
+}
@@ -0,0 +1,635 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/reqctx"
+	"justus/pkg/app"
+	"justus/pkg/e"
+	"justus/pkg/export"
+	"justus/pkg/query"
+)
+
+const (
+	// dataJobWorkers 同时执行的导入导出任务数，其余任务排队
+	dataJobWorkers = 2
+	// maxActiveDataJobs 每个租户排队中与执行中的任务上限
+	maxActiveDataJobs = 5
+	// importMaxRows 单次导入的数据行上限（不含表头）
+	importMaxRows = 10000
+	// progressEvery 每处理多少行回写一次进度
+	progressEvery = 500
+)
+
+// errDataJobStopped 服务停止时仍在排队的任务
+var errDataJobStopped = errors.New("服务停止，任务未执行")
+
+// staleDataJobReason 启动时发现的未完成任务的失败原因
+const staleDataJobReason = "服务重启，任务已中断"
+
+// exportSource 可导出的列表：与列表接口共用 schema，过滤、排序与 fields 参数含义一致
+type exportSource struct {
+	schema *query.Schema
+	// columns 未指定 fields 时导出的列，顺序即表头顺序
+	columns []string
+	// fetch 按 spec 查询一页，items 为可 JSON 序列化的切片
+	fetch func(ctx context.Context, tenantID uint, spec *query.Spec) (items interface{}, pagination query.Pagination, err error)
+}
+
+// importTarget 可导入的数据：表头须包含 required 中的列，不认识的列忽略
+type importTarget struct {
+	required []string
+	// apply 校验并保存一行，record 为 表头 → 单元格
+	apply func(ctx context.Context, tenantID uint, record map[string]string) error
+}
+
+// DataJobServiceImpl 导入导出服务实现
+type DataJobServiceImpl struct {
+	jobRepo   container.DataJobRepository
+	userRepo  container.UserRepository
+	roleRepo  container.RoleRepository
+	auditRepo container.AuditLogRepository
+	logger    container.Logger
+
+	exports map[string]exportSource
+	imports map[string]importTarget
+	slots   chan struct{}
+
+	// wg 跟踪后台任务，stopping 关闭后排队中的任务不再执行
+	wg       sync.WaitGroup
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDataJobService 创建导入导出服务实例
+func NewDataJobService(jobRepo container.DataJobRepository, userRepo container.UserRepository, roleRepo container.RoleRepository, auditRepo container.AuditLogRepository, logger container.Logger) container.DataJobService {
+	s := &DataJobServiceImpl{
+		jobRepo:   jobRepo,
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+		auditRepo: auditRepo,
+		logger:    logger,
+		slots:     make(chan struct{}, dataJobWorkers),
+		stopping:  make(chan struct{}),
+	}
+	s.exports = map[string]exportSource{
+		"users": {
+			schema:  models.UserListSchema,
+			columns: []string{"id", "username", "email", "phone", "first_name", "last_name", "full_name", "lang", "status", "created_at"},
+			fetch:   s.fetchUsers,
+		},
+		"roles": {
+			schema:  models.RoleListSchema,
+			columns: []string{"id", "name", "display_name", "description", "level", "status", "is_system", "sort_order", "created_at"},
+			fetch: func(ctx context.Context, tenantID uint, spec *query.Spec) (interface{}, query.Pagination, error) {
+				page, err := s.roleRepo.ListByTenant(ctx, tenantID, spec)
+				if err != nil {
+					return nil, query.Pagination{}, err
+				}
+				return page.Items, page.Pagination, nil
+			},
+		},
+		"audit-logs": {
+			schema:  models.AuditLogListSchema,
+			columns: []string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "batch_id", "success", "code", "detail", "request_id"},
+			fetch: func(ctx context.Context, tenantID uint, spec *query.Spec) (interface{}, query.Pagination, error) {
+				page, err := s.auditRepo.ListByTenant(ctx, tenantID, spec)
+				if err != nil {
+					return nil, query.Pagination{}, err
+				}
+				return page.Items, page.Pagination, nil
+			},
+		},
+	}
+	s.imports = map[string]importTarget{
+		"users": {
+			required: []string{"username"},
+			apply:    s.importUser,
+		},
+	}
+	return s
+}
+
+// Export 校验查询参数后创建导出任务
+func (s *DataJobServiceImpl) Export(ctx context.Context, tenantID uint, resource, format string, params url.Values) (*models.DataJob, error) {
+	src, ok := s.exports[resource]
+	if !ok || !validFormat(format) {
+		return nil, e.NotFound(e.ERROR_DATA_JOB_UNSUPPORTED)
+	}
+	values := exportValues(params)
+	if _, err := query.Parse(values, src.schema); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	encoded := values.Encode()
+	if len(encoded) > 1000 {
+		encoded = encoded[:1000]
+	}
+	job := s.newJob(ctx, tenantID, models.DataJobExport, resource, format)
+	job.Params = encoded
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	s.logger.WithContext(ctx).Infof("DataJobService: export job %d queued (%s, %s)", job.ID, resource, format)
+
+	s.start(ctx, job, func(ctx context.Context) (map[string]interface{}, error) {
+		return s.runExport(ctx, job, src, values)
+	})
+	return job, nil
+}
+
+// Import 暂存上传文件并校验表头后创建导入任务
+func (s *DataJobServiceImpl) Import(ctx context.Context, tenantID uint, resource, format string, src io.Reader) (*models.DataJob, error) {
+	target, ok := s.imports[resource]
+	if !ok || !validFormat(format) {
+		return nil, e.NotFound(e.ERROR_DATA_JOB_UNSUPPORTED)
+	}
+	if err := s.checkQuota(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(export.GetImportFullPath(), export.NewFileName(resource, format))
+	if err := saveFile(path, src); err != nil {
+		s.logger.WithContext(ctx).Errorf("DataJobService: failed to store import file %s: %v", path, err)
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	if _, err := readHeader(format, path, target); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	job := s.newJob(ctx, tenantID, models.DataJobImport, resource, format)
+	job.SourceFile = path
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	s.logger.WithContext(ctx).Infof("DataJobService: import job %d queued (%s, %s)", job.ID, resource, format)
+
+	s.start(ctx, job, func(ctx context.Context) (map[string]interface{}, error) {
+		defer os.Remove(path)
+		return s.runImport(ctx, job, target)
+	})
+	return job, nil
+}
+
+// Get 获取本租户的任务
+func (s *DataJobServiceImpl) Get(ctx context.Context, tenantID, id uint) (*models.DataJob, error) {
+	return s.jobRepo.GetForTenant(ctx, id, tenantID)
+}
+
+// List 本租户的任务列表
+func (s *DataJobServiceImpl) List(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.DataJob], error) {
+	return s.jobRepo.ListByTenant(ctx, tenantID, spec)
+}
+
+// RecoverStale 任务只在发起它的进程内执行，进程退出后排队中与执行中的任务不会再继续；
+// 启动时（开始接收请求之前）将其标记为失败并删除暂存的导入文件。多实例共享数据库时应只由一个实例执行
+func (s *DataJobServiceImpl) RecoverStale(ctx context.Context) error {
+	jobs, err := s.jobRepo.FailActive(ctx, staleDataJobReason)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.SourceFile == "" {
+			continue
+		}
+		if err := os.Remove(job.SourceFile); err != nil && !os.IsNotExist(err) {
+			s.logger.WithContext(ctx).Warnf("DataJobService: failed to remove import file of job %d: %v", job.ID, err)
+		}
+	}
+	if len(jobs) > 0 {
+		s.logger.WithContext(ctx).Warnf("DataJobService: %d interrupted jobs marked as failed", len(jobs))
+	}
+	return nil
+}
+
+// Shutdown 停止调度排队中的任务（记为失败），等待执行中的任务写完结果；ctx 到期时直接返回，
+// 未完成的任务由下次启动的 RecoverStale 处理
+func (s *DataJobServiceImpl) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newJob 以当前操作者为发起人构造任务
+func (s *DataJobServiceImpl) newJob(ctx context.Context, tenantID uint, kind, resource, format string) *models.DataJob {
+	actor, _ := reqctx.ActorFrom(ctx)
+	return &models.DataJob{
+		TenantID:  tenantID,
+		CreatedBy: uint(actor.UserID),
+		Kind:      kind,
+		Resource:  resource,
+		Format:    format,
+		Status:    models.DataJobPending,
+	}
+}
+
+// checkQuota 限制单个租户同时排队的任务数，避免占满执行槽位
+func (s *DataJobServiceImpl) checkQuota(ctx context.Context, tenantID uint) error {
+	n, err := s.jobRepo.CountActive(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if n >= maxActiveDataJobs {
+		return e.New(e.ERROR_DATA_JOB_TOO_MANY)
+	}
+	return nil
+}
+
+// start 在后台执行任务：排队等待执行槽位，结束后按 fn 的结果写入状态；服务停止后排队中的任务记为失败
+// 任务不随请求结束而取消，但保留请求ID等上下文值便于日志关联
+func (s *DataJobServiceImpl) start(ctx context.Context, job *models.DataJob, fn func(ctx context.Context) (map[string]interface{}, error)) {
+	ctx = context.WithoutCancel(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-s.stopping:
+			s.abandon(ctx, job)
+			return
+		}
+		// 停止与空出槽位同时发生时 select 随机选择，拿到槽位后再确认一次
+		select {
+		case <-s.stopping:
+			s.abandon(ctx, job)
+			return
+		default:
+		}
+
+		_ = s.jobRepo.Update(ctx, job.ID, map[string]interface{}{"status": models.DataJobRunning, "started_at": time.Now()})
+
+		var (
+			result map[string]interface{}
+			err    error
+		)
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic: %v", r)
+				}
+			}()
+			result, err = fn(ctx)
+		}()
+		s.finish(ctx, job, result, err)
+	}()
+}
+
+// abandon 服务停止时放弃未执行的任务；导入任务的暂存文件执行时由 fn 删除，未执行时在这里删除
+func (s *DataJobServiceImpl) abandon(ctx context.Context, job *models.DataJob) {
+	if job.SourceFile != "" {
+		_ = os.Remove(job.SourceFile)
+	}
+	s.finish(ctx, job, nil, errDataJobStopped)
+}
+
+// finish 写入任务的最终状态与结果
+func (s *DataJobServiceImpl) finish(ctx context.Context, job *models.DataJob, result map[string]interface{}, err error) {
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	result["finished_at"] = time.Now()
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("DataJobService: %s job %d failed: %v", job.Kind, job.ID, err)
+		result["status"] = models.DataJobFailed
+		result["error"] = jobError(err)
+	} else {
+		s.logger.WithContext(ctx).Infof("DataJobService: %s job %d finished", job.Kind, job.ID)
+		result["status"] = models.DataJobSucceeded
+	}
+	_ = s.jobRepo.Update(ctx, job.ID, result)
+}
+
+// runExport 按游标分批读取并流式写出，内存占用与总行数无关
+func (s *DataJobServiceImpl) runExport(ctx context.Context, job *models.DataJob, src exportSource, values url.Values) (map[string]interface{}, error) {
+	spec, err := query.Parse(values, src.schema)
+	if err != nil {
+		return nil, err
+	}
+	columns := src.columns
+	if len(spec.Fields) > 0 {
+		columns = spec.Fields
+	}
+
+	name := export.NewFileName(job.Resource, job.Format)
+	path := filepath.Join(export.GetExcelFullPath(), name)
+	w, err := export.NewWriter(job.Format, path)
+	if err != nil {
+		return nil, err
+	}
+	var processed int64
+	err = func() error {
+		if err := w.Write(columns); err != nil {
+			return err
+		}
+		for {
+			items, pagination, err := src.fetch(ctx, job.TenantID, spec)
+			if err != nil {
+				return err
+			}
+			if pagination.Total != nil {
+				_ = s.jobRepo.Update(ctx, job.ID, map[string]interface{}{"total": *pagination.Total})
+			}
+			rows, err := toRecords(items)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				cells := make([]string, len(columns))
+				for i, column := range columns {
+					cells[i] = cellText(row[column])
+				}
+				if err := w.Write(cells); err != nil {
+					return err
+				}
+			}
+			processed += int64(len(rows))
+			_ = s.jobRepo.Update(ctx, job.ID, map[string]interface{}{"processed": processed})
+
+			if !pagination.HasMore {
+				return nil
+			}
+			values.Set("cursor", pagination.NextCursor)
+			if spec, err = query.Parse(values, src.schema); err != nil {
+				return err
+			}
+		}
+	}()
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return map[string]interface{}{"processed": processed, "total": processed, "file_name": name}, nil
+}
+
+// runImport 逐行校验并保存，失败行（行号、字段、原因）写入与源文件同格式的报告
+func (s *DataJobServiceImpl) runImport(ctx context.Context, job *models.DataJob, target importTarget) (map[string]interface{}, error) {
+	r, err := export.OpenReader(job.Format, job.SourceFile)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	header, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		report     export.Writer
+		reportName string
+		processed  int64
+		failed     int64
+	)
+	fail := func(line int, cause error) error {
+		failed++
+		if report == nil {
+			reportName = export.NewFileName(job.Resource+"-report", job.Format)
+			if report, err = export.NewWriter(job.Format, filepath.Join(export.GetExcelFullPath(), reportName)); err != nil {
+				return err
+			}
+			if err := report.Write([]string{"row", "field", "message"}); err != nil {
+				return err
+			}
+		}
+		ae := e.FromError(cause)
+		if len(ae.Fields) == 0 {
+			return report.Write([]string{strconv.Itoa(line), "", e.GetMsg(ae.Code)})
+		}
+		for _, f := range ae.Fields {
+			if err := report.Write([]string{strconv.Itoa(line), f.Field, f.Message}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = func() error {
+		// 行号与表格软件一致：表头为第 1 行
+		for line := 2; ; line++ {
+			cells, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			record := make(map[string]string, len(header))
+			blank := true
+			for i, column := range header {
+				if i < len(cells) {
+					record[column] = cells[i]
+					blank = blank && cells[i] == ""
+				}
+			}
+			if blank {
+				continue
+			}
+			if processed >= importMaxRows {
+				return fmt.Errorf("超过单次导入上限 %d 行", importMaxRows)
+			}
+			processed++
+			if err := target.apply(ctx, job.TenantID, record); err != nil {
+				if err := fail(line, err); err != nil {
+					return err
+				}
+			}
+			if processed%progressEvery == 0 {
+				_ = s.jobRepo.Update(ctx, job.ID, map[string]interface{}{"processed": processed, "failed": failed})
+			}
+		}
+	}()
+	if report != nil {
+		if cerr := report.Close(); err == nil {
+			err = cerr
+		}
+	}
+	result := map[string]interface{}{"total": processed, "processed": processed, "failed": failed, "file_name": reportName}
+	return result, err
+}
+
+// userImportRow 用户导入的一行，校验规则与后台创建用户一致
+type userImportRow struct {
+	Username  string `json:"username" binding:"required,username"`
+	Email     string `json:"email" binding:"omitempty,email,max=100"`
+	Phone     string `json:"phone" binding:"omitempty,phone"`
+	FirstName string `json:"first_name" binding:"max=50"`
+	LastName  string `json:"last_name" binding:"max=50"`
+	Lang      string `json:"lang" binding:"omitempty,max=10"`
+	Status    string `json:"status" binding:"omitempty,oneof=0 1 2"`
+}
+
+// importUser 校验并创建普通用户，状态缺省为正常；普通用户不归属租户，与 fetchUsers 相同忽略 tenantID
+func (s *DataJobServiceImpl) importUser(ctx context.Context, _ uint, record map[string]string) error {
+	var row userImportRow
+	raw, _ := json.Marshal(record)
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return e.Wrap(e.INVALID_PARAMS, err)
+	}
+	if err := app.ValidateStruct(&row); err != nil {
+		return err
+	}
+	status := 1
+	if row.Status != "" {
+		status, _ = strconv.Atoi(row.Status)
+	}
+	return s.userRepo.Create(ctx, &models.User{
+		Username:  row.Username,
+		Email:     row.Email,
+		Phone:     row.Phone,
+		FirstName: row.FirstName,
+		LastName:  row.LastName,
+		Lang:      row.Lang,
+		Status:    status,
+	})
+}
+
+// fetchUsers 用户导出行：列表项格式化后附带创建时间
+// 普通用户是平台级账号、不归属租户（与 /admin/v1/users 列表一致），因此不按 tenantID 过滤
+func (s *DataJobServiceImpl) fetchUsers(ctx context.Context, _ uint, spec *query.Spec) (interface{}, query.Pagination, error) {
+	page, err := s.userRepo.GetUsers(ctx, spec)
+	if err != nil {
+		return nil, query.Pagination{}, err
+	}
+	type userRow struct {
+		*models.UserInfo
+		CreatedAt models.GormTime `json:"created_at"`
+	}
+	rows := make([]userRow, 0, len(page.Items))
+	for _, user := range page.Items {
+		rows = append(rows, userRow{UserInfo: user.Format(), CreatedAt: user.CreatedAt})
+	}
+	return rows, page.Pagination, nil
+}
+
+// validFormat 是否为支持的文件格式
+func validFormat(format string) bool {
+	return format == export.FormatXLSX || format == export.FormatCSV
+}
+
+// exportValues 去掉分页参数，导出总是从第一条开始按最大页长分批读取
+func exportValues(params url.Values) url.Values {
+	values := url.Values{}
+	for k, v := range params {
+		switch k {
+		case "page", "page_size", "limit", "cursor", "format":
+			continue
+		}
+		values[k] = v
+	}
+	values.Set("limit", strconv.Itoa(query.DefaultMaxLimit))
+	return values
+}
+
+// readHeader 读取并校验表头须包含必填列
+func readHeader(format, path string, target importTarget) ([]string, error) {
+	r, err := export.OpenReader(format, path)
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_IMPORT_FILE_INVALID, err)
+	}
+	defer r.Close()
+	header, err := r.Next()
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_IMPORT_FILE_INVALID, err)
+	}
+	present := make(map[string]bool, len(header))
+	for _, column := range header {
+		present[column] = true
+	}
+	for _, column := range target.required {
+		if !present[column] {
+			return nil, e.New(e.ERROR_IMPORT_FILE_INVALID)
+		}
+	}
+	return header, nil
+}
+
+// saveFile 将上传内容写入 path
+func saveFile(path string, src io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// toRecords 将列表项转换为 字段名 → 值，字段名与列表接口的 JSON 输出一致
+func toRecords(items interface{}) ([]map[string]interface{}, error) {
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var rows []map[string]interface{}
+	if err := dec.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// cellText 单元格文本；数字保留原样，嵌套结构输出为 JSON
+func cellText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// jobError 任务失败原因，截断到字段长度
+func jobError(err error) string {
+	msg := err.Error()
+	var ae *e.Error
+	if errors.As(err, &ae) {
+		msg = e.GetMsg(ae.Code)
+	}
+	if r := []rune(msg); len(r) > 500 {
+		msg = string(r[:500])
+	}
+	return msg
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"justus/internal/container"
+	"justus/pkg/e"
+	"justus/pkg/export"
+	"justus/pkg/qrcode"
+	"justus/pkg/signurl"
+	"justus/pkg/upload"
+)
+
+// FileServiceImpl 签名文件服务实现
+type FileServiceImpl struct {
+	dataJobRepo container.DataJobRepository
+	uploadRepo  container.UploadRepository
+	storage     upload.Storage
+	logger      container.Logger
+}
+
+// NewFileService 创建签名文件服务实例
+func NewFileService(dataJobRepo container.DataJobRepository, uploadRepo container.UploadRepository, storage upload.Storage, logger container.Logger) container.FileService {
+	return &FileServiceImpl{dataJobRepo: dataJobRepo, uploadRepo: uploadRepo, storage: storage, logger: logger}
+}
+
+// Open 先验签，再按命名空间定位文件：导出文件与上传文件须属于链接绑定的租户
+// 文件不存在与不属于该租户一律返回 404，不暴露文件是否存在
+func (s *FileServiceImpl) Open(ctx context.Context, p string, query url.Values) (*container.SignedFile, error) {
+	claims, err := signurl.Verify(p, query, time.Now())
+	if errors.Is(err, signurl.ErrExpired) {
+		return nil, e.New(e.ERROR_FILE_LINK_EXPIRED)
+	}
+	if err != nil {
+		return nil, e.New(e.ERROR_FILE_LINK_INVALID)
+	}
+
+	rel := strings.TrimPrefix(p, signurl.Route)
+	if path.Clean("/"+rel) != "/"+rel || strings.Contains(rel, "..") {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	namespace, name, _ := strings.Cut(rel, "/")
+	if name == "" {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+
+	var file *container.SignedFile
+	switch namespace {
+	case signurl.Exports:
+		file, err = s.openExport(ctx, claims.TenantID, name)
+	case signurl.Uploads:
+		file, err = s.openUpload(ctx, claims.TenantID, name)
+	case signurl.QrCodes:
+		file, err = openLocal(qrcode.GetQrCodeFullPath(), name)
+	default:
+		err = e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Expires = claims.Expires
+	file.Filename = claims.Filename
+	return file, nil
+}
+
+// openExport 导出文件或导入报告，须由本租户的任务生成
+func (s *FileServiceImpl) openExport(ctx context.Context, tenantID uint, name string) (*container.SignedFile, error) {
+	if tenantID == 0 || strings.Contains(name, "/") {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	if _, err := s.dataJobRepo.GetByFile(ctx, tenantID, name); err != nil {
+		if errors.Is(err, e.New(e.ERROR_DATA_JOB_NOT_FOUND)) {
+			return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+		}
+		return nil, err
+	}
+	return openLocal(export.GetExcelFullPath(), name)
+}
+
+// openUpload 上传文件，须有本租户的上传记录引用该存储键
+func (s *FileServiceImpl) openUpload(ctx context.Context, tenantID uint, key string) (*container.SignedFile, error) {
+	if tenantID == 0 {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	u, err := s.uploadRepo.GetByKey(ctx, tenantID, key)
+	if err != nil {
+		if errors.Is(err, e.New(e.ERROR_UPLOAD_NOT_FOUND)) {
+			return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+		}
+		return nil, err
+	}
+
+	body, err := s.storage.Open(ctx, key)
+	if errors.Is(err, upload.ErrObjectNotFound) {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("Failed to open stored object %s: %v", key, err)
+		return nil, e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	content, err := seekable(body)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("Failed to buffer stored object %s: %v", key, err)
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	return &container.SignedFile{
+		Name:        path.Base(key),
+		ContentType: u.ContentType,
+		ETag:        `"` + u.MD5 + `"`,
+		ModTime:     u.CreatedAt.Time,
+		Content:     content,
+	}, nil
+}
+
+// openLocal 打开 dir 下的文件，name 不能包含目录
+func openLocal(dir, name string) (*container.SignedFile, error) {
+	if strings.Contains(name, "/") {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		_ = f.Close()
+		return nil, e.NotFound(e.ERROR_FILE_NOT_FOUND)
+	}
+	return &container.SignedFile{Name: name, ModTime: info.ModTime(), Content: f}, nil
+}
+
+// seekable 本地存储直接返回文件；S3 等流式对象先落临时文件，以支持 Range 请求
+func seekable(body io.ReadCloser) (io.ReadSeekCloser, error) {
+	if rs, ok := body.(io.ReadSeekCloser); ok {
+		return rs, nil
+	}
+	defer body.Close()
+	tmp, err := os.CreateTemp("", "justus-file-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(tmp, body); err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return &tempFile{File: tmp}, nil
+}
+
+// tempFile 关闭时删除的临时文件
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.Name())
+	return err
+}
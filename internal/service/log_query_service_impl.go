@@ -0,0 +1,314 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"justus/internal/container"
+	"justus/pkg/e"
+	"justus/pkg/setting"
+	"justus/pkg/zincsearch"
+)
+
+const (
+	// logScanChunkSize 倒序读取日志文件时每次读取的块大小
+	logScanChunkSize = 64 * 1024
+	// logScanMaxBytes 单次请求最多扫描的字节数，超出后返回游标由调用方继续翻页
+	logScanMaxBytes = 32 * 1024 * 1024
+)
+
+var (
+	// ErrLogSourceUnsupported 当前日志输出方式不支持查询
+	ErrLogSourceUnsupported = e.New(e.ERROR_SYSTEM_CONFIG).WithCause(errors.New("log source does not support querying"))
+	// ErrInvalidLogCursor 非法的分页游标
+	ErrInvalidLogCursor = e.New(e.INVALID_PARAMS).WithFields(e.FieldError{Field: "cursor", Rule: "cursor", Message: "游标无效"})
+)
+
+// LogQueryServiceImpl 日志查询服务实现，按当前 LogType 路由到 ZincSearch 或本地文件
+type LogQueryServiceImpl struct {
+	logger container.Logger
+}
+
+// NewLogQueryService 创建日志查询服务实例
+func NewLogQueryService(logger container.Logger) container.LogQueryService {
+	return &LogQueryServiceImpl{logger: logger}
+}
+
+// Query 按条件查询日志，结果按时间倒序
+func (s *LogQueryServiceImpl) Query(ctx context.Context, q container.LogQuery) (*container.LogPage, error) {
+	switch setting.LoggerSetting.LogType {
+	case setting.LogFileZinc:
+		return s.queryZinc(ctx, q)
+	case setting.LogFileType:
+		return s.queryFile(ctx, q)
+	default:
+		return nil, ErrLogSourceUnsupported
+	}
+}
+
+// queryZinc 基于 ZincSearch 布尔查询，游标为偏移量
+func (s *LogQueryServiceImpl) queryZinc(ctx context.Context, q container.LogQuery) (*container.LogPage, error) {
+	from, err := decodeLogCursor(q.Cursor, "zinc")
+	if err != nil {
+		return nil, err
+	}
+
+	var must []map[string]interface{}
+	if q.Level != "" {
+		must = append(must, map[string]interface{}{"term": map[string]interface{}{"level": q.Level}})
+	}
+	if !q.Start.IsZero() || !q.End.IsZero() {
+		rangeQuery := map[string]interface{}{}
+		if !q.Start.IsZero() {
+			rangeQuery["gte"] = q.Start.Format(time.RFC3339)
+		}
+		if !q.End.IsZero() {
+			rangeQuery["lte"] = q.End.Format(time.RFC3339)
+		}
+		must = append(must, map[string]interface{}{"range": map[string]interface{}{"@timestamp": rangeQuery}})
+	}
+	if q.UserID > 0 {
+		must = append(must, map[string]interface{}{"term": map[string]interface{}{"user_id": q.UserID}})
+	}
+	if q.TenantID > 0 {
+		must = append(must, map[string]interface{}{"term": map[string]interface{}{"tenant_id": q.TenantID}})
+	}
+	if q.Path != "" {
+		must = append(must, map[string]interface{}{"term": map[string]interface{}{"path": q.Path}})
+	}
+	if q.Keyword != "" {
+		must = append(must, map[string]interface{}{"match": map[string]interface{}{"_all": q.Keyword}})
+	}
+	if len(must) == 0 {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	client := zincsearch.NewClient()
+	sort := []interface{}{map[string]interface{}{"@timestamp": "desc"}}
+	resp, err := client.BoolSearchWithSort(setting.ZincSearchSetting.DefaultIndex, must, nil, nil, sort, from, q.Limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("LogQueryService: Zinc search failed: %v", err)
+		return nil, err
+	}
+
+	page := &container.LogPage{Source: string(setting.LogFileZinc), Entries: make([]container.LogEntry, 0, len(resp.Hits.Hits))}
+	for _, hit := range resp.Hits.Hits {
+		page.Entries = append(page.Entries, newLogEntry(hit.Source, "@timestamp", "message"))
+	}
+	if next := from + len(resp.Hits.Hits); len(resp.Hits.Hits) == q.Limit && next < resp.Hits.Total.Value {
+		page.NextCursor = encodeLogCursor("zinc", int64(next))
+	}
+	return page, nil
+}
+
+// queryFile 倒序扫描 lumberjack 当前日志文件（JSON 行），游标为下一次读取的文件偏移
+func (s *LogQueryServiceImpl) queryFile(ctx context.Context, q container.LogQuery) (*container.LogPage, error) {
+	path := setting.LoggerSetting.LogFileSavePath + "/" + setting.LoggerSetting.LogFileName + setting.LoggerSetting.LogFileExt
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &container.LogPage{Source: string(setting.LogFileType), Entries: []container.LogEntry{}}, nil
+		}
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat log file: %w", err)
+	}
+
+	offset := info.Size()
+	if q.Cursor != "" {
+		pos, err := decodeLogCursor(q.Cursor, "file")
+		if err != nil {
+			return nil, err
+		}
+		if int64(pos) < offset {
+			offset = int64(pos)
+		}
+	}
+
+	page := &container.LogPage{Source: string(setting.LogFileType), Entries: []container.LogEntry{}}
+	reader := &reverseLineReader{r: f, offset: offset}
+	keyword := strings.ToLower(q.Keyword)
+	for reader.scanned < logScanMaxBytes {
+		// 请求取消或超时后停止扫描
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line, lineStart, err := reader.next()
+		if err == io.EOF {
+			return page, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read log file: %w", err)
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		var raw map[string]interface{}
+		if json.Unmarshal(line, &raw) != nil {
+			continue
+		}
+		entry := newLogEntry(raw, "time", "msg")
+		if ts, err := time.Parse(time.RFC3339, entry.Timestamp); err == nil {
+			// 文件按时间追加，倒序读到早于起始时间的记录即可结束
+			if !q.Start.IsZero() && ts.Before(q.Start) {
+				return page, nil
+			}
+			if !q.End.IsZero() && ts.After(q.End) {
+				continue
+			}
+		}
+		if !matchLogEntry(entry, q, keyword, line) {
+			continue
+		}
+
+		page.Entries = append(page.Entries, entry)
+		if len(page.Entries) >= q.Limit {
+			if lineStart > 0 {
+				page.NextCursor = encodeLogCursor("file", lineStart)
+			}
+			return page, nil
+		}
+	}
+
+	// 达到单次扫描上限，返回游标由调用方继续向前翻页
+	if reader.offset > 0 || len(reader.buf) > 0 {
+		page.NextCursor = encodeLogCursor("file", reader.offset+int64(len(reader.buf)))
+	}
+	return page, nil
+}
+
+// matchLogEntry 文件模式下的字段过滤
+func matchLogEntry(entry container.LogEntry, q container.LogQuery, keyword string, line []byte) bool {
+	if q.Level != "" && entry.Level != q.Level {
+		return false
+	}
+	if q.UserID > 0 && toInt64(entry.Fields["user_id"]) != int64(q.UserID) {
+		return false
+	}
+	if q.TenantID > 0 && toInt64(entry.Fields["tenant_id"]) != int64(q.TenantID) {
+		return false
+	}
+	if q.Path != "" && entry.Fields["path"] != q.Path {
+		return false
+	}
+	if keyword != "" && !bytes.Contains(bytes.ToLower(line), []byte(keyword)) {
+		return false
+	}
+	return true
+}
+
+// newLogEntry 将原始日志文档规范化为统一结构
+func newLogEntry(raw map[string]interface{}, timeKey, messageKey string) container.LogEntry {
+	entry := container.LogEntry{Fields: map[string]interface{}{}}
+	for k, v := range raw {
+		switch k {
+		case timeKey:
+			entry.Timestamp, _ = v.(string)
+		case messageKey:
+			entry.Message, _ = v.(string)
+		case "level":
+			entry.Level, _ = v.(string)
+		default:
+			entry.Fields[k] = v
+		}
+	}
+	return entry
+}
+
+// toInt64 兼容 JSON 数字与字符串形式的整型字段
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// encodeLogCursor 游标编码：来源前缀 + 位置，避免不同来源的游标混用
+func encodeLogCursor(source string, pos int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(source + ":" + strconv.FormatInt(pos, 10)))
+}
+
+// decodeLogCursor 解析游标，空游标返回0
+func decodeLogCursor(cursor, source string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidLogCursor
+	}
+	prefix, pos, ok := strings.Cut(string(raw), ":")
+	if !ok || prefix != source {
+		return 0, ErrInvalidLogCursor
+	}
+	n, err := strconv.Atoi(pos)
+	if err != nil || n < 0 {
+		return 0, ErrInvalidLogCursor
+	}
+	return n, nil
+}
+
+// reverseLineReader 从指定偏移向文件头倒序逐行读取
+type reverseLineReader struct {
+	r       io.ReaderAt
+	offset  int64  // buf 在文件中的起始偏移
+	buf     []byte // 尚未消费的数据
+	scanned int64
+}
+
+// next 返回上一行内容及其在文件中的起始偏移
+func (rl *reverseLineReader) next() ([]byte, int64, error) {
+	for {
+		if i := bytes.LastIndexByte(rl.buf, '\n'); i >= 0 {
+			line := rl.buf[i+1:]
+			rl.buf = rl.buf[:i]
+			if len(line) > 0 {
+				return line, rl.offset + int64(i) + 1, nil
+			}
+			continue
+		}
+		if rl.offset == 0 {
+			if len(rl.buf) == 0 {
+				return nil, 0, io.EOF
+			}
+			line := rl.buf
+			rl.buf = nil
+			return line, 0, nil
+		}
+
+		size := int64(logScanChunkSize)
+		if rl.offset < size {
+			size = rl.offset
+		}
+		chunk := make([]byte, size)
+		if _, err := rl.r.ReadAt(chunk, rl.offset-size); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		rl.offset -= size
+		rl.scanned += size
+		rl.buf = append(chunk, rl.buf...)
+	}
+}
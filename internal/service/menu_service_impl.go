@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/pkg/rediskey"
+)
+
+const (
+	// menuWhitelistTTL 租户菜单白名单缓存时间
+	menuWhitelistTTL = 10 * time.Minute
+	// menuTreeTTL 用户菜单树缓存时间
+	menuTreeTTL = 5 * time.Minute
+)
+
+// MenuServiceImpl 菜单服务实现
+type MenuServiceImpl struct {
+	tenantRepo     container.TenantRepository
+	permissionRepo container.PermissionRepository
+	logger         container.Logger
+	cache          container.Cache
+}
+
+// NewMenuService 创建菜单服务实例
+func NewMenuService(tenantRepo container.TenantRepository, permissionRepo container.PermissionRepository, logger container.Logger, cache container.Cache) container.MenuService {
+	return &MenuServiceImpl{
+		tenantRepo:     tenantRepo,
+		permissionRepo: permissionRepo,
+		logger:         logger,
+		cache:          cache,
+	}
+}
+
+// UserMenuTree 用户在租户下可见的菜单树：租户白名单 ∩ 用户租户内权限，仅 is_menu 项
+func (s *MenuServiceImpl) UserMenuTree(ctx context.Context, tenantID uint, userID int) ([]*container.MenuNode, bool, error) {
+	// 用户维度菜单树缓存（key携带租户菜单世代号）
+	treeKey := rediskey.TenantUserMenuTreeKey(tenantID, uint(userID), s.menuGeneration(ctx, tenantID))
+	if s.cache != nil {
+		if raw := s.cache.Get(ctx, treeKey); raw != "" {
+			var cached []*container.MenuNode
+			if err := json.Unmarshal([]byte(raw), &cached); err == nil {
+				return cached, true, nil
+			}
+		}
+	}
+
+	whiteIDs, err := s.TenantMenuIDs(ctx, tenantID)
+	if err != nil {
+		return nil, false, err
+	}
+	userPermIDs, err := s.permissionRepo.GetAdminPermissionIDsInTenant(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("get user permission ids error: %v", err)
+		return nil, false, err
+	}
+
+	ids := intersectIDs(userPermIDs, whiteIDs)
+	if len(ids) == 0 {
+		return []*container.MenuNode{}, false, nil
+	}
+	menuPerms, err := s.permissionRepo.GetMenusByIDs(ctx, ids)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("query menu perms error: %v", err)
+		return nil, false, err
+	}
+
+	tree := buildMenuTree(menuPerms)
+	if s.cache != nil {
+		payload, _ := json.Marshal(tree)
+		_ = s.cache.Set(ctx, treeKey, string(payload), menuTreeTTL)
+	}
+	return tree, false, nil
+}
+
+// TenantMenuIDs 租户菜单白名单（先读缓存）
+func (s *MenuServiceImpl) TenantMenuIDs(ctx context.Context, tenantID uint) ([]uint, error) {
+	var whiteIDs []uint
+	if s.cache != nil {
+		if raw := s.cache.Get(ctx, rediskey.TenantMenuWhitelistKey(tenantID)); raw != "" {
+			_ = json.Unmarshal([]byte(raw), &whiteIDs)
+		}
+	}
+	if len(whiteIDs) > 0 {
+		return whiteIDs, nil
+	}
+
+	ids, err := s.tenantRepo.GetPermissionIDs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		b, _ := json.Marshal(ids)
+		_ = s.cache.Set(ctx, rediskey.TenantMenuWhitelistKey(tenantID), string(b), menuWhitelistTTL)
+	}
+	return ids, nil
+}
+
+// UpdateTenantMenus 覆盖式更新租户菜单白名单并失效缓存
+func (s *MenuServiceImpl) UpdateTenantMenus(ctx context.Context, tenantID uint, permissionIDs []uint) error {
+	if err := s.tenantRepo.ReplacePermissions(ctx, tenantID, permissionIDs); err != nil {
+		return err
+	}
+	s.InvalidateTenant(ctx, tenantID)
+	return nil
+}
+
+// InvalidateTenant 失效租户全部菜单缓存
+// 先递增世代号：所有旧菜单树key立即不可见（原子生效），再删除白名单并扫描清理旧菜单树释放内存
+func (s *MenuServiceImpl) InvalidateTenant(ctx context.Context, tenantID uint) {
+	if s.cache == nil {
+		return
+	}
+	if _, err := s.cache.Incr(ctx, rediskey.TenantMenuGenerationKey(tenantID)); err != nil {
+		s.logger.WithContext(ctx).Errorf("bump tenant menu generation error: tenant_id=%d, err=%v", tenantID, err)
+	}
+	if _, err := s.cache.Del(ctx, rediskey.TenantMenuWhitelistKey(tenantID)); err != nil {
+		s.logger.WithContext(ctx).Errorf("delete tenant menu whitelist error: tenant_id=%d, err=%v", tenantID, err)
+	}
+	removed, err := s.cache.DelByPattern(ctx, rediskey.TenantMenuTreePattern(tenantID))
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("purge tenant menu trees error: tenant_id=%d, err=%v", tenantID, err)
+		return
+	}
+	s.logger.WithContext(ctx).Infof("tenant menu cache invalidated: tenant_id=%d, removed_trees=%d", tenantID, removed)
+}
+
+// menuGeneration 读取租户菜单世代号，缓存不可用或未初始化时为0
+func (s *MenuServiceImpl) menuGeneration(ctx context.Context, tenantID uint) uint {
+	if s.cache == nil {
+		return 0
+	}
+	gen, err := strconv.ParseUint(s.cache.Get(ctx, rediskey.TenantMenuGenerationKey(tenantID)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(gen)
+}
+
+// intersectIDs 求两个ID集合的交集
+func intersectIDs(a, b []uint) []uint {
+	set := make(map[uint]struct{}, len(b))
+	for _, id := range b {
+		set[id] = struct{}{}
+	}
+	out := make([]uint, 0, len(a))
+	for _, id := range a {
+		if _, ok := set[id]; ok {
+			out = append(out, id)
+			delete(set, id)
+		}
+	}
+	return out
+}
+
+// buildMenuTree 按 parent_id 构建菜单树，父节点不可见时子节点提升为根节点
+func buildMenuTree(perms []models.Permission) []*container.MenuNode {
+	nodes := make(map[uint]*container.MenuNode, len(perms))
+	for _, p := range perms {
+		nodes[p.ID] = &container.MenuNode{
+			ID:          p.ID,
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			Route:       p.Route,
+			Component:   p.Component,
+			MenuIcon:    p.MenuIcon,
+			ParentID:    p.ParentID,
+			SortOrder:   p.SortOrder,
+			Children:    []*container.MenuNode{},
+		}
+	}
+
+	roots := []*container.MenuNode{}
+	for _, p := range perms {
+		node := nodes[p.ID]
+		if parent, ok := nodes[p.ParentID]; ok && p.ParentID != 0 {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return roots[i].SortOrder < roots[j].SortOrder
+	})
+	return roots
+}
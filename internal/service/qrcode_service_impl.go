@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"justus/internal/container"
+	"justus/pkg/e"
+	"justus/pkg/qrcode"
+	"justus/pkg/setting"
+	"justus/pkg/upload"
+
+	"github.com/boombuler/barcode/qr"
+)
+
+// 二维码参数默认值
+const (
+	qrCodeDefaultSize   = 256
+	qrCodeMinSize       = 64
+	qrCodeMaxSize       = 2048
+	qrCodeDefaultMargin = 4
+	qrCodeMaxMargin     = 16
+)
+
+var (
+	qrCodeFormats = map[string]string{"png": qrcode.EXT_PNG, "svg": qrcode.EXT_SVG, "jpg": qrcode.EXT_JPG}
+	qrCodeLevels  = map[string]qr.ErrorCorrectionLevel{"L": qr.L, "M": qr.M, "Q": qr.Q, "H": qr.H}
+)
+
+// QrCodeServiceImpl 二维码服务实现
+type QrCodeServiceImpl struct {
+	uploadRepo container.UploadRepository
+	storage    upload.Storage
+	logger     container.Logger
+}
+
+// NewQrCodeService 创建二维码服务实例
+func NewQrCodeService(uploadRepo container.UploadRepository, storage upload.Storage, logger container.Logger) container.QrCodeService {
+	return &QrCodeServiceImpl{uploadRepo: uploadRepo, storage: storage, logger: logger}
+}
+
+// Generate 按参数生成二维码；文件名由内容与全部渲染参数（Logo 按上传 MD5）决定，已生成过的直接复用
+func (s *QrCodeServiceImpl) Generate(ctx context.Context, tenantID uint, in container.QrCodeInput) (*container.QrCodeFile, error) {
+	code, err := s.newQrCode(in)
+	if err != nil {
+		return nil, err
+	}
+
+	logoKey, logoUpload := "", ""
+	if in.LogoID != 0 {
+		u, err := s.uploadRepo.GetForTenant(ctx, in.LogoID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(u.ContentType, "image/") {
+			return nil, e.New(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED)
+		}
+		logoKey, logoUpload = u.MD5, u.Key
+	}
+
+	dir := qrcode.GetQrCodeFullPath()
+	name := code.CacheKey(logoKey) + code.GetQrCodeExt()
+	f, err := os.Open(dir + name)
+	if errors.Is(err, os.ErrNotExist) {
+		if logoUpload != "" {
+			if code.Logo, err = s.loadLogo(ctx, logoUpload); err != nil {
+				return nil, err
+			}
+		}
+		if _, _, err = code.EncodeAs(dir, name); err != nil {
+			if errors.Is(err, qrcode.ErrContent) {
+				return nil, e.Validation(e.FieldError{Field: "content", Rule: "qrcode", Message: "内容过长，无法生成二维码"})
+			}
+			s.logger.WithContext(ctx).Errorf("Failed to generate qrcode %s: %v", name, err)
+			return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+		}
+		f, err = os.Open(dir + name)
+	}
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	return &container.QrCodeFile{
+		Name:        name,
+		ContentType: code.ContentType(),
+		ETag:        `"` + strings.TrimSuffix(name, code.GetQrCodeExt()) + `"`,
+		ModTime:     info.ModTime(),
+		Content:     f,
+	}, nil
+}
+
+// PurgeCache 删除过期的二维码缓存，缓存目录只增不减，由定时任务调用
+func (s *QrCodeServiceImpl) PurgeCache(ctx context.Context, maxAge time.Duration) (int, error) {
+	n, err := qrcode.PurgeCache(qrcode.GetQrCodeFullPath(), time.Now().Add(-maxAge))
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("QrCodeService: purge cache error: %v", err)
+		return n, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	s.logger.WithContext(ctx).Infof("QrCodeService: purged %d cached qrcodes older than %s", n, maxAge)
+	return n, nil
+}
+
+// newQrCode 校验参数并填充默认值
+func (s *QrCodeServiceImpl) newQrCode(in container.QrCodeInput) (*qrcode.QrCode, error) {
+	if in.Content == "" {
+		return nil, e.Validation(e.FieldError{Field: "content", Rule: "required", Message: "不能为空"})
+	}
+	format := in.Format
+	if format == "" {
+		format = "png"
+	}
+	ext, ok := qrCodeFormats[format]
+	if !ok {
+		return nil, e.Validation(e.FieldError{Field: "format", Rule: "oneof", Param: "png svg jpg", Message: "必须是以下值之一: png svg jpg"})
+	}
+	level := in.Level
+	if level == "" {
+		level = "M"
+	}
+	if _, ok := qrCodeLevels[level]; !ok {
+		return nil, e.Validation(e.FieldError{Field: "level", Rule: "oneof", Param: "L M Q H", Message: "必须是以下值之一: L M Q H"})
+	}
+	size := in.Size
+	if size == 0 {
+		size = qrCodeDefaultSize
+	}
+	if size < qrCodeMinSize || size > qrCodeMaxSize {
+		return nil, e.Validation(e.FieldError{Field: "size", Rule: "range", Param: "64-2048", Message: "尺寸须在 64~2048 之间"})
+	}
+	margin := qrCodeDefaultMargin
+	if in.Margin != nil {
+		margin = *in.Margin
+	}
+	if margin < 0 || margin > qrCodeMaxMargin {
+		return nil, e.Validation(e.FieldError{Field: "margin", Rule: "range", Param: "0-16", Message: "静区须在 0~16 之间"})
+	}
+
+	code := qrcode.NewQrCode(in.Content, size, size, qrCodeLevels[level], qr.Auto)
+	code.Ext = ext
+	code.Margin = margin
+	var err error
+	if code.Foreground, err = parseQrColor("fg", in.Foreground); err != nil {
+		return nil, err
+	}
+	if code.Background, err = parseQrColor("bg", in.Background); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// loadLogo 读取并解码作为 Logo 的上传图片
+func (s *QrCodeServiceImpl) loadLogo(ctx context.Context, key string) (image.Image, error) {
+	src, err := s.storage.Open(ctx, key)
+	if errors.Is(err, upload.ErrObjectNotFound) {
+		return nil, e.NotFound(e.ERROR_UPLOAD_NOT_FOUND)
+	}
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	defer src.Close()
+	return upload.DecodeImage(io.LimitReader(src, int64(setting.AppSetting.ImageMaxSize)+1))
+}
+
+// parseQrColor 解析颜色参数，为空时返回 nil（黑码白底）
+func parseQrColor(field, value string) (color.Color, error) {
+	if value == "" {
+		return nil, nil
+	}
+	c, err := qrcode.ParseColor(value)
+	if err != nil {
+		return nil, e.Validation(e.FieldError{Field: field, Rule: "hexcolor", Message: "颜色格式应为 #RRGGBB"})
+	}
+	return c, nil
+}
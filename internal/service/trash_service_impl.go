@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/reqctx"
+	"justus/pkg/e"
+	"justus/pkg/query"
+)
+
+// TrashServiceImpl 回收站服务实现
+type TrashServiceImpl struct {
+	trashRepo container.TrashRepository
+	logger    container.Logger
+}
+
+// NewTrashService 创建回收站服务实例
+func NewTrashService(trashRepo container.TrashRepository, logger container.Logger) container.TrashService {
+	return &TrashServiceImpl{trashRepo: trashRepo, logger: logger}
+}
+
+// List 已删除记录列表；角色按当前租户隔离
+func (s *TrashServiceImpl) List(ctx context.Context, resource string, tenantID uint, spec *query.Spec) (*container.TrashPage, error) {
+	if err := s.authorize(ctx, resource); err != nil {
+		return nil, err
+	}
+	return s.trashRepo.List(ctx, resource, tenantID, spec)
+}
+
+// Restore 从回收站恢复
+func (s *TrashServiceImpl) Restore(ctx context.Context, resource string, tenantID, id uint) error {
+	if err := s.authorize(ctx, resource); err != nil {
+		return err
+	}
+	if err := s.trashRepo.Restore(ctx, resource, tenantID, id); err != nil {
+		return err
+	}
+	s.logger.WithContext(ctx).Infof("TrashService: %s %d restored", resource, id)
+	return nil
+}
+
+// Purge 从回收站永久删除
+func (s *TrashServiceImpl) Purge(ctx context.Context, resource string, tenantID, id uint) error {
+	if err := s.authorize(ctx, resource); err != nil {
+		return err
+	}
+	if err := s.trashRepo.Purge(ctx, resource, tenantID, id); err != nil {
+		return err
+	}
+	s.logger.WithContext(ctx).Infof("TrashService: %s %d purged", resource, id)
+	return nil
+}
+
+// PurgeExpired 永久删除超过保留期的记录（定时任务调用，不做租户隔离）
+func (s *TrashServiceImpl) PurgeExpired(ctx context.Context, retention time.Duration) (map[string]int64, error) {
+	cutoff := time.Now().Add(-retention)
+	purged := make(map[string]int64, len(models.TrashSchemas))
+	for resource := range models.TrashSchemas {
+		n, err := s.trashRepo.PurgeBefore(ctx, resource, cutoff)
+		if err != nil {
+			return purged, err
+		}
+		purged[resource] = n
+	}
+	s.logger.WithContext(ctx).Infof("TrashService: purged records deleted before %s: %v", cutoff.Format(time.DateTime), purged)
+	return purged, nil
+}
+
+// authorize 管理员账号不属于任何租户后才会被删除，与租户一样只有超级管理员可以查看与处理
+func (s *TrashServiceImpl) authorize(ctx context.Context, resource string) error {
+	if _, ok := models.TrashSchemas[resource]; !ok {
+		return e.NotFound(e.ERROR_TRASH_NOT_FOUND)
+	}
+	superOnly := resource == models.TrashAdminUsers || resource == models.TrashTenants
+	if actor, _ := reqctx.ActorFrom(ctx); superOnly && !actor.IsSuper {
+		return e.New(e.ERROR_INSUFFICIENT_PERMISSION)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"justus/internal/container"
+	"justus/internal/models"
+	"justus/internal/reqctx"
+	"justus/pkg/e"
+	"justus/pkg/query"
+	"justus/pkg/setting"
+	"justus/pkg/signurl"
+	"justus/pkg/upload"
+)
+
+// uploadNameMax 原始文件名保存的最大长度（与 ay_uploads.name 一致）
+const uploadNameMax = 255
+
+// UploadServiceImpl 文件上传服务实现
+type UploadServiceImpl struct {
+	uploadRepo container.UploadRepository
+	storage    upload.Storage
+	logger     container.Logger
+}
+
+// NewUploadService 创建文件上传服务实例
+func NewUploadService(uploadRepo container.UploadRepository, storage upload.Storage, logger container.Logger) container.UploadService {
+	return &UploadServiceImpl{uploadRepo: uploadRepo, storage: storage, logger: logger}
+}
+
+// Upload 嗅探类型并落临时文件，按 MD5 去重、检查配额后写入存储
+// 存储键只由内容决定，已存在的对象不会重复写入
+func (s *UploadServiceImpl) Upload(ctx context.Context, tenantID uint, in container.UploadInput) (*models.Upload, bool, error) {
+	category := in.Category
+	if category == "" {
+		category = models.UploadImage
+	}
+	switch category {
+	case models.UploadAvatar, models.UploadImage, models.UploadFile:
+	default:
+		return nil, false, e.Validation(e.FieldError{Field: "category", Rule: "oneof", Param: "avatar image file", Message: "分类只能是 avatar、image 或 file"})
+	}
+
+	tmp, err := upload.Receive(in.Body, upload.DefaultPolicy())
+	if err != nil {
+		return nil, false, err
+	}
+	defer tmp.Remove()
+	if category != models.UploadFile && !strings.HasPrefix(tmp.ContentType, "image/") {
+		return nil, false, e.New(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED)
+	}
+
+	existing, err := s.uploadRepo.FindByMD5(ctx, tenantID, tmp.MD5)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, true, nil
+	}
+
+	// 图片先摆正、去除 EXIF 并限制尺寸，配额与存储均按处理后的大小计算
+	var img image.Image
+	if category != models.UploadFile {
+		if img, err = upload.NormalizeImage(tmp, setting.UploadSetting.Image); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if quota := setting.UploadSetting.TenantQuota; quota > 0 {
+		used, _, err := s.uploadRepo.Usage(ctx, tenantID)
+		if err != nil {
+			return nil, false, err
+		}
+		if used+tmp.Size > quota {
+			return nil, false, e.New(e.ERROR_UPLOAD_QUOTA_EXCEEDED)
+		}
+	}
+
+	prefix := upload.PrivatePrefix
+	if strings.HasPrefix(tmp.ContentType, "image/") {
+		prefix = "images"
+	}
+	key := tmp.Key(prefix)
+	stored, err := s.put(ctx, tmp, key)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("Failed to store upload %s: %v", key, err)
+		return nil, false, e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	// 变体与原图一同写入；对象已存在时变体也已生成过。变体失败不影响上传，可由 /variants 路由补生成
+	if stored && img != nil && !setting.UploadSetting.Image.Lazy {
+		for _, v := range setting.UploadSetting.Image.Variants {
+			if err := s.putVariant(ctx, img, key, v); err != nil {
+				s.logger.WithContext(ctx).Warnf("Failed to generate %s variant for %s: %v", v.Name, key, err)
+			}
+		}
+	}
+
+	actor, _ := reqctx.ActorFrom(ctx)
+	record := &models.Upload{
+		TenantID:    tenantID,
+		UploadedBy:  uint(actor.UserID),
+		Category:    category,
+		Name:        cleanUploadName(in.Name),
+		Key:         key,
+		ContentType: tmp.ContentType,
+		Size:        tmp.Size,
+		MD5:         tmp.MD5,
+	}
+	if err := s.uploadRepo.Create(ctx, record); err != nil {
+		return nil, false, err
+	}
+	s.logger.WithContext(ctx).Infof("Uploaded %s for tenant %d: key=%s, size=%d", category, tenantID, key, tmp.Size)
+	return record, false, nil
+}
+
+// put 对象不存在时才写入存储（其他租户可能已上传过相同内容），返回是否实际写入
+func (s *UploadServiceImpl) put(ctx context.Context, tmp *upload.Temp, key string) (bool, error) {
+	exists, err := s.storage.Exists(ctx, key)
+	if err != nil || exists {
+		return false, err
+	}
+	f, err := tmp.Open()
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return true, s.storage.Put(ctx, upload.Object{Key: key, Size: tmp.Size, ContentType: tmp.ContentType, SHA256: tmp.SHA256}, f)
+}
+
+// putVariant 生成并写入一个变体
+func (s *UploadServiceImpl) putVariant(ctx context.Context, img image.Image, key string, v setting.ImageVariant) error {
+	obj, body, err := upload.RenderVariant(img, key, v, setting.UploadSetting.Image.Quality)
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, obj, bytes.NewReader(body))
+}
+
+// Variant 返回图片变体的访问地址，变体不存在时从原图生成（Lazy 模式或处理管线上线前的图片）
+func (s *UploadServiceImpl) Variant(ctx context.Context, key, name string) (string, error) {
+	v, ok := upload.FindVariant(name)
+	if !ok || !strings.HasPrefix(key, "images/") || path.Clean(key) != key || strings.Contains(key, "..") {
+		return "", e.NotFound(e.ERROR_UPLOAD_NOT_FOUND)
+	}
+	variantKey := upload.VariantKey(key, v)
+	exists, err := s.storage.Exists(ctx, variantKey)
+	if err != nil {
+		return "", e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	if exists {
+		return s.storage.URL(variantKey), nil
+	}
+
+	src, err := s.storage.Open(ctx, key)
+	if errors.Is(err, upload.ErrObjectNotFound) {
+		return "", e.NotFound(e.ERROR_UPLOAD_NOT_FOUND)
+	}
+	if err != nil {
+		return "", e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	img, err := upload.DecodeImage(io.LimitReader(src, int64(setting.AppSetting.ImageMaxSize)+1))
+	_ = src.Close()
+	if err != nil {
+		return "", err
+	}
+	if err := s.putVariant(ctx, img, key, v); err != nil {
+		s.logger.WithContext(ctx).Errorf("Failed to generate %s variant for %s: %v", name, key, err)
+		return "", e.Wrap(e.ERROR_UPLOAD_STORAGE_FAIL, err)
+	}
+	return s.storage.URL(variantKey), nil
+}
+
+// Get 获取本租户的上传记录
+func (s *UploadServiceImpl) Get(ctx context.Context, tenantID, id uint) (*models.Upload, error) {
+	return s.uploadRepo.GetForTenant(ctx, id, tenantID)
+}
+
+// List 本租户的上传记录
+func (s *UploadServiceImpl) List(ctx context.Context, tenantID uint, spec *query.Spec) (*query.Page[models.Upload], error) {
+	return s.uploadRepo.ListByTenant(ctx, tenantID, spec)
+}
+
+// Delete 删除记录；对象（含图片变体）删除失败只记录日志，不影响记录删除的结果
+func (s *UploadServiceImpl) Delete(ctx context.Context, tenantID, id uint) error {
+	u, err := s.uploadRepo.GetForTenant(ctx, id, tenantID)
+	if err != nil {
+		return err
+	}
+	refs, err := s.uploadRepo.Delete(ctx, u)
+	if err != nil {
+		return err
+	}
+	if refs == 0 {
+		keys := []string{u.Key}
+		if strings.HasPrefix(u.Key, "images/") {
+			for _, v := range setting.UploadSetting.Image.Variants {
+				keys = append(keys, upload.VariantKey(u.Key, v))
+			}
+		}
+		for _, key := range keys {
+			if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, upload.ErrObjectNotFound) {
+				s.logger.WithContext(ctx).Warnf("Failed to delete stored object %s: %v", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Usage 租户存储用量
+func (s *UploadServiceImpl) Usage(ctx context.Context, tenantID uint) (*container.UploadUsage, error) {
+	used, files, err := s.uploadRepo.Usage(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &container.UploadUsage{Used: used, Quota: setting.UploadSetting.TenantQuota, Files: files}, nil
+}
+
+// URL 上传记录的访问地址：图片为公开地址，其余文件为绑定租户的签名链接，下载时使用原始文件名
+func (s *UploadServiceImpl) URL(u *models.Upload) string {
+	if upload.IsPrivate(u.Key) {
+		return signurl.URL(signurl.Path(signurl.Uploads, u.Key), signurl.Options{TenantID: u.TenantID, Filename: u.Name})
+	}
+	return s.storage.URL(u.Key)
+}
+
+// cleanUploadName 去掉客户端文件名中的路径并截断到字段长度
+func cleanUploadName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	for len(name) > uploadNameMax {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
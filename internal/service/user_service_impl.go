@@ -1,8 +1,19 @@
 package service
 
 import (
+	"context"
 	"justus/internal/container"
 	"justus/internal/models"
+	"justus/pkg/query"
+	"justus/pkg/rediskey"
+	"time"
+)
+
+const (
+	// userLangTTL 语言偏好缓存时间；本实例内修改资料会立即失效
+	userLangTTL = 10 * time.Minute
+	// userLangUnset 用户未设置语言时的缓存值，避免每次请求回源
+	userLangUnset = "-"
 )
 
 // UserServiceImpl 用户服务实现
@@ -22,100 +33,149 @@ func NewUserService(userRepo container.UserRepository, logger container.Logger,
 }
 
 // GetUserInfo 获取用户信息
-func (s *UserServiceImpl) GetUserInfo(id int) (*models.User, error) {
-	s.logger.Infof("UserService: Getting user info for ID: %d", id)
+func (s *UserServiceImpl) GetUserInfo(ctx context.Context, id int) (*models.User, error) {
+	s.logger.WithContext(ctx).Infof("UserService: Getting user info for ID: %d", id)
 
-	user, err := s.userRepo.GetByID(id)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to get user info for ID %d: %v", id, err)
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to get user info for ID %d: %v", id, err)
 		return nil, err
 	}
 
-	s.logger.Debugf("UserService: Successfully retrieved user info for ID: %d", id)
+	s.logger.WithContext(ctx).Debugf("UserService: Successfully retrieved user info for ID: %d", id)
 	return user, nil
 }
 
 // GetUsersByIDs 批量获取用户信息
-func (s *UserServiceImpl) GetUsersByIDs(ids []int) ([]*models.User, error) {
-	s.logger.Infof("UserService: Getting users info for IDs: %v", ids)
+func (s *UserServiceImpl) GetUsersByIDs(ctx context.Context, ids []int) ([]*models.User, error) {
+	s.logger.WithContext(ctx).Infof("UserService: Getting users info for IDs: %v", ids)
 
-	users, err := s.userRepo.GetByIDs(ids)
+	users, err := s.userRepo.GetByIDs(ctx, ids)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to get users info for IDs %v: %v", ids, err)
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to get users info for IDs %v: %v", ids, err)
 		return nil, err
 	}
 
-	s.logger.Debugf("UserService: Successfully retrieved %d users", len(users))
+	s.logger.WithContext(ctx).Debugf("UserService: Successfully retrieved %d users", len(users))
 	return users, nil
 }
 
 // GetUsers 获取用户列表
-func (s *UserServiceImpl) GetUsers(page, limit int, keyword, status string) ([]*models.User, int64, error) {
-	s.logger.Infof("UserService: Getting users list with filters")
+func (s *UserServiceImpl) GetUsers(ctx context.Context, spec *query.Spec) (*query.Page[*models.User], error) {
+	s.logger.WithContext(ctx).Infof("UserService: Getting users list with filters")
 
-	users, total, err := s.userRepo.GetUsers(page, limit, keyword, status)
+	result, err := s.userRepo.GetUsers(ctx, spec)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to get users list: %v", err)
-		return nil, 0, err
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to get users list: %v", err)
+		return nil, err
 	}
 
-	s.logger.Infof("UserService: Successfully retrieved users list - %d users found", len(users))
-	return users, total, nil
+	s.logger.WithContext(ctx).Infof("UserService: Successfully retrieved users list - %d users found", len(result.Items))
+	return result, nil
 }
 
 // CreateUser 创建用户
-func (s *UserServiceImpl) CreateUser(user *models.User) error {
-	s.logger.Infof("UserService: Creating user - %s %s", user.FirstName, user.LastName)
+func (s *UserServiceImpl) CreateUser(ctx context.Context, user *models.User) error {
+	s.logger.WithContext(ctx).Infof("UserService: Creating user - %s %s", user.FirstName, user.LastName)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证用户数据
 	// - 密码加密
 	// - 发送欢迎邮件等
 
-	err := s.userRepo.Create(user)
+	err := s.userRepo.Create(ctx, user)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to create user: %v", err)
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to create user: %v", err)
 		return err
 	}
 
-	s.logger.Infof("UserService: User created successfully with ID: %d", user.ID)
+	s.logger.WithContext(ctx).Infof("UserService: User created successfully with ID: %d", user.ID)
 	return nil
 }
 
 // UpdateUser 更新用户
-func (s *UserServiceImpl) UpdateUser(user *models.User) error {
-	s.logger.Infof("UserService: Updating user ID: %d", user.ID)
+func (s *UserServiceImpl) UpdateUser(ctx context.Context, user *models.User) error {
+	s.logger.WithContext(ctx).Infof("UserService: Updating user ID: %d", user.ID)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证更新权限
 	// - 数据验证
 	// - 缓存更新等
 
-	err := s.userRepo.Update(user)
+	err := s.userRepo.Update(ctx, user)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to update user ID %d: %v", user.ID, err)
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to update user ID %d: %v", user.ID, err)
 		return err
 	}
 
-	s.logger.Infof("UserService: User ID %d updated successfully", user.ID)
+	s.forgetLang(ctx, int(user.ID))
+	s.logger.WithContext(ctx).Infof("UserService: User ID %d updated successfully", user.ID)
 	return nil
 }
 
+// PatchUser 部分更新用户
+func (s *UserServiceImpl) PatchUser(ctx context.Context, id int, version uint, patch models.UserPatch) (*models.User, error) {
+	s.logger.WithContext(ctx).Infof("UserService: Patching user ID: %d", id)
+
+	user, err := s.userRepo.Patch(ctx, id, version, patch)
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to patch user ID %d: %v", id, err)
+		return nil, err
+	}
+
+	s.forgetLang(ctx, id)
+	s.logger.WithContext(ctx).Infof("UserService: User ID %d patched to version %d", id, user.Version)
+	return user, nil
+}
+
 // DeleteUser 删除用户
-func (s *UserServiceImpl) DeleteUser(id int) error {
-	s.logger.Infof("UserService: Deleting user ID: %d", id)
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, id int) error {
+	s.logger.WithContext(ctx).Infof("UserService: Deleting user ID: %d", id)
 
 	// 这里可以添加业务逻辑，例如：
 	// - 验证删除权限
 	// - 软删除逻辑
 	// - 清理相关数据等
 
-	err := s.userRepo.Delete(id)
+	err := s.userRepo.Delete(ctx, id)
 	if err != nil {
-		s.logger.Errorf("UserService: Failed to delete user ID %d: %v", id, err)
+		s.logger.WithContext(ctx).Errorf("UserService: Failed to delete user ID %d: %v", id, err)
 		return err
 	}
 
-	s.logger.Infof("UserService: User ID %d deleted successfully", id)
+	s.forgetLang(ctx, id)
+	s.logger.WithContext(ctx).Infof("UserService: User ID %d deleted successfully", id)
 	return nil
 }
+
+// GetUserLang 获取用户语言偏好，供未携带语言头的 API 请求使用
+func (s *UserServiceImpl) GetUserLang(ctx context.Context, id int) string {
+	if id <= 0 {
+		return ""
+	}
+	key := rediskey.UserLangKey(uint(id))
+	if lang := s.cache.Get(ctx, key); lang != "" {
+		if lang == userLangUnset {
+			return ""
+		}
+		return lang
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return ""
+	}
+	lang := user.Lang
+	if lang == "" {
+		lang = userLangUnset
+	}
+	_ = s.cache.Set(ctx, key, lang, userLangTTL)
+	return user.Lang
+}
+
+// forgetLang 资料变更后删除语言偏好缓存
+func (s *UserServiceImpl) forgetLang(ctx context.Context, id int) {
+	if _, err := s.cache.Del(ctx, rediskey.UserLangKey(uint(id))); err != nil {
+		s.logger.WithContext(ctx).Warnf("UserService: Failed to clear lang cache for user %d: %v", id, err)
+	}
+}
@@ -0,0 +1,130 @@
+package testkit
+
+import (
+	"encoding/json"
+	"os"
+
+	"justus/internal/models"
+)
+
+// Fixtures 测试数据集，按依赖顺序写入：租户 → 权限 → 角色 → 关联表 → 管理员
+// 需要稳定 ID 时显式填写 ID 字段，否则由数据库自增
+type Fixtures struct {
+	Tenants           []models.Tenant           `json:"tenants"`
+	Permissions       []models.Permission       `json:"permissions"`
+	Roles             []models.Role             `json:"roles"`
+	RolePermissions   []models.RolePermission   `json:"role_permissions"`
+	TenantPermissions []models.TenantPermission `json:"tenant_permissions"`
+	AdminUsers        []models.AdminUser        `json:"admin_users"`
+	AdminUserRoles    []models.AdminUserRole    `json:"admin_user_roles"`
+	Users             []models.User             `json:"users"`
+}
+
+// Load 写入测试数据，失败时终止测试
+func (k *Kit) Load(f Fixtures) {
+	k.t.Helper()
+
+	steps := []struct {
+		name string
+		rows interface{}
+		n    int
+	}{
+		{"tenants", &f.Tenants, len(f.Tenants)},
+		{"permissions", &f.Permissions, len(f.Permissions)},
+		{"roles", &f.Roles, len(f.Roles)},
+		{"role_permissions", &f.RolePermissions, len(f.RolePermissions)},
+		{"tenant_permissions", &f.TenantPermissions, len(f.TenantPermissions)},
+		{"admin_users", &f.AdminUsers, len(f.AdminUsers)},
+		{"admin_user_roles", &f.AdminUserRoles, len(f.AdminUserRoles)},
+		{"users", &f.Users, len(f.Users)},
+	}
+	for _, step := range steps {
+		if step.n == 0 {
+			continue
+		}
+		if err := k.DB.Create(step.rows).Error; err != nil {
+			k.t.Fatalf("testkit: load %s: %v", step.name, err)
+		}
+	}
+}
+
+// LoadFile 从 JSON 文件读取并写入测试数据
+func (k *Kit) LoadFile(path string) {
+	k.t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		k.t.Fatalf("testkit: read fixtures: %v", err)
+	}
+	var f Fixtures
+	if err := json.Unmarshal(raw, &f); err != nil {
+		k.t.Fatalf("testkit: parse fixtures %s: %v", path, err)
+	}
+	k.Load(f)
+}
+
+// 标准 RBAC 场景中的固定 ID
+const (
+	TenantA = 1
+	TenantB = 2
+
+	SuperAdminID = 1
+	AdminAID     = 2 // 租户 A 的管理员
+	AdminBID     = 3 // 租户 B 的管理员
+	PlainAdminID = 4 // 未分配 admin 角色的后台账号
+
+	AdminRoleID   = 1 // 系统级 admin 角色
+	RoleAEditorID = 2 // 租户 A 的自定义角色
+	RoleBEditorID = 3 // 租户 B 的自定义角色
+
+	PermDashboardID = 1
+	PermUsersID     = 2
+	PermRolesID     = 3
+	PermUserEditID  = 4 // 按钮级权限
+)
+
+// RBACFixtures 两个租户的标准 RBAC 场景：
+// 租户 A 白名单包含仪表盘、用户管理与按钮权限，租户 B 仅有仪表盘；
+// 两个租户管理员均被授予 admin 角色（拥有全部权限），由租户白名单决定最终可见范围
+func RBACFixtures() Fixtures {
+	return Fixtures{
+		Tenants: []models.Tenant{
+			{ID: TenantA, Code: "tenant-a", Name: "Tenant A", Status: 1},
+			{ID: TenantB, Code: "tenant-b", Name: "Tenant B", Status: 1},
+		},
+		Permissions: []models.Permission{
+			{ID: PermDashboardID, Name: "admin.dashboard", DisplayName: "仪表盘", Module: "admin", Action: "read", Resource: "dashboard", Route: "/dashboard", IsMenu: true, SortOrder: 1},
+			{ID: PermUsersID, Name: "admin.users", DisplayName: "用户管理", Module: "admin", Action: "read", Resource: "user", Route: "/users", IsMenu: true, SortOrder: 2},
+			{ID: PermRolesID, Name: "admin.roles", DisplayName: "角色管理", Module: "admin", Action: "read", Resource: "role", Route: "/roles", IsMenu: true, SortOrder: 3},
+			{ID: PermUserEditID, Name: "admin.users.edit", DisplayName: "编辑用户", Module: "admin", Action: "update", Resource: "user", ParentID: PermUsersID, Level: 2},
+		},
+		Roles: []models.Role{
+			{ID: AdminRoleID, TenantID: 0, Name: "admin", DisplayName: "管理员", Status: 1, IsSystem: true},
+			{ID: RoleAEditorID, TenantID: TenantA, Name: "a-editor", DisplayName: "A 编辑", Status: 1},
+			{ID: RoleBEditorID, TenantID: TenantB, Name: "b-editor", DisplayName: "B 编辑", Status: 1},
+		},
+		RolePermissions: []models.RolePermission{
+			{RoleID: AdminRoleID, PermissionID: PermDashboardID},
+			{RoleID: AdminRoleID, PermissionID: PermUsersID},
+			{RoleID: AdminRoleID, PermissionID: PermRolesID},
+			{RoleID: AdminRoleID, PermissionID: PermUserEditID},
+			{RoleID: RoleAEditorID, PermissionID: PermDashboardID},
+		},
+		TenantPermissions: []models.TenantPermission{
+			{TenantID: TenantA, PermissionID: PermDashboardID},
+			{TenantID: TenantA, PermissionID: PermUsersID},
+			{TenantID: TenantA, PermissionID: PermUserEditID},
+			{TenantID: TenantB, PermissionID: PermDashboardID},
+		},
+		AdminUsers: []models.AdminUser{
+			{ID: SuperAdminID, Username: "root", Password: "x", Email: "root@example.com", Status: 1, IsSuper: true},
+			{ID: AdminAID, Username: "admin-a", Password: "x", Email: "a@example.com", Status: 1},
+			{ID: AdminBID, Username: "admin-b", Password: "x", Email: "b@example.com", Status: 1},
+			{ID: PlainAdminID, Username: "plain", Password: "x", Email: "plain@example.com", Status: 1},
+		},
+		AdminUserRoles: []models.AdminUserRole{
+			{AdminUserID: AdminAID, RoleID: AdminRoleID, TenantID: TenantA},
+			{AdminUserID: AdminBID, RoleID: AdminRoleID, TenantID: TenantB},
+		},
+	}
+}
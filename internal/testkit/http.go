@@ -0,0 +1,78 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"justus/pkg/e"
+)
+
+// Response 统一响应结构（与 app.Gin 输出一致）
+type Response struct {
+	Status int             `json:"-"`
+	Code   int             `json:"code"`
+	Msg    string          `json:"msg"`
+	Data   json.RawMessage `json:"data"`
+	Errors []e.FieldError  `json:"errors"`
+}
+
+// Decode 将 data 字段解码到 v
+func (r *Response) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// Do 发起请求；body 为 nil、[]byte、string 或任意可 JSON 序列化的值，token 为空时不带 Authorization
+func (k *Kit) Do(method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	k.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			k.t.Fatalf("testkit: marshal body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range k.Header {
+		req.Header[name] = values
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	k.Router.ServeHTTP(w, req)
+	return w
+}
+
+// Call 发起请求并解析统一响应结构
+func (k *Kit) Call(method, path string, body interface{}, token string) *Response {
+	k.t.Helper()
+
+	w := k.Do(method, path, body, token)
+	resp := &Response{Status: w.Code}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		k.t.Fatalf("testkit: %s %s: decode response (status %d): %v\n%s", method, path, w.Code, err, w.Body.String())
+	}
+	return resp
+}
+
+// Get 便捷 GET
+func (k *Kit) Get(path, token string) *Response {
+	k.t.Helper()
+	return k.Call(http.MethodGet, path, nil, token)
+}
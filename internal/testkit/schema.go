@@ -0,0 +1,74 @@
+package testkit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"justus/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Models 测试库中建表的全部模型
+var Models = []interface{}{
+	&models.Tenant{},
+	&models.Permission{},
+	&models.Role{},
+	&models.RolePermission{},
+	&models.TenantPermission{},
+	&models.AdminUser{},
+	&models.AdminUserRole{},
+	&models.AdminInvitation{},
+	&models.User{},
+	&models.AuditLog{},
+	&models.DataJob{},
+	&models.Upload{},
+}
+
+// migrate 在 SQLite 中建表
+// SQLite 的索引名在整个库内唯一，而模型沿用 MySQL 的表内索引名（如 uk_name 同时用于角色与权限），
+// 因此先解析并缓存模型 schema，把显式索引名改写为 "表名_索引名" 后再 AutoMigrate（仅影响本连接的 schema 缓存）
+func migrate(db *gorm.DB, values ...interface{}) error {
+	for _, value := range values {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(value); err != nil {
+			return fmt.Errorf("parse %T: %w", value, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			field.Tag = prefixIndexNames(field.Tag, stmt.Schema.Table)
+		}
+		if err := db.AutoMigrate(value); err != nil {
+			return fmt.Errorf("migrate %s: %w", stmt.Schema.Table, err)
+		}
+	}
+	return nil
+}
+
+// prefixIndexNames 为 gorm 标签中的 index/uniqueIndex 名称加表名前缀
+func prefixIndexNames(tag reflect.StructTag, table string) reflect.StructTag {
+	gormTag, ok := tag.Lookup("gorm")
+	if !ok {
+		return tag
+	}
+
+	parts := strings.Split(gormTag, ";")
+	changed := false
+	for i, part := range parts {
+		key, value, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "INDEX", "UNIQUEINDEX":
+			if value != "" && !strings.HasPrefix(value, ",") {
+				parts[i] = key + ":" + table + "_" + value
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return tag
+	}
+	return reflect.StructTag(strings.Replace(string(tag), `gorm:"`+gormTag+`"`, `gorm:"`+strings.Join(parts, ";")+`"`, 1))
+}
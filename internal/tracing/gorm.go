@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// maxStatementLen 记录到 span 的 SQL 最大长度
+const maxStatementLen = 2048
+
+// RegisterGormCallbacks 注册 GORM 回调，为每条语句创建 client span（父 span 取自 db.WithContext 传入的 context）
+func RegisterGormCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	operations := []struct {
+		name   string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, op := range operations {
+		if err := op.before("tracing:before_"+op.name, startSpan(op.name)); err != nil {
+			return err
+		}
+		if err := op.after("tracing:after_"+op.name, endSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+		ctx, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	val, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := val.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if table := db.Statement.Table; table != "" {
+		span.SetAttributes(attribute.String("db.collection.name", table))
+	}
+	stmt := db.Statement.SQL.String()
+	if len(stmt) > maxStatementLen {
+		stmt = stmt[:maxStatementLen]
+	}
+	span.SetAttributes(
+		attribute.String("db.query.text", stmt),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
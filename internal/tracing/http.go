@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"justus/internal/reqctx"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware 接收上游 traceparent（缺失时新建 trace），为请求创建 server span，
+// 写回 traceparent 响应头，并把 span 放入 c.Request 的 context 供下游 GORM/Redis 与日志使用
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("user_agent.original", c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if id := reqctx.RequestID(ctx); id != "" {
+			span.SetAttributes(attribute.String("request.id", id))
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			c.Set("trace_id", sc.TraceID().String())
+		}
+		propagator.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if actor, ok := reqctx.ActorFrom(c.Request.Context()); ok {
+			span.SetAttributes(attribute.Int("enduser.id", actor.UserID), attribute.Int("tenant.id", actor.TenantID))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
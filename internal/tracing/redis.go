@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook go-redis 钩子，为命令与管道创建 client span
+type RedisHook struct{}
+
+// NewRedisHook 创建 Redis 追踪钩子
+func NewRedisHook() redis.Hook {
+	return RedisHook{}
+}
+
+// BeforeProcess 开始单条命令 span
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = Tracer().Start(ctx, "redis."+cmd.Name(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation.name", cmd.Name()),
+		),
+	)
+	return ctx, nil
+}
+
+// AfterProcess 结束单条命令 span
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedisSpan(trace.SpanFromContext(ctx), cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline 开始管道 span
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = Tracer().Start(ctx, "redis.pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.Int("db.operation.batch.size", len(cmds)),
+		),
+	)
+	return ctx, nil
+}
+
+// AfterProcessPipeline 结束管道 span，任一命令失败即标记错误
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if e := cmd.Err(); e != nil && !errors.Is(e, redis.Nil) {
+			err = e
+			break
+		}
+	}
+	endRedisSpan(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+// endRedisSpan redis.Nil 表示key不存在，不计为错误
+func endRedisSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
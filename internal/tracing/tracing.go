@@ -0,0 +1,103 @@
+// Package tracing OpenTelemetry 链路追踪：HTTP 入口、GORM 与 Redis 的 span，经 OTLP/HTTP 导出
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"justus/internal/global"
+	"justus/pkg/setting"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// instrumentationName 本服务 tracer 名称
+const instrumentationName = "justus"
+
+// defaultTracesPath OTLP/HTTP 默认导出路径
+const defaultTracesPath = "/v1/traces"
+
+// Tracer 返回本服务的 tracer（未 Setup 时为全局默认的空实现）
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// NewProvider 按配置创建 TracerProvider
+// 未启用时不导出且不采样，但仍为每个请求生成 trace/span ID，保证 traceparent 与日志关联可用
+func NewProvider(cfg *setting.Tracing, version string) (*sdktrace.TracerProvider, error) {
+	res := resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+		attribute.String("service.version", version),
+		attribute.String("deployment.environment", setting.ServerSetting.RunMode),
+	)
+	if !cfg.Enabled {
+		return sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.NeverSample()),
+		), nil
+	}
+
+	endpoint, err := exportURL(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint)}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	), nil
+}
+
+// Setup 安装全局 TracerProvider 与 W3C 传播器，启用时为传入的数据库与 Redis 挂载追踪
+// 返回的 shutdown 在停机时调用，刷新尚未导出的 span
+func Setup(cfg *setting.Tracing, version string, db *gorm.DB, rdb *redis.Client) (func(context.Context) error, error) {
+	tp, err := NewProvider(cfg, version)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.Enabled {
+		if db != nil {
+			if err := RegisterGormCallbacks(db); err != nil {
+				global.Logger.Warnf("注册GORM追踪回调失败: %v", err)
+			}
+		}
+		if rdb != nil {
+			rdb.AddHook(NewRedisHook())
+		}
+	}
+	return tp.Shutdown, nil
+}
+
+// exportURL 补全导出路径
+func exportURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid tracing endpoint %q", endpoint)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = defaultTracesPath
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"justus/internal/middleware/requestid"
+	"justus/internal/reqctx"
+	"justus/pkg/app"
+	"justus/pkg/setting"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// collector 进程内 OTLP/HTTP 接收端
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (col *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req coltracepb.ExportTraceServiceRequest
+	if r.URL.Path != defaultTracesPath || proto.Unmarshal(body, &req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	col.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			col.spans = append(col.spans, ss.Spans...)
+		}
+	}
+	col.mu.Unlock()
+
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
+
+func (col *collector) byName() map[string]*tracepb.Span {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	out := make(map[string]*tracepb.Span, len(col.spans))
+	for _, s := range col.spans {
+		out[s.Name] = s
+	}
+	return out
+}
+
+func TestExportHTTPGormRedisSpans(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	db, err := gorm.Open(sqlite.Open("file:tracing?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	shutdown, err := Setup(&setting.Tracing{Enabled: true, Endpoint: srv.URL, ServiceName: "justus-test", SampleRatio: 1}, "test", db, rdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&logs)
+	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(reqctx.LogHook{})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestid.RequestID(), Middleware())
+	r.GET("/ping", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var n int64
+		db.WithContext(ctx).Table("sqlite_master").Count(&n)
+		rdb.Get(ctx, "missing")
+		log.WithContext(ctx).Info("pong")
+		appG := app.Gin{C: c}
+		appG.Success(nil)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set(requestid.Header, "req-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("traceparent"); !strings.Contains(got, traceID) {
+		t.Fatalf("traceparent response header = %q", got)
+	}
+	var body app.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.TraceID != traceID || body.RequestID != "req-1" {
+		t.Fatalf("response trace_id=%q request_id=%q", body.TraceID, body.RequestID)
+	}
+	if !strings.Contains(logs.String(), `"trace_id":"`+traceID+`"`) || !strings.Contains(logs.String(), `"request_id":"req-1"`) {
+		t.Fatalf("log entry missing correlation fields: %s", logs.String())
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	spans := col.byName()
+	server, ok := spans["GET /ping"]
+	if !ok {
+		t.Fatalf("server span not exported, got %v", spans)
+	}
+	for _, name := range []string{"gorm.query", "redis.get"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("%s span not exported", name)
+		}
+		if !bytes.Equal(span.TraceId, server.TraceId) || !bytes.Equal(span.ParentSpanId, server.SpanId) {
+			t.Fatalf("%s is not a child of the server span", name)
+		}
+	}
+}
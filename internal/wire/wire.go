@@ -1,58 +1,157 @@
 package wire
 
 import (
+	"errors"
+
 	"justus/internal/container"
 	"justus/internal/controllers/admin"
 	"justus/internal/controllers/api"
 	"justus/internal/controllers/common"
+	"justus/internal/global"
+	"justus/internal/health"
 	"justus/internal/infrastructure"
+	"justus/internal/models"
 	"justus/internal/repository"
 	"justus/internal/service"
+	"justus/pkg/setting"
+	"justus/pkg/upload"
+	"justus/pkg/util"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
+// Deps 组装应用所需的基础设施依赖，由 bootstrap 构建，测试可替换为 fake
+type Deps struct {
+	Logger container.Logger
+	Cache  container.Cache
+	// DB、Redis、JWTSecret 为必填；仓储层仍通过 models 包访问数据库，由 WireApp 统一安装到包级变量
+	DB        *gorm.DB
+	Redis     *redis.Client
+	JWTSecret []byte
+	// Health 为空时按当前配置创建默认检查注册表
+	Health *health.Registry
+	// Mailer 为空时按邮件配置创建（未配置 SMTP 时只写日志）
+	Mailer container.Mailer
+	// Storage 为空时按上传配置创建（本地磁盘或 S3 兼容服务）
+	Storage upload.Storage
+}
+
 // WireApp 组装应用程序的所有依赖
-func WireApp() (*AppContext, error) {
+func WireApp(deps Deps) (*AppContext, error) {
+	if deps.Logger == nil || deps.Cache == nil {
+		return nil, errors.New("wire: Logger and Cache are required")
+	}
+	if deps.DB == nil || deps.Redis == nil || len(deps.JWTSecret) == 0 {
+		return nil, errors.New("wire: DB, Redis and JWTSecret are required")
+	}
+	models.SetDb(deps.DB)
+	global.Redis = deps.Redis
+	util.SetJWTSecret(deps.JWTSecret)
+
 	// 初始化依赖注入容器
 	container.InitContainer()
 
-	// 创建基础设施层
-	logger := infrastructure.NewLogger()
-	cache := infrastructure.NewCache()
+	// 基础设施层
+	logger := deps.Logger
+	cache := deps.Cache
+	healthRegistry := deps.Health
+	if healthRegistry == nil {
+		healthRegistry = health.NewDefaultRegistry(deps.DB, deps.Redis)
+	}
+	mailer := deps.Mailer
+	if mailer == nil {
+		mailer = infrastructure.NewMailer(setting.MailSetting, logger)
+	}
+	storage := deps.Storage
+	if storage == nil {
+		var err error
+		if storage, err = upload.NewStorage(setting.UploadSetting); err != nil {
+			return nil, err
+		}
+	}
 
 	// 创建 Repository 层
 	userRepo := repository.NewUserRepository(logger, cache)
 	adminUserRepo := repository.NewAdminUserRepository(logger, cache)
+	roleRepo := repository.NewRoleRepository(logger, cache)
+	permissionRepo := repository.NewPermissionRepository(logger, cache)
+	tenantRepo := repository.NewTenantRepository(logger, cache)
+	invitationRepo := repository.NewAdminInvitationRepository(logger)
+	trashRepo := repository.NewTrashRepository(logger)
+	auditLogRepo := repository.NewAuditLogRepository(logger)
+	dataJobRepo := repository.NewDataJobRepository(logger)
+	uploadRepo := repository.NewUploadRepository(logger)
 
 	// 创建 Service 层
 	userService := service.NewUserService(userRepo, logger, cache)
-	adminUserService := service.NewAdminUserService(adminUserRepo, logger, cache)
+	cacheService := service.NewCacheService(cache, logger)
+	logQueryService := service.NewLogQueryService(logger)
+	menuService := service.NewMenuService(tenantRepo, permissionRepo, logger, cache)
+	adminUserService := service.NewAdminUserService(adminUserRepo, roleRepo, tenantRepo, menuService, auditLogRepo, logger, cache)
+	trashService := service.NewTrashService(trashRepo, logger)
+	dataJobService := service.NewDataJobService(dataJobRepo, userRepo, roleRepo, auditLogRepo, logger)
+	uploadService := service.NewUploadService(uploadRepo, storage, logger)
+	fileService := service.NewFileService(dataJobRepo, uploadRepo, storage, logger)
+	qrCodeService := service.NewQrCodeService(uploadRepo, storage, logger)
+	invitationService := service.NewAdminInvitationService(invitationRepo, adminUserRepo, roleRepo, tenantRepo, menuService, mailer, logger, cache)
 
 	// 将服务注册到容器中
 	container.GlobalContainer.Logger = logger
 	container.GlobalContainer.Cache = cache
+	container.GlobalContainer.Mailer = mailer
+	container.GlobalContainer.Storage = storage
+	upload.SetDefaultStorage(storage)
 	container.GlobalContainer.UserRepo = userRepo
 	container.GlobalContainer.AdminUserRepo = adminUserRepo
+	container.GlobalContainer.RoleRepo = roleRepo
+	container.GlobalContainer.PermissionRepo = permissionRepo
+	container.GlobalContainer.TenantRepo = tenantRepo
+	container.GlobalContainer.InvitationRepo = invitationRepo
+	container.GlobalContainer.TrashRepo = trashRepo
+	container.GlobalContainer.AuditLogRepo = auditLogRepo
+	container.GlobalContainer.DataJobRepo = dataJobRepo
+	container.GlobalContainer.UploadRepo = uploadRepo
 	container.GlobalContainer.UserService = userService
 	container.GlobalContainer.AdminUserService = adminUserService
+	container.GlobalContainer.InvitationService = invitationService
+	container.GlobalContainer.TrashService = trashService
+	container.GlobalContainer.CacheService = cacheService
+	container.GlobalContainer.LogQueryService = logQueryService
+	container.GlobalContainer.MenuService = menuService
+	container.GlobalContainer.DataJobService = dataJobService
+	container.GlobalContainer.UploadService = uploadService
+	container.GlobalContainer.FileService = fileService
+	container.GlobalContainer.QrCodeService = qrCodeService
 
 	// 创建 API 控制器
 	userController := api.NewUserController(userService, logger, cache)
 
 	// 创建 Admin 控制器
 	userManagementController := admin.NewUserManagementController(userService, adminUserService, logger)
-	systemController := admin.NewSystemController(logger, cache)
-	roleController := admin.NewRoleController(logger, cache)
-	accessController := admin.NewAccessController(logger, cache)
-	menuController := admin.NewMenuController(logger, cache)
-	authController := admin.NewAuthController(logger, cache)
+	systemController := admin.NewSystemController(cacheService, logQueryService, healthRegistry, logger, cache)
+	roleController := admin.NewRoleController(roleRepo, permissionRepo, menuService, logger, cache)
+	accessController := admin.NewAccessController(permissionRepo, logger, cache)
+	menuController := admin.NewMenuController(menuService, logger)
+	authController := admin.NewAuthController(adminUserService, tenantRepo, logger)
+	adminUserController := admin.NewAdminUserController(adminUserService, invitationService, logger)
+	trashController := admin.NewTrashController(trashService, logger)
+	tenantController := admin.NewTenantController(tenantRepo, logger)
+	auditLogController := admin.NewAuditLogController(auditLogRepo, logger)
+	dataJobController := admin.NewDataJobController(dataJobService, logger)
+	uploadController := admin.NewUploadController(uploadService, logger)
+	qrCodeController := admin.NewQrCodeController(qrCodeService, logger)
 
 	// 创建公共控制器
-	healthController := common.NewHealthController(logger, cache)
+	healthController := common.NewHealthController(healthRegistry, logger)
 	testController := common.NewTestController(logger)
+	imageController := common.NewImageController(uploadService, logger)
+	fileController := common.NewFileController(fileService, logger)
 
 	// 创建应用上下文
 	app := &AppContext{
-		Container: container.GlobalContainer,
+		Container:      container.GlobalContainer,
+		HealthRegistry: healthRegistry,
 
 		// API 控制器
 		UserController: userController,
@@ -64,10 +163,19 @@ func WireApp() (*AppContext, error) {
 		AccessController:         accessController,
 		MenuController:           menuController,
 		AuthController:           authController,
+		AdminUserController:      adminUserController,
+		TrashController:          trashController,
+		TenantController:         tenantController,
+		AuditLogController:       auditLogController,
+		DataJobController:        dataJobController,
+		UploadController:         uploadController,
+		QrCodeController:         qrCodeController,
 
 		// 公共控制器
 		HealthController: healthController,
 		TestController:   testController,
+		ImageController:  imageController,
+		FileController:   fileController,
 	}
 
 	return app, nil
@@ -75,7 +183,8 @@ func WireApp() (*AppContext, error) {
 
 // AppContext 应用程序上下文，包含所有注入的依赖
 type AppContext struct {
-	Container *container.Container
+	Container      *container.Container
+	HealthRegistry *health.Registry
 
 	// API 控制器
 	UserController *api.UserController
@@ -87,8 +196,17 @@ type AppContext struct {
 	AccessController         *admin.AccessController
 	MenuController           *admin.MenuController
 	AuthController           *admin.AuthController
+	AdminUserController      *admin.AdminUserController
+	TrashController          *admin.TrashController
+	TenantController         *admin.TenantController
+	AuditLogController       *admin.AuditLogController
+	DataJobController        *admin.DataJobController
+	UploadController         *admin.UploadController
+	QrCodeController         *admin.QrCodeController
 
 	// 公共控制器
 	HealthController *common.HealthController
 	TestController   *common.TestController
+	ImageController  *common.ImageController
+	FileController   *common.FileController
 }
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"justus/pkg/e"
+)
+
+// ETag 由记录版本号生成的实体标签，如 "3"
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// SetETag 写入 ETag 响应头，客户端更新时通过 If-Match 回传
+func SetETag(c *gin.Context, version uint) {
+	if version > 0 {
+		c.Header("ETag", ETag(version))
+	}
+}
+
+// IfMatch 解析 If-Match 请求头中的版本号
+// 未携带或为 * 时返回 0（不做前置校验）；接受弱标签 W/"3"，格式不正确返回 400
+func IfMatch(c *gin.Context) (uint, error) {
+	v := strings.TrimSpace(c.GetHeader("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	v = strings.TrimPrefix(v, "W/")
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, e.New(e.INVALID_PARAMS)
+	}
+	n, err := strconv.ParseUint(v[1:len(v)-1], 10, 32)
+	if err != nil || n == 0 {
+		return 0, e.New(e.INVALID_PARAMS)
+	}
+	return uint(n), nil
+}
@@ -1,29 +1,100 @@
 package app
 
 import (
-	"github.com/astaxie/beego/validation"
+	"errors"
+	"strings"
+
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"github.com/go-playground/validator/v10"
 
 	"justus/pkg/e"
+	"justus/pkg/query"
 )
 
-// BindAndValid binds and validates data
-func BindAndValid(c *gin.Context, form interface{}) (int, int) {
-	err := c.Bind(form)
-	if err != nil {
-		return http.StatusBadRequest, e.INVALID_PARAMS
+// BindAndValid 按 Content-Type 绑定请求（JSON、表单或查询参数）并校验 binding 标签
+// 校验失败返回带字段明细的 422 错误，请求无法解析时返回 400
+func BindAndValid(c *gin.Context, form interface{}) error {
+	Validator()
+	return bindError(c.ShouldBind(form))
+}
+
+// BindJSON 解析并校验 JSON 请求体，错误语义同 BindAndValid
+func BindJSON(c *gin.Context, obj interface{}) error {
+	Validator()
+	return bindError(c.ShouldBindJSON(obj))
+}
+
+// BindQuery 绑定并校验查询参数（form 标签），错误语义同 BindAndValid
+func BindQuery(c *gin.Context, obj interface{}) error {
+	Validator()
+	return bindError(c.ShouldBindQuery(obj))
+}
+
+// ParseQuery 按列表白名单解析过滤、排序、字段与分页参数，错误语义同 BindAndValid
+func ParseQuery(c *gin.Context, schema *query.Schema) (*query.Spec, error) {
+	return query.Parse(c.Request.URL.Query(), schema)
+}
+
+func bindError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return e.Validation(FieldErrors(verrs)...).WithCause(err)
 	}
+	return e.Wrap(e.INVALID_PARAMS, err)
+}
 
-	valid := validation.Validation{}
-	check, err := valid.Valid(form)
-	if err != nil {
-		return http.StatusInternalServerError, e.ERROR
+// FieldErrors 将 validator 的校验错误转换为字段错误列表
+func FieldErrors(verrs validator.ValidationErrors) []e.FieldError {
+	fields := make([]e.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, e.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: ruleMessage(fe),
+		})
 	}
-	if !check {
-		MarkErrors(valid.Errors)
-		return http.StatusBadRequest, e.INVALID_PARAMS
+	return fields
+}
+
+// fieldPath 返回去掉顶层结构体名的字段路径，如 items[0].name
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
 	}
+	return fe.Field()
+}
 
-	return http.StatusOK, e.SUCCESS
+// ruleMessage 生成字段错误的默认中文提示，响应时按请求语言替换
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "不能为空"
+	case "email":
+		return "邮箱格式不正确"
+	case "min":
+		return "不能小于 " + fe.Param()
+	case "max":
+		return "不能大于 " + fe.Param()
+	case "len":
+		return "长度必须为 " + fe.Param()
+	case "oneof":
+		return "必须是以下值之一: " + fe.Param()
+	case "phone":
+		return "手机号格式不正确"
+	case "username":
+		return "用户名须以字母开头，由 3~50 位字母、数字或下划线组成"
+	case "permname":
+		return "权限标识格式应为 module.action.resource"
+	case "tenantcode":
+		return "租户编码须为 3~50 位小写字母、数字或中划线"
+	}
+	if fe.Param() != "" {
+		return "不满足校验规则 " + fe.Tag() + "=" + fe.Param()
+	}
+	return "不满足校验规则 " + fe.Tag()
 }
@@ -5,7 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"justus/internal/global"
 	"justus/pkg/e"
+	"justus/pkg/glange"
 )
 
 type Gin struct {
@@ -13,17 +15,22 @@ type Gin struct {
 }
 
 type Response struct {
-	Code int         `json:"code"`
-	Msg  string      `json:"msg"`
-	Data interface{} `json:"data"`
+	Code      int            `json:"code"`
+	Msg       string         `json:"msg"`
+	Data      interface{}    `json:"data"`
+	Errors    []e.FieldError `json:"errors,omitempty"`     // 字段级校验错误
+	RequestID string         `json:"request_id,omitempty"` // 由 requestid 中间件写入
+	TraceID   string         `json:"trace_id,omitempty"`   // 由 tracing 中间件写入
 }
 
 // Response setting gin.JSON
 func (g *Gin) Response(httpCode, errCode int, data interface{}) {
 	g.C.JSON(httpCode, Response{
-		Code: errCode,
-		Msg:  e.GetMsg(errCode),
-		Data: data,
+		Code:      errCode,
+		Msg:       g.message(errCode, e.MsgKey(errCode)),
+		Data:      data,
+		RequestID: g.C.GetString("request_id"),
+		TraceID:   g.C.GetString("trace_id"),
 	})
 	return
 }
@@ -33,14 +40,54 @@ func (g *Gin) Success(data interface{}) {
 	g.Response(http.StatusOK, e.SUCCESS, data)
 }
 
-// Error 错误响应
+// Error 错误响应，HTTP 状态由业务码推导
 func (g *Gin) Error(errCode int) {
-	g.Response(http.StatusOK, errCode, nil)
+	g.Fail(e.New(errCode))
 }
 
 // ErrorWithData 带数据的错误响应
 func (g *Gin) ErrorWithData(errCode int, data interface{}) {
-	g.Response(http.StatusOK, errCode, data)
+	g.Response(e.StatusOf(errCode), errCode, data)
+}
+
+// Fail 统一错误响应：按应用错误输出状态码、业务码与字段错误
+// 内部原因只记录到日志与链路追踪，不返回给客户端
+func (g *Gin) Fail(err error) {
+	if err == nil {
+		err = e.New(e.ERROR)
+	}
+	ae := e.FromError(err)
+	// 交给 tracing 中间件记录到 span
+	_ = g.C.Error(err)
+	if ae.Status >= http.StatusInternalServerError && global.Logger != nil {
+		global.Logger.WithContext(g.C.Request.Context()).Errorf("%s %s failed: %v", g.C.Request.Method, g.C.FullPath(), ae)
+	}
+
+	g.C.JSON(ae.Status, Response{
+		Code:      ae.Code,
+		Msg:       g.message(ae.Code, ae.Key()),
+		Data:      nil,
+		Errors:    g.localizeFields(ae.Fields),
+		RequestID: g.C.GetString("request_id"),
+		TraceID:   g.C.GetString("trace_id"),
+	})
+}
+
+// ItemResult 批量接口中单条记录的处理结果
+type ItemResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+// Item 生成批量接口单条结果，提示按请求语言输出；err 为 nil 表示成功
+func (g *Gin) Item(id uint, err error) ItemResult {
+	if err == nil {
+		return ItemResult{ID: id, Success: true, Code: e.SUCCESS, Msg: g.message(e.SUCCESS, e.MsgKey(e.SUCCESS))}
+	}
+	ae := e.FromError(err)
+	return ItemResult{ID: id, Code: ae.Code, Msg: g.message(ae.Code, ae.Key())}
 }
 
 // InvalidParams 参数错误响应
@@ -52,3 +99,30 @@ func (g *Gin) InvalidParams() {
 func (g *Gin) Unauthorized(errCode int) {
 	g.Response(http.StatusUnauthorized, errCode, nil)
 }
+
+// message 按请求语言（locale 中间件写入）返回提示
+// 未指定语言时保持中文提示；指定语言缺少译文时回退英文，语言包不可用时回退中文
+func (g *Gin) message(code int, key string) string {
+	if locale := g.C.GetString("locale"); locale != "" {
+		if msg, ok := glange.Localize(locale, key, nil); ok {
+			return msg
+		}
+	}
+	return e.GetMsg(code)
+}
+
+// localizeFields 按请求语言重写字段错误提示，缺少对应规则译文时保留原提示
+func (g *Gin) localizeFields(fields []e.FieldError) []e.FieldError {
+	locale := g.C.GetString("locale")
+	if locale == "" || len(fields) == 0 {
+		return fields
+	}
+	out := make([]e.FieldError, len(fields))
+	for i, f := range fields {
+		if msg, ok := glange.Localize(locale, e.RuleKey(f.Rule), map[string]interface{}{"Param": f.Param}); ok {
+			f.Message = msg
+		}
+		out[i] = f
+	}
+	return out
+}
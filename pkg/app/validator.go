@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"justus/pkg/e"
+)
+
+// 项目自定义校验规则，可直接用于 binding 标签，如 `binding:"required,phone"`
+var (
+	// phoneRule 手机号：可带 + 前缀的 6~15 位数字（E.164）
+	phoneRule = regexp.MustCompile(`^\+?[1-9][0-9]{5,14}$`)
+	// usernameRule 用户名：字母开头，3~50 位字母、数字或下划线
+	usernameRule = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,49}$`)
+	// permNameRule 权限标识：module.action.resource，恰好 3 段小写字母、数字或下划线
+	permNameRule = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*){2}$`)
+	// tenantCodeRule 租户编码：3~50 位小写字母、数字或中划线，不以中划线开头或结尾
+	tenantCodeRule = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,48}[a-z0-9]$`)
+)
+
+// customRules 规则名 → 正则，空值交给 required/omitempty 处理
+var customRules = map[string]*regexp.Regexp{
+	"phone":      phoneRule,
+	"username":   usernameRule,
+	"permname":   permNameRule,
+	"tenantcode": tenantCodeRule,
+}
+
+var setupValidatorOnce sync.Once
+
+// Validator 返回 gin 绑定使用的校验引擎，首次调用时注册 json 字段名与项目自定义规则
+func Validator() *validator.Validate {
+	v, _ := binding.Validator.Engine().(*validator.Validate)
+	if v == nil {
+		return nil
+	}
+	setupValidatorOnce.Do(func() {
+		v.RegisterTagNameFunc(jsonTagName)
+		for tag, re := range customRules {
+			_ = v.RegisterValidation(tag, matchRule(re))
+		}
+	})
+	return v
+}
+
+// ValidateStruct 校验非 HTTP 入口（服务层、导入任务等）构造的结构体，失败时返回带字段明细的 422 错误
+func ValidateStruct(obj interface{}) error {
+	v := Validator()
+	if v == nil {
+		// binding.Validator 被替换为非 validator/v10 引擎时无法校验，按内部错误处理而不是 panic
+		return e.Internal(errors.New("app: binding validator is not validator/v10"))
+	}
+	err := v.Struct(obj)
+	if err == nil {
+		return nil
+	}
+	if verrs, ok := err.(validator.ValidationErrors); ok {
+		return e.Validation(FieldErrors(verrs)...).WithCause(err)
+	}
+	return e.Wrap(e.INVALID_PARAMS, err)
+}
+
+func matchRule(re *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		s := fl.Field().String()
+		return s == "" || re.MatchString(s)
+	}
+}
+
+// jsonTagName 让校验错误使用 json 字段名，与客户端提交的字段保持一致
+func jsonTagName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
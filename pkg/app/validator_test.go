@@ -0,0 +1,49 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"justus/pkg/e"
+)
+
+type ruleForm struct {
+	Phone      string `json:"phone" binding:"omitempty,phone"`
+	Username   string `json:"username" binding:"omitempty,username"`
+	Permission string `json:"permission" binding:"omitempty,permname"`
+	TenantCode string `json:"tenant_code" binding:"omitempty,tenantcode"`
+}
+
+func TestCustomRules(t *testing.T) {
+	cases := []struct {
+		name  string
+		form  ruleForm
+		field string
+	}{
+		{"valid", ruleForm{Phone: "+8613800138000", Username: "alice_01", Permission: "admin.user.create", TenantCode: "tenant-a"}, ""},
+		{"two segment permission", ruleForm{Permission: "admin.dashboard"}, "permission"},
+		{"four segment permission", ruleForm{Permission: "admin.user.create.bulk"}, "permission"},
+		{"phone with letters", ruleForm{Phone: "138-0013-8000"}, "phone"},
+		{"username starts with digit", ruleForm{Username: "1alice"}, "username"},
+		{"username too short", ruleForm{Username: "al"}, "username"},
+		{"permission single segment", ruleForm{Permission: "admin"}, "permission"},
+		{"permission uppercase", ruleForm{Permission: "Admin.User"}, "permission"},
+		{"tenant code trailing hyphen", ruleForm{TenantCode: "tenant-"}, "tenant_code"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateStruct(tc.form)
+			if tc.field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var ae *e.Error
+			if !errors.As(err, &ae) || ae.Status != http.StatusUnprocessableEntity || len(ae.Fields) != 1 || ae.Fields[0].Field != tc.field {
+				t.Fatalf("got %v, want a single field error on %s", err, tc.field)
+			}
+		})
+	}
+}
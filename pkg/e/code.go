@@ -57,6 +57,7 @@ const (
 	ERROR_ROLE_IN_USE             = 41007
 	ERROR_ADMIN_ROLE_PROTECT      = 41008
 	ERROR_INSUFFICIENT_PERMISSION = 41009
+	ERROR_PERMISSION_EXIST        = 41010
 
 	// 管理员相关错误码
 	ERROR_ADMIN_NOT_FOUND      = 42001
@@ -64,6 +65,33 @@ const (
 	ERROR_ADMIN_UPDATE_FAIL    = 42003
 	ERROR_ADMIN_DELETE_FAIL    = 42004
 	ERROR_ADMIN_SELF_OPERATION = 42005
+	ERROR_ADMIN_OWNER_PROTECT  = 42006
+	ERROR_ADMIN_LAST_SUPER     = 42007
+	ERROR_INVITATION_INVALID   = 42008
+
+	// 租户相关错误码
+	ERROR_TENANT_NOT_FOUND  = 43001
+	ERROR_TENANT_CODE_EXIST = 43002
+
+	// 回收站相关错误码
+	ERROR_TRASH_NOT_FOUND        = 44001
+	ERROR_TRASH_RESTORE_CONFLICT = 44002
+	ERROR_TRASH_RESTORE_ORPHAN   = 44003
+
+	// 导入导出任务相关错误码
+	ERROR_DATA_JOB_NOT_FOUND   = 45001
+	ERROR_DATA_JOB_UNSUPPORTED = 45002
+	ERROR_IMPORT_FILE_INVALID  = 45003
+	ERROR_DATA_JOB_TOO_MANY    = 45004
+
+	// 文件上传相关错误码
+	ERROR_UPLOAD_TOO_LARGE        = 46001
+	ERROR_UPLOAD_TYPE_NOT_ALLOWED = 46002
+	ERROR_UPLOAD_QUOTA_EXCEEDED   = 46003
+	ERROR_UPLOAD_NOT_FOUND        = 46004
+	ERROR_UPLOAD_STORAGE_FAIL     = 46005
+	ERROR_FILE_LINK_INVALID       = 46006
+	ERROR_FILE_LINK_EXPIRED       = 46007
 
 	// 数据库相关错误码
 	ERROR_DATABASE_CONNECTION = 50001
@@ -87,8 +115,9 @@ const (
 	ERROR_NETWORK_REQUEST = 80002
 
 	// 业务逻辑错误码
-	ERROR_BUSINESS_LOGIC  = 90001
-	ERROR_DATA_VALIDATION = 90002
+	ERROR_BUSINESS_LOGIC   = 90001
+	ERROR_DATA_VALIDATION  = 90002
+	ERROR_VERSION_CONFLICT = 90003
 
 	// 系统相关错误码
 	ERROR_SYSTEM_MAINTENANCE = 91001
@@ -0,0 +1,133 @@
+package e
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// FieldError 字段级校验错误，原样返回给客户端
+// Param 为规则参数（如 max=10 中的 10），用于按语言重新生成 Message
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"-"`
+	Message string `json:"message"`
+}
+
+// Error 应用错误：携带业务码、HTTP 状态、消息键、字段错误与内部原因
+// Cause 仅用于日志与链路追踪，响应时不会输出给客户端
+type Error struct {
+	Code   int
+	Status int
+	MsgKey string
+	Fields []FieldError
+	Cause  error
+}
+
+// New 按业务码创建错误，HTTP 状态由 StatusOf 推导
+func New(code int) *Error {
+	return &Error{Code: code, Status: StatusOf(code)}
+}
+
+// Wrap 按业务码包装底层错误；cause 已是 *Error 时原样返回，避免覆盖更具体的业务码
+func Wrap(code int, cause error) *Error {
+	var ae *Error
+	if errors.As(cause, &ae) {
+		return ae
+	}
+	return New(code).WithCause(cause)
+}
+
+// NotFound 资源不存在（404）
+func NotFound(code int) *Error {
+	return New(code).WithStatus(http.StatusNotFound)
+}
+
+// Forbidden 无权访问（403）
+func Forbidden(code int) *Error {
+	return New(code).WithStatus(http.StatusForbidden)
+}
+
+// Conflict 资源冲突（409）
+func Conflict(code int) *Error {
+	return New(code).WithStatus(http.StatusConflict)
+}
+
+// Validation 字段校验失败（422）
+func Validation(fields ...FieldError) *Error {
+	return New(ERROR_DATA_VALIDATION).WithFields(fields...)
+}
+
+// Internal 内部错误（500），原因只写入日志
+func Internal(cause error) *Error {
+	return New(ERROR).WithStatus(http.StatusInternalServerError).WithCause(cause)
+}
+
+// FromError 将任意错误归一为 *Error；非应用错误一律视为内部错误
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var ae *Error
+	if errors.As(err, &ae) {
+		return ae
+	}
+	return Internal(err)
+}
+
+// Error 实现 error 接口，包含原因便于日志排查
+func (err *Error) Error() string {
+	msg := fmt.Sprintf("code=%d status=%d msg=%s", err.Code, err.Status, GetMsg(err.Code))
+	if err.Cause != nil {
+		msg += ": " + err.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap 支持 errors.Is/As 访问底层原因
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
+
+// Is 业务码相同即视为同一错误，便于 errors.Is(err, e.New(e.ERROR_USER_NOT_FOUND))
+func (err *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == err.Code
+}
+
+// Key 返回消息键；未显式指定时按业务码生成
+func (err *Error) Key() string {
+	if err.MsgKey != "" {
+		return err.MsgKey
+	}
+	return MsgKey(err.Code)
+}
+
+// WithCause 返回携带原因的副本
+func (err *Error) WithCause(cause error) *Error {
+	c := *err
+	c.Cause = cause
+	return &c
+}
+
+// WithFields 返回携带字段错误的副本
+func (err *Error) WithFields(fields ...FieldError) *Error {
+	c := *err
+	c.Fields = append([]FieldError(nil), fields...)
+	return &c
+}
+
+// WithStatus 返回指定 HTTP 状态的副本
+func (err *Error) WithStatus(status int) *Error {
+	c := *err
+	c.Status = status
+	return &c
+}
+
+// WithMsgKey 返回指定消息键的副本
+func (err *Error) WithMsgKey(key string) *Error {
+	c := *err
+	c.MsgKey = key
+	return &c
+}
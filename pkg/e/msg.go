@@ -1,5 +1,7 @@
 package e
 
+import "strconv"
+
 var MsgFlags = map[int]string{
 	SUCCESS:        "ok",
 	ERROR:          "fail",
@@ -57,6 +59,7 @@ var MsgFlags = map[int]string{
 	ERROR_ROLE_IN_USE:             "角色正在使用中，无法删除",
 	ERROR_ADMIN_ROLE_PROTECT:      "管理员角色受保护，无法修改或删除",
 	ERROR_INSUFFICIENT_PERMISSION: "权限不足，无法执行此操作",
+	ERROR_PERMISSION_EXIST:        "权限标识已存在",
 
 	// 管理员相关错误消息
 	ERROR_ADMIN_NOT_FOUND:      "管理员不存在",
@@ -64,6 +67,33 @@ var MsgFlags = map[int]string{
 	ERROR_ADMIN_UPDATE_FAIL:    "更新管理员失败",
 	ERROR_ADMIN_DELETE_FAIL:    "删除管理员失败",
 	ERROR_ADMIN_SELF_OPERATION: "不能对自己执行此操作",
+	ERROR_ADMIN_OWNER_PROTECT:  "不能删除或禁用租户所有者",
+	ERROR_ADMIN_LAST_SUPER:     "不能删除或禁用最后一个超级管理员",
+	ERROR_INVITATION_INVALID:   "邀请不存在或已失效",
+
+	// 租户相关错误消息
+	ERROR_TENANT_NOT_FOUND:  "租户不存在",
+	ERROR_TENANT_CODE_EXIST: "租户编码已被使用",
+
+	// 回收站相关错误消息
+	ERROR_TRASH_NOT_FOUND:        "回收站中不存在该记录",
+	ERROR_TRASH_RESTORE_CONFLICT: "恢复失败，唯一字段已被其他记录占用",
+	ERROR_TRASH_RESTORE_ORPHAN:   "恢复失败，记录所属的租户已被永久删除",
+
+	// 导入导出任务相关错误消息
+	ERROR_DATA_JOB_NOT_FOUND:   "导入导出任务不存在",
+	ERROR_DATA_JOB_UNSUPPORTED: "该数据不支持导入导出或格式不支持",
+	ERROR_IMPORT_FILE_INVALID:  "导入文件无效，请使用导出模板的表头",
+	ERROR_DATA_JOB_TOO_MANY:    "进行中的导入导出任务过多，请稍后再试",
+
+	// 文件上传相关错误消息
+	ERROR_UPLOAD_TOO_LARGE:        "文件超过大小限制",
+	ERROR_UPLOAD_TYPE_NOT_ALLOWED: "不支持的文件类型",
+	ERROR_UPLOAD_QUOTA_EXCEEDED:   "租户存储空间不足",
+	ERROR_UPLOAD_NOT_FOUND:        "文件不存在",
+	ERROR_UPLOAD_STORAGE_FAIL:     "文件存储失败",
+	ERROR_FILE_LINK_INVALID:       "下载链接无效",
+	ERROR_FILE_LINK_EXPIRED:       "下载链接已过期",
 
 	// 数据库相关错误消息
 	ERROR_DATABASE_CONNECTION: "数据库连接失败",
@@ -87,8 +117,9 @@ var MsgFlags = map[int]string{
 	ERROR_NETWORK_REQUEST: "网络请求失败",
 
 	// 业务逻辑错误消息
-	ERROR_BUSINESS_LOGIC:  "业务逻辑错误",
-	ERROR_DATA_VALIDATION: "数据验证失败",
+	ERROR_BUSINESS_LOGIC:   "业务逻辑错误",
+	ERROR_DATA_VALIDATION:  "数据验证失败",
+	ERROR_VERSION_CONFLICT: "数据已被他人修改，请刷新后重试",
 
 	// 系统相关错误消息
 	ERROR_SYSTEM_MAINTENANCE: "系统维护中",
@@ -105,3 +136,13 @@ func GetMsg(code int) string {
 
 	return MsgFlags[ERROR]
 }
+
+// MsgKey 业务码对应的语言包消息键，见 pkg/langefile 中的 [error] 表
+func MsgKey(code int) string {
+	return "error." + strconv.Itoa(code)
+}
+
+// RuleKey 校验规则对应的语言包消息键，见 pkg/langefile 中的 [validation] 表
+func RuleKey(rule string) string {
+	return "validation." + rule
+}
@@ -0,0 +1,84 @@
+package e
+
+import "net/http"
+
+// StatusFlags 业务码对应的 HTTP 状态；未列出的错误码按 500 处理
+var StatusFlags = map[int]int{
+	SUCCESS:        http.StatusOK,
+	CONTENT_EMPTY:  http.StatusOK,
+	INVALID_PARAMS: http.StatusBadRequest,
+	SIGN_ERROR:     http.StatusUnauthorized,
+
+	ERROR_EXIST_TAG:         http.StatusConflict,
+	ERROR_NOT_EXIST_TAG:     http.StatusNotFound,
+	ERROR_NOT_EXIST_ARTICLE: http.StatusNotFound,
+
+	ERROR_AUTH_CHECK_TOKEN_FAIL:    http.StatusUnauthorized,
+	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: http.StatusUnauthorized,
+	ERROR_AUTH_TOKEN:               http.StatusUnauthorized,
+	ERROR_AUTH:                     http.StatusUnauthorized,
+
+	ERROR_UPLOAD_CHECK_IMAGE_FAIL:   http.StatusUnprocessableEntity,
+	ERROR_UPLOAD_CHECK_IMAGE_FORMAT: http.StatusUnprocessableEntity,
+
+	ERROR_USER_NOT_FOUND:      http.StatusNotFound,
+	ERROR_USER_ALREADY_EXIST:  http.StatusConflict,
+	ERROR_USER_STATUS_INVALID: http.StatusUnprocessableEntity,
+
+	ERROR_PERMISSION_DENIED:       http.StatusForbidden,
+	ERROR_ROLE_NOT_FOUND:          http.StatusNotFound,
+	ERROR_ROLE_ALREADY_EXIST:      http.StatusConflict,
+	ERROR_ROLE_IN_USE:             http.StatusConflict,
+	ERROR_ADMIN_ROLE_PROTECT:      http.StatusForbidden,
+	ERROR_INSUFFICIENT_PERMISSION: http.StatusForbidden,
+	ERROR_PERMISSION_EXIST:        http.StatusConflict,
+
+	ERROR_ADMIN_NOT_FOUND:      http.StatusNotFound,
+	ERROR_ADMIN_SELF_OPERATION: http.StatusForbidden,
+	ERROR_ADMIN_OWNER_PROTECT:  http.StatusForbidden,
+	ERROR_ADMIN_LAST_SUPER:     http.StatusForbidden,
+	ERROR_INVITATION_INVALID:   http.StatusNotFound,
+
+	ERROR_TENANT_NOT_FOUND:  http.StatusNotFound,
+	ERROR_TENANT_CODE_EXIST: http.StatusConflict,
+
+	ERROR_TRASH_NOT_FOUND:        http.StatusNotFound,
+	ERROR_TRASH_RESTORE_CONFLICT: http.StatusConflict,
+	ERROR_TRASH_RESTORE_ORPHAN:   http.StatusConflict,
+
+	ERROR_DATA_JOB_NOT_FOUND:   http.StatusNotFound,
+	ERROR_DATA_JOB_UNSUPPORTED: http.StatusNotFound,
+	ERROR_IMPORT_FILE_INVALID:  http.StatusBadRequest,
+	ERROR_DATA_JOB_TOO_MANY:    http.StatusTooManyRequests,
+
+	ERROR_UPLOAD_TOO_LARGE:        http.StatusRequestEntityTooLarge,
+	ERROR_UPLOAD_TYPE_NOT_ALLOWED: http.StatusUnsupportedMediaType,
+	ERROR_UPLOAD_QUOTA_EXCEEDED:   http.StatusForbidden,
+	ERROR_UPLOAD_NOT_FOUND:        http.StatusNotFound,
+	ERROR_UPLOAD_STORAGE_FAIL:     http.StatusBadGateway,
+	ERROR_FILE_LINK_INVALID:       http.StatusForbidden,
+	ERROR_FILE_LINK_EXPIRED:       http.StatusGone,
+
+	ERROR_FILE_NOT_FOUND: http.StatusNotFound,
+
+	ERROR_NETWORK_TIMEOUT: http.StatusGatewayTimeout,
+	ERROR_NETWORK_REQUEST: http.StatusBadGateway,
+
+	ERROR_BUSINESS_LOGIC:   http.StatusUnprocessableEntity,
+	ERROR_DATA_VALIDATION:  http.StatusUnprocessableEntity,
+	ERROR_VERSION_CONFLICT: http.StatusConflict,
+
+	ERROR_SYSTEM_MAINTENANCE: http.StatusServiceUnavailable,
+	ERROR_SYSTEM_OVERLOAD:    http.StatusServiceUnavailable,
+
+	ERROR_ZINC_CONNECTION_FAILED: http.StatusServiceUnavailable,
+	ERROR_ZINC_INDEX_NOT_FOUND:   http.StatusNotFound,
+}
+
+// StatusOf 获取业务码对应的 HTTP 状态
+func StatusOf(code int) int {
+	if status, ok := StatusFlags[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
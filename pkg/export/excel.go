@@ -1,12 +1,15 @@
 package export
 
-import "justus/pkg/setting"
+import (
+	"justus/pkg/setting"
+	"justus/pkg/signurl"
+)
 
 const EXT = ".xlsx"
 
-// GetExcelFullUrl get the full access path of the Excel file
-func GetExcelFullUrl(name string) string {
-	return setting.AppSetting.PrefixUrl + "/" + GetExcelPath() + name
+// GetExcelSignedUrl 导出文件的签名下载地址，只有所属租户的任务引用该文件时才能下载
+func GetExcelSignedUrl(name string, tenantID uint) string {
+	return signurl.URL(signurl.Path(signurl.Exports, name), signurl.Options{TenantID: tenantID, Filename: name})
 }
 
 // GetExcelPath get the relative save path of the Excel file
@@ -18,3 +21,8 @@ func GetExcelPath() string {
 func GetExcelFullPath() string {
 	return setting.AppSetting.RuntimeRootPath + GetExcelPath()
 }
+
+// GetImportFullPath 导入上传文件的暂存目录，不在静态下载路径下
+func GetImportFullPath() string {
+	return setting.AppSetting.RuntimeRootPath + setting.AppSetting.ImportSavePath
+}
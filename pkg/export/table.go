@@ -0,0 +1,228 @@
+package export
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 支持的表格格式
+const (
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+)
+
+// CSV_EXT CSV 文件扩展名
+const CSV_EXT = ".csv"
+
+// ErrUnsupportedFormat 不支持的表格格式
+var ErrUnsupportedFormat = errors.New("export: unsupported format")
+
+// sheetName 导出文件只写一个工作表
+const sheetName = "Sheet1"
+
+// Writer 按行流式写出表格，Close 时落盘
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// Reader 按行读取表格，读完返回 io.EOF
+type Reader interface {
+	Next() ([]string, error)
+	Close() error
+}
+
+// Ext 格式对应的扩展名
+func Ext(format string) string {
+	if format == FormatCSV {
+		return CSV_EXT
+	}
+	return EXT
+}
+
+// FormatOf 按文件扩展名识别格式，无法识别时返回空串
+func FormatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case EXT:
+		return FormatXLSX
+	case CSV_EXT:
+		return FormatCSV
+	}
+	return ""
+}
+
+// NewFileName 生成不可猜测的文件名（下载地址即凭证），如 users-20240102150405-1a2b3c4d5e6f7a8b.xlsx
+func NewFileName(prefix, format string) string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%s-%s%s", prefix, time.Now().Format("20060102150405"), hex.EncodeToString(buf), Ext(format))
+}
+
+// NewWriter 在 path 创建指定格式的写入器，目录不存在时自动创建
+func NewWriter(format, path string) (Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatXLSX:
+		f := excelize.NewFile()
+		sw, err := f.NewStreamWriter(sheetName)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &xlsxWriter{file: f, stream: sw, path: path}, nil
+	case FormatCSV:
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		// 写入 BOM，Excel 打开 UTF-8 CSV 时才不会乱码
+		if _, err := f.WriteString("\uFEFF"); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &csvWriter{file: f, w: csv.NewWriter(f)}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// OpenReader 打开指定格式的表格文件，XLSX 读取第一个工作表
+func OpenReader(format, path string) (Reader, error) {
+	switch format {
+	case FormatXLSX:
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := f.Rows(f.GetSheetName(0))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &xlsxReader{file: f, rows: rows}, nil
+	case FormatCSV:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		return &csvReader{file: f, r: r, first: true}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// xlsxWriter 基于 excelize StreamWriter，内存占用与行数无关
+type xlsxWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	path   string
+	row    int
+}
+
+func (w *xlsxWriter) Write(row []string) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.SaveAs(w.path)
+}
+
+// csvWriter 写出时转义公式前缀，避免 CSV 注入
+type csvWriter struct {
+	file *os.File
+	w    *csv.Writer
+}
+
+func (w *csvWriter) Write(row []string) error {
+	out := make([]string, len(row))
+	for i, v := range row {
+		out[i] = escapeFormula(v)
+	}
+	return w.w.Write(out)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// escapeFormula 以 = + - @ 开头的单元格前加单引号，防止被表格软件当作公式执行
+func escapeFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func (r *xlsxReader) Next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return r.rows.Columns()
+}
+
+func (r *xlsxReader) Close() error {
+	_ = r.rows.Close()
+	return r.file.Close()
+}
+
+type csvReader struct {
+	file  *os.File
+	r     *csv.Reader
+	first bool
+}
+
+func (r *csvReader) Next() ([]string, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	if r.first {
+		r.first = false
+		if len(row) > 0 {
+			row[0] = strings.TrimPrefix(row[0], "\uFEFF")
+		}
+	}
+	return row, nil
+}
+
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
@@ -223,3 +223,8 @@ func (f *FFmpeg) QuickValidate() error {
 
 	return validateFFmpegPath(execPath)
 }
+
+// DetectPath 检测FFmpeg可执行文件路径（供健康检查等外部使用）
+func DetectPath() (string, error) {
+	return detectFFmpegPath()
+}
@@ -2,17 +2,27 @@ package file
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io"
 	"mime/multipart"
 	"os"
 	"path"
 )
 
-// GetSize get the file size
+// GetSize get the file size by seeking to the end, without reading the content into memory;
+// the read offset is restored afterwards
 func GetSize(f multipart.File) (int, error) {
-	content, err := ioutil.ReadAll(f)
-
-	return len(content), err
+	cur, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(cur, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return int(size), nil
 }
 
 // GetExt get the file ext
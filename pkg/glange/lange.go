@@ -1,24 +1,107 @@
 package glange
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"github.com/BurntSushi/toml"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"golang.org/x/text/language"
 )
-var langeBundle *i18n.Bundle
-func Setup() {
-	langeBundle = i18n.NewBundle(language.English)
-	langeBundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
-	//_, _ = bundle.LoadMessageFile("pkg/lange/active.yh.toml")
-	lange := []string{
-		"zh-Hans","zh-Hant","en","th","fr","es","fil","ms","pt","ja","id","af","am","bg","ca","hr","cs","da","nl","et","fi","de","el","he","hi","hu","is","it","ko","lv","lt","nb","pl","ro","ru","sr","sk","sl","sw","sv","tr","uk","vi","zu",
+
+// DefaultDir 语言文件目录（相对进程工作目录）
+const DefaultDir = "pkg/langefile"
+
+// Supported 已提供语言文件的 locale，与 active.<locale>.toml 对应
+var Supported = []string{
+	"zh-Hans", "zh-Hant", "en", "th", "fr", "es", "fil", "ms", "pt", "ja", "id", "af", "am", "bg", "ca", "hr", "cs", "da", "nl", "et", "fi", "de", "el", "he", "hi", "hu", "is", "it", "ko", "lv", "lt", "nb", "pl", "ro", "ru", "sr", "sk", "sl", "sw", "sv", "tr", "uk", "vi", "zu",
+}
+
+var (
+	langeBundle *i18n.Bundle
+	matcher     language.Matcher
+	// loadedTags 与 matcher 的候选顺序一致，下标即匹配结果
+	loadedTags []language.Tag
+)
+
+// Setup 加载 dir 下的语言文件
+// 单个文件缺失或解析失败不会中断加载，汇总后返回；英文文件不可用时不启用多语言
+func Setup(dir string) error {
+	bundle := i18n.NewBundle(language.English)
+	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+
+	var errs []error
+	for _, v := range Supported {
+		if _, err := bundle.LoadMessageFile(filepath.Join(dir, "active."+v+".toml")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	tags := bundle.LanguageTags()
+	if !hasTag(tags, language.English) {
+		return errors.Join(append(errs, fmt.Errorf("glange: english messages not loaded from %s", dir))...)
+	}
+
+	langeBundle = bundle
+	loadedTags = tags
+	matcher = language.NewMatcher(tags)
+	return errors.Join(errs...)
+}
+
+// Ready 语言包是否已加载
+func Ready() bool {
+	return langeBundle != nil
+}
+
+// Match 将 lange 头或 Accept-Language 解析为已加载的 locale
+// 支持 "zh-CN"、"en-US,en;q=0.9" 等形式，无法匹配时返回 false
+func Match(header string) (string, bool) {
+	header = strings.TrimSpace(header)
+	if matcher == nil || header == "" {
+		return "", false
 	}
-	for _,v := range lange{
-		langeBundle.MustLoadMessageFile("pkg/langefile/active."+v+".toml")
+	desired, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(desired) == 0 {
+		return "", false
 	}
+	_, index, confidence := matcher.Match(desired...)
+	if confidence == language.No {
+		return "", false
+	}
+	return loadedTags[index].String(), true
 }
 
-func GetlangeMessage(lange string,messageKey string)string{
-	localizer := i18n.NewLocalizer(langeBundle, lange)
-	return localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: messageKey})
-}
\ No newline at end of file
+// Localize 按 locale 翻译消息键，缺少译文时回退英文；键不存在返回 false，不会 panic
+func Localize(locale, key string, data map[string]interface{}) (string, bool) {
+	if langeBundle == nil || key == "" {
+		return "", false
+	}
+	localizer := i18n.NewLocalizer(langeBundle, locale)
+	msg, err := localizer.Localize(&i18n.LocalizeConfig{MessageID: key, TemplateData: data})
+	if msg == "" {
+		return "", false
+	}
+	var notFound *i18n.MessageNotFoundErr
+	if err != nil && !errors.As(err, &notFound) {
+		return "", false
+	}
+	return msg, true
+}
+
+// GetlangeMessage 获取翻译，键不存在时返回键本身
+func GetlangeMessage(lange string, messageKey string) string {
+	if msg, ok := Localize(lange, messageKey, nil); ok {
+		return msg
+	}
+	return messageKey
+}
+
+func hasTag(tags []language.Tag, tag language.Tag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
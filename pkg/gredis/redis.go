@@ -5,23 +5,18 @@ import (
 	"justus/internal/global"
 	"justus/pkg/setting"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
-var ctx = context.Background()
-
 // Setup Initialize the Redis instance
 func Setup() {
-	global.Redis = redis.NewClient(&redis.Options{
-		Addr:     setting.RedisSetting.Host,
-		Password: setting.RedisSetting.Password,
-		DB:       setting.RedisSetting.DB,
-	})
+	global.Redis = NewClient(setting.RedisSetting)
 
 	// 测试连接
-	_, err := global.Redis.Ping(ctx).Result()
+	_, err := global.Redis.Ping(context.Background()).Result()
 	if err != nil {
 		global.Logger.Warnf("Redis连接失败: %v", err)
 	} else {
@@ -29,13 +24,22 @@ func Setup() {
 	}
 }
 
+// NewClient 按配置创建 Redis 客户端（不做连接测试）
+func NewClient(cfg *setting.Redis) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Host,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
+
 // isRedisAvailable 检查Redis是否可用
 func isRedisAvailable() bool {
 	return global.Redis != nil
 }
 
 // Set a key/value
-func Set(key string, data interface{}, expiration time.Duration) error {
+func Set(ctx context.Context, key string, data interface{}, expiration time.Duration) error {
 	if !isRedisAvailable() {
 		global.Logger.Error("Redis未初始化，无法执行Set操作")
 		return redis.Nil
@@ -51,7 +55,7 @@ func Set(key string, data interface{}, expiration time.Duration) error {
 }
 
 // Get a key
-func Get(key string) string {
+func Get(ctx context.Context, key string) string {
 	if !isRedisAvailable() {
 		global.Logger.Error("Redis未初始化，无法执行Get操作")
 		return ""
@@ -66,7 +70,7 @@ func Get(key string) string {
 	return val
 }
 
-func Del(key string) (res int64, err error) {
+func Del(ctx context.Context, key string) (res int64, err error) {
 	if !isRedisAvailable() {
 		global.Logger.Error("Redis未初始化，无法执行Del操作")
 		return 0, redis.Nil
@@ -81,7 +85,66 @@ func Del(key string) (res int64, err error) {
 	return val, nil
 }
 
-func Zadd(key string, members redis.Z) (int64, error) {
+// Info 执行 INFO 命令并解析为键值对
+func Info(ctx context.Context, sections ...string) (map[string]string, error) {
+	if !isRedisAvailable() {
+		return nil, redis.Nil
+	}
+
+	raw, err := global.Redis.Info(ctx, sections...).Result()
+	if err != nil {
+		global.Logger.Errorf("redis info failed %v", err)
+		return nil, err
+	}
+	info := make(map[string]string)
+	for _, line := range strings.Split(raw, "\r\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			info[k] = v
+		}
+	}
+	return info, nil
+}
+
+// scanBatchSize 每轮 SCAN 的建议返回数量
+const scanBatchSize = 500
+
+// DelByPattern 按匹配模式批量删除key（基于SCAN，不阻塞Redis），返回删除数量
+func DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	if !isRedisAvailable() {
+		global.Logger.Error("Redis未初始化，无法执行DelByPattern操作")
+		return 0, redis.Nil
+	}
+
+	pattern = setting.RedisSetting.Prefix + pattern
+	var (
+		cursor  uint64
+		deleted int64
+	)
+	for {
+		keys, next, err := global.Redis.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			global.Logger.Errorf("redis scan failed %v", err)
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := global.Redis.Del(ctx, keys...).Result()
+			if err != nil {
+				global.Logger.Errorf("redis Del failed %v", err)
+				return deleted, err
+			}
+			deleted += n
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+func Zadd(ctx context.Context, key string, members redis.Z) (int64, error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZAdd(ctx, key, &members).Result()
 	if err != nil {
@@ -90,7 +153,7 @@ func Zadd(key string, members redis.Z) (int64, error) {
 	}
 	return val, nil
 }
-func Zscore(key string, uid int) (int64, int64) {
+func Zscore(ctx context.Context, key string, uid int) (int64, int64) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZScore(ctx, key, strconv.Itoa(uid)).Result()
 	if err != nil {
@@ -101,7 +164,7 @@ func Zscore(key string, uid int) (int64, int64) {
 
 }
 
-func Zrem(key string, members ...interface{}) (int64, error) {
+func Zrem(ctx context.Context, key string, members ...interface{}) (int64, error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZRem(ctx, key, members).Result()
 	if err != nil {
@@ -111,7 +174,12 @@ func Zrem(key string, members ...interface{}) (int64, error) {
 	return val, nil
 }
 
-func Incr(key string) (int64, error) {
+func Incr(ctx context.Context, key string) (int64, error) {
+	if !isRedisAvailable() {
+		global.Logger.Error("Redis未初始化，无法执行Incr操作")
+		return 0, redis.Nil
+	}
+
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.Incr(ctx, key).Result()
 	if err != nil {
@@ -121,7 +189,7 @@ func Incr(key string) (int64, error) {
 	return val, nil
 }
 
-func Decr(key string) (int64, error) {
+func Decr(ctx context.Context, key string) (int64, error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.Decr(ctx, key).Result()
 	if err != nil {
@@ -132,7 +200,7 @@ func Decr(key string) (int64, error) {
 }
 
 // 无序集合相关
-func SAdd(key string, members ...interface{}) (res int64, err error) {
+func SAdd(ctx context.Context, key string, members ...interface{}) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.SAdd(ctx, key, members).Result()
 	if err != nil {
@@ -142,7 +210,7 @@ func SAdd(key string, members ...interface{}) (res int64, err error) {
 	return val, nil
 }
 
-func SRem(key string, members ...interface{}) (res int64, err error) {
+func SRem(ctx context.Context, key string, members ...interface{}) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.SRem(ctx, key, members).Result()
 	if err != nil {
@@ -152,7 +220,7 @@ func SRem(key string, members ...interface{}) (res int64, err error) {
 	return val, nil
 }
 
-func SIsMember(key string, members interface{}) (res bool) {
+func SIsMember(ctx context.Context, key string, members interface{}) (res bool) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.SIsMember(ctx, key, members).Result()
 	if err != nil {
@@ -162,7 +230,7 @@ func SIsMember(key string, members interface{}) (res bool) {
 	return val
 }
 
-func SMembers(key string) (res []string) {
+func SMembers(ctx context.Context, key string) (res []string) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.SMembers(ctx, key).Result()
 	if err != nil {
@@ -173,7 +241,7 @@ func SMembers(key string) (res []string) {
 	return val
 }
 
-func Zcard(key string) (res int64, err error) {
+func Zcard(ctx context.Context, key string) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZCard(ctx, key).Result()
 	if err != nil {
@@ -183,7 +251,7 @@ func Zcard(key string) (res int64, err error) {
 	return val, nil
 }
 
-func ZunionStore(key string, z *redis.ZStore) (res int64, err error) {
+func ZunionStore(ctx context.Context, key string, z *redis.ZStore) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZUnionStore(ctx, key, z).Result()
 	if err != nil {
@@ -193,7 +261,7 @@ func ZunionStore(key string, z *redis.ZStore) (res int64, err error) {
 	return val, nil
 }
 
-func Zremrangebyrank(key string, start int64, end int64) (res int64, err error) {
+func Zremrangebyrank(ctx context.Context, key string, start int64, end int64) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZRemRangeByRank(ctx, key, start, end).Result()
 	if err != nil {
@@ -202,7 +270,7 @@ func Zremrangebyrank(key string, start int64, end int64) (res int64, err error)
 	}
 	return val, nil
 }
-func Zrangebyscore(key string, opt *redis.ZRangeBy) (res []string, err error) {
+func Zrangebyscore(ctx context.Context, key string, opt *redis.ZRangeBy) (res []string, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZRangeByScore(ctx, key, opt).Result()
 	if err != nil {
@@ -211,7 +279,7 @@ func Zrangebyscore(key string, opt *redis.ZRangeBy) (res []string, err error) {
 	}
 	return val, nil
 }
-func Zrange(key string, start int64, end int64) (res []string, err error) {
+func Zrange(ctx context.Context, key string, start int64, end int64) (res []string, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZRange(ctx, key, start, end).Result()
 	if err != nil {
@@ -221,7 +289,7 @@ func Zrange(key string, start int64, end int64) (res []string, err error) {
 	return val, nil
 
 }
-func Zrevrange(key string, start int64, end int64) (res []string, err error) {
+func Zrevrange(ctx context.Context, key string, start int64, end int64) (res []string, err error) {
 	key = setting.RedisSetting.Prefix + key
 	val, err := global.Redis.ZRevRange(ctx, key, start, end).Result()
 	if err != nil {
@@ -232,7 +300,7 @@ func Zrevrange(key string, start int64, end int64) (res []string, err error) {
 }
 
 // 队列  入队列
-func LPush(key string, values ...interface{}) (res int64, err error) {
+func LPush(ctx context.Context, key string, values ...interface{}) (res int64, err error) {
 	key = setting.RedisSetting.Prefix + key
 	result, err := global.Redis.LPush(ctx, key, values).Result()
 	if err != nil {
@@ -244,7 +312,7 @@ func LPush(key string, values ...interface{}) (res int64, err error) {
 }
 
 // 队列  出队列
-func RPop(key string) (res string, err error) {
+func RPop(ctx context.Context, key string) (res string, err error) {
 	key = setting.RedisSetting.Prefix + key
 	result, err := global.Redis.RPop(ctx, key).Result()
 	if err != nil {
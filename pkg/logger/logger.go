@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"errors"
 	"io"
 	"justus/internal/global"
+	"justus/internal/reqctx"
 	"justus/pkg/setting"
 	"log"
 	"os"
@@ -11,11 +13,16 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// closers 需要在进程退出前关闭的日志输出（缓冲型 Hook、文件等）
+var closers []io.Closer
+
 func New(s *setting.LoggerSettingS) (*logrus.Logger, error) {
 	logger := logrus.New()
 	logger.Formatter = &logrus.JSONFormatter{
 		PrettyPrint: false,
 	}
+	// 请求ID、trace ID 等字段须在 SLS/Zinc Hook 之前写入
+	logger.Hooks.Add(reqctx.LogHook{})
 
 	switch s.LogType {
 	case setting.LogFileType:
@@ -27,6 +34,7 @@ func New(s *setting.LoggerSettingS) (*logrus.Logger, error) {
 		}
 
 		logger.Out = io.MultiWriter(os.Stdout, fileWriter)
+		closers = append(closers, fileWriter)
 
 	case setting.LogFileSLS:
 		// 阿里云SLS日志服务
@@ -36,6 +44,7 @@ func New(s *setting.LoggerSettingS) (*logrus.Logger, error) {
 		}
 
 		logger.Hooks.Add(slsHook)
+		closers = append(closers, slsHook)
 
 		// 同时输出到控制台
 		logger.Out = os.Stdout
@@ -48,6 +57,7 @@ func New(s *setting.LoggerSettingS) (*logrus.Logger, error) {
 		}
 
 		logger.Hooks.Add(zincHook)
+		closers = append(closers, zincHook)
 
 		// 同时输出到控制台
 		logger.Out = os.Stdout
@@ -77,3 +87,15 @@ func setupLogger() error {
 
 	return nil
 }
+
+// Close 刷新并关闭所有日志输出，应在进程退出前最后调用
+func Close() error {
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	closers = nil
+	return errors.Join(errs...)
+}
@@ -104,6 +104,11 @@ func (hook *SLSHook) Fire(entry *logrus.Entry) error {
 	return nil
 }
 
+// Close 关闭SLS客户端
+func (hook *SLSHook) Close() error {
+	return hook.client.Close()
+}
+
 // Levels 实现logrus.Hook接口，返回hook处理的日志级别
 func (hook *SLSHook) Levels() []logrus.Level {
 	return logrus.AllLevels
@@ -1,13 +1,22 @@
 package qrcode
 
 import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
 	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/boombuler/barcode"
 	"github.com/boombuler/barcode/qr"
 
 	"justus/pkg/file"
 	"justus/pkg/setting"
+	"justus/pkg/signurl"
 	"justus/pkg/util"
 )
 
@@ -18,10 +27,19 @@ type QrCode struct {
 	Ext    string
 	Level  qr.ErrorCorrectionLevel
 	Mode   qr.Encoding
+	// Foreground、Background 模块与背景颜色，为空时黑码白底
+	Foreground color.Color
+	Background color.Color
+	// Margin 四周静区宽度（模块数），规范建议至少 4
+	Margin int
+	// Logo 居中叠加的图片，设置后纠错等级自动提升到 H
+	Logo image.Image
 }
 
 const (
 	EXT_JPG = ".jpg"
+	EXT_PNG = ".png"
+	EXT_SVG = ".svg"
 )
 
 // NewQrCode initialize instance
@@ -51,6 +69,11 @@ func GetQrCodeFullUrl(name string) string {
 	return setting.AppSetting.PrefixUrl + "/" + GetQrCodePath() + name
 }
 
+// GetQrCodeSignedUrl 二维码图片的签名访问地址
+func GetQrCodeSignedUrl(name string) string {
+	return signurl.URL(signurl.Path(signurl.QrCodes, name), signurl.Options{})
+}
+
 // GetQrCodeFileName get qr file name
 func GetQrCodeFileName(value string) string {
 	return util.EncodeMD5(value)
@@ -61,32 +84,105 @@ func (q *QrCode) GetQrCodeExt() string {
 	return q.Ext
 }
 
+// ContentType 输出格式对应的 MIME 类型
+func (q *QrCode) ContentType() string {
+	switch q.Ext {
+	case EXT_PNG:
+		return "image/png"
+	case EXT_SVG:
+		return "image/svg+xml"
+	}
+	return "image/jpeg"
+}
+
+// CacheKey 由内容与全部渲染选项（尺寸、纠错等级、编码模式、静区、颜色、Logo）决定的文件名（不含扩展名）
+// logoKey 标识 Logo 内容（如上传文件的 MD5），非空即视为带 Logo，可在加载 Logo 之前计算；
+// 为空而设置了 Logo 时按像素计算
+func (q *QrCode) CacheKey(logoKey string) string {
+	if logoKey == "" && q.Logo != nil {
+		logoKey = imageKey(q.Logo)
+	}
+	level := q.level()
+	if logoKey != "" {
+		level = qr.H
+	}
+	fg, bg := q.colors()
+	return GetQrCodeFileName(fmt.Sprintf("%s\n%dx%d/%d/%d/%d/%s/%s/%s", q.URL, q.Width, q.Height, level, q.Mode, q.Margin, hexColor(fg), hexColor(bg), logoKey))
+}
+
+// Write 按 Ext 输出 JPEG、PNG 或 SVG
+func (q *QrCode) Write(w io.Writer) error {
+	if q.Ext == EXT_SVG {
+		return q.writeSVG(w)
+	}
+	img, err := q.Image()
+	if err != nil {
+		return err
+	}
+	if q.Ext == EXT_PNG {
+		return png.Encode(w, img)
+	}
+	return jpeg.Encode(w, img, nil)
+}
+
 // Encode generate QR code
+// 文件名沿用 GetQrCodeFileName(URL)，与已有链接兼容但不区分尺寸等选项；同一内容需要多种样式时使用 CacheKey 与 EncodeAs
 func (q *QrCode) Encode(path string) (string, string, error) {
-	name := GetQrCodeFileName(q.URL) + q.GetQrCodeExt()
+	return q.EncodeAs(path, GetQrCodeFileName(q.URL)+q.GetQrCodeExt())
+}
+
+// EncodeAs 以 name 为文件名生成到 path 目录
+// 文件已存在时直接复用；先写临时文件再重命名，避免并发请求读到写了一半的文件
+func (q *QrCode) EncodeAs(path, name string) (string, string, error) {
 	src := path + name
 	if file.CheckNotExist(src) == true {
-		code, err := qr.Encode(q.URL, q.Level, q.Mode)
-		if err != nil {
+		if err := file.IsNotExistMkDir(path); err != nil {
 			return "", "", err
 		}
-
-		code, err = barcode.Scale(code, q.Width, q.Height)
+		tmp, err := os.CreateTemp(path, ".qrcode-*")
 		if err != nil {
 			return "", "", err
 		}
-
-		f, err := file.MustOpen(name, path)
-		if err != nil {
-			return "", "", err
+		if err = q.Write(tmp); err == nil {
+			err = tmp.Close()
+		} else {
+			_ = tmp.Close()
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), filepath.Join(path, name))
 		}
-		defer f.Close()
-
-		err = jpeg.Encode(f, code, nil)
 		if err != nil {
+			_ = os.Remove(tmp.Name())
 			return "", "", err
 		}
 	}
 
 	return name, path, nil
 }
+
+// PurgeCache 删除 path 目录下修改时间早于 cutoff 的二维码文件（含残留的临时文件），返回删除个数
+// 被删除的二维码在下次请求时重新生成
+func PurgeCache(path string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(path, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,158 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boombuler/barcode/qr"
+)
+
+// sameColor 忽略透明度比较 RGB
+func sameColor(a, b color.Color) bool {
+	return hexColor(a) == hexColor(b)
+}
+
+// TestImageModules 按模块中心取样还原矩阵，应与编码结果一致；静区与 Logo 区域为背景色
+func TestImageModules(t *testing.T) {
+	fg, bg := color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}, color.RGBA{R: 0xff, G: 0xee, B: 0xdd, A: 0xff}
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for i := range logo.Pix {
+		logo.Pix[i] = 0xff
+	}
+
+	for _, withLogo := range []bool{false, true} {
+		q := NewQrCode("https://example.com/invite?code=abc", 300, 300, qr.L, qr.Auto)
+		q.Ext, q.Margin, q.Foreground, q.Background = EXT_PNG, 4, fg, bg
+		if withLogo {
+			q.Logo = logo
+		}
+		var buf bytes.Buffer
+		if err := q.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil || img.Bounds() != image.Rect(0, 0, 300, 300) {
+			t.Fatalf("decode: %v %v", img.Bounds(), err)
+		}
+
+		l, _ := q.layout()
+		if withLogo && q.level() != qr.H {
+			t.Fatal("level not upgraded with logo")
+		}
+		if l.x0 < 4*l.scale || !sameColor(img.At(l.x0-1, l.y0-1), bg) || !sameColor(img.At(0, 0), bg) {
+			t.Fatalf("quiet zone: x0=%d scale=%d", l.x0, l.scale)
+		}
+		box := l.logoRect()
+		for y := 0; y < l.modules; y++ {
+			for x := 0; x < l.modules; x++ {
+				p := image.Pt(l.x0+x*l.scale+l.scale/2, l.y0+y*l.scale+l.scale/2)
+				if withLogo && p.In(box) {
+					if sameColor(img.At(p.X, p.Y), fg) {
+						t.Fatalf("module (%d,%d) drawn under logo", x, y)
+					}
+					continue
+				}
+				want := bg
+				if l.dark(x, y) {
+					want = fg
+				}
+				if !sameColor(img.At(p.X, p.Y), want) {
+					t.Fatalf("logo=%v module (%d,%d) = %v", withLogo, x, y, img.At(p.X, p.Y))
+				}
+			}
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	q := NewQrCode("hello", 200, 200, qr.M, qr.Auto)
+	q.Ext, q.Margin = EXT_SVG, 2
+	q.Logo = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	var buf bytes.Buffer
+	if err := q.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"svg"`
+		Width   int      `xml:"width,attr"`
+		Paths   []struct {
+			D string `xml:"d,attr"`
+		} `xml:"path"`
+		Images []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"image"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid svg: %v", err)
+	}
+	if doc.Width != 200 || len(doc.Paths) != 1 || !strings.HasPrefix(doc.Paths[0].D, "M") ||
+		len(doc.Images) != 1 || !strings.HasPrefix(doc.Images[0].Href, "data:image/png;base64,") {
+		t.Fatalf("svg = %+v", doc)
+	}
+	if q.ContentType() != "image/svg+xml" {
+		t.Fatal(q.ContentType())
+	}
+}
+
+func TestCacheKeyAndEncode(t *testing.T) {
+	plain := NewQrCode("hello", 100, 100, qr.M, qr.Auto)
+	for name, other := range map[string]*QrCode{
+		"size":  NewQrCode("hello", 200, 200, qr.M, qr.Auto),
+		"level": NewQrCode("hello", 100, 100, qr.H, qr.Auto),
+		"mode":  NewQrCode("hello", 100, 100, qr.M, qr.Unicode),
+	} {
+		if other.CacheKey("") == plain.CacheKey("") {
+			t.Fatalf("cache key ignores %s", name)
+		}
+	}
+	styled := NewQrCode("hello", 100, 100, qr.M, qr.Auto)
+	styled.Margin = 4
+	withLogo := *styled
+	if styled.CacheKey("") == plain.CacheKey("") || withLogo.CacheKey("md5-a") == withLogo.CacheKey("md5-b") || withLogo.CacheKey("md5-a") == styled.CacheKey("") {
+		t.Fatal("cache key ignores options")
+	}
+
+	dir := t.TempDir() + "/qr/"
+	name, path, err := plain.Encode(dir)
+	if err != nil || name != GetQrCodeFileName("hello")+EXT_JPG || path != dir {
+		t.Fatalf("encode: %s %s %v", name, path, err)
+	}
+	f, err := os.Open(dir + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if cfg, format, err := image.DecodeConfig(f); err != nil || format != "jpeg" || cfg.Width != 100 {
+		t.Fatalf("encoded file: %s %v %v", format, cfg, err)
+	}
+
+	if _, err := ParseColor("#12g"); err == nil {
+		t.Fatal("invalid color accepted")
+	}
+	if c, err := ParseColor("#0f8"); err != nil || hexColor(c) != "#00ff88" {
+		t.Fatalf("short color: %v %v", c, err)
+	}
+
+	fresh, _, err := NewQrCode("fresh", 100, 100, qr.M, qr.Auto).Encode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(dir+name, old, old)
+	if n, err := PurgeCache(dir, time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Fatalf("purge: %d %v", n, err)
+	}
+	if _, err := os.Stat(dir + name); !os.IsNotExist(err) {
+		t.Fatal("expired qrcode kept")
+	}
+	if _, err := os.Stat(dir + fresh); err != nil {
+		t.Fatalf("fresh qrcode removed: %v", err)
+	}
+}
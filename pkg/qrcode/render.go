@@ -0,0 +1,212 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/draw"
+)
+
+// ErrContent 内容无法编码（过长或含不支持的字符）
+var ErrContent = errors.New("qrcode: content cannot be encoded")
+
+// logoRatio Logo 边长占码区（不含静区）的比例；H 级可纠正约 30% 的损坏，1/5 边长只遮挡 4% 面积
+const logoRatio = 5
+
+// ParseColor 解析 #RGB 或 #RRGGBB 颜色，# 可省略
+func ParseColor(s string) (color.Color, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return nil, fmt.Errorf("qrcode: invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("qrcode: invalid color %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// hexColor 颜色的 #rrggbb 表示，透明度被忽略
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func (q *QrCode) colors() (fg, bg color.Color) {
+	fg, bg = q.Foreground, q.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	return fg, bg
+}
+
+// level 叠加 Logo 时纠错等级至少为 H，否则 Logo 遮挡的模块可能无法恢复
+func (q *QrCode) level() qr.ErrorCorrectionLevel {
+	if q.Logo != nil {
+		return qr.H
+	}
+	return q.Level
+}
+
+// imageKey 图片像素内容的摘要
+func imageKey(img image.Image) string {
+	h := md5.New()
+	b := img.Bounds()
+	var px [8]byte
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			binary.BigEndian.PutUint16(px[0:], uint16(r))
+			binary.BigEndian.PutUint16(px[2:], uint16(g))
+			binary.BigEndian.PutUint16(px[4:], uint16(bl))
+			binary.BigEndian.PutUint16(px[6:], uint16(a))
+			h.Write(px[:])
+		}
+	}
+	return fmt.Sprintf("%dx%d:%x", b.Dx(), b.Dy(), h.Sum(nil))
+}
+
+// layout 二维码模块与像素尺寸：scale 为每个模块的像素数，码区在画布内居中
+type layout struct {
+	code          barcode.Barcode
+	modules       int
+	scale         int
+	width, height int
+	// x0、y0 码区（不含静区）左上角像素坐标
+	x0, y0 int
+}
+
+func (q *QrCode) layout() (*layout, error) {
+	code, err := qr.Encode(q.URL, q.level(), q.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContent, err)
+	}
+	l := &layout{code: code, modules: code.Bounds().Dx(), width: q.Width, height: q.Height}
+	total := l.modules + 2*q.Margin
+	l.scale = min(l.width, l.height) / total
+	if l.scale < 1 {
+		// 尺寸不足以容纳全部模块时按每模块 1 像素输出
+		l.scale = 1
+		l.width, l.height = max(l.width, total), max(l.height, total)
+	}
+	l.x0 = (l.width-total*l.scale)/2 + q.Margin*l.scale
+	l.y0 = (l.height-total*l.scale)/2 + q.Margin*l.scale
+	return l, nil
+}
+
+// dark 模块是否为深色
+func (l *layout) dark(x, y int) bool {
+	r, _, _, _ := l.code.At(x, y).RGBA()
+	return r < 0x8000
+}
+
+// logoRect 码区中央的 Logo 区域（含与背景同色的留白）
+func (l *layout) logoRect() image.Rectangle {
+	side := l.modules * l.scale / logoRatio
+	cx, cy := l.x0+l.modules*l.scale/2, l.y0+l.modules*l.scale/2
+	return image.Rect(cx-side/2, cy-side/2, cx-side/2+side, cy-side/2+side)
+}
+
+// Image 渲染为位图，尺寸为 Width×Height
+func (q *QrCode) Image() (image.Image, error) {
+	l, err := q.layout()
+	if err != nil {
+		return nil, err
+	}
+	fg, bg := q.colors()
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	dark := image.NewUniform(fg)
+	for y := 0; y < l.modules; y++ {
+		for x := 0; x < l.modules; x++ {
+			if l.dark(x, y) {
+				r := image.Rect(l.x0+x*l.scale, l.y0+y*l.scale, l.x0+(x+1)*l.scale, l.y0+(y+1)*l.scale)
+				draw.Draw(img, r, dark, image.Point{}, draw.Src)
+			}
+		}
+	}
+	if q.Logo != nil {
+		box := l.logoRect()
+		draw.Draw(img, box, image.NewUniform(bg), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(img, fitLogo(q.Logo.Bounds(), inset(box)), q.Logo, q.Logo.Bounds(), draw.Over, nil)
+	}
+	return img, nil
+}
+
+// writeSVG 以 viewBox 为单位的矢量输出，每行连续的深色模块合并为一个矩形；Logo 以 PNG 内嵌
+func (q *QrCode) writeSVG(w io.Writer) error {
+	l, err := q.layout()
+	if err != nil {
+		return err
+	}
+	fg, bg := q.colors()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, l.width, l.height, l.width, l.height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/><path fill="%s" d="`, hexColor(bg), hexColor(fg))
+	for y := 0; y < l.modules; y++ {
+		for x := 0; x < l.modules; {
+			if !l.dark(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for x+run < l.modules && l.dark(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(bw, "M%d %dh%dv%dh-%dz", l.x0+x*l.scale, l.y0+y*l.scale, run*l.scale, l.scale, run*l.scale)
+			x += run
+		}
+	}
+	bw.WriteString(`"/>`)
+	if q.Logo != nil {
+		box := l.logoRect()
+		dst := fitLogo(q.Logo.Bounds(), inset(box))
+		logo := image.NewRGBA(image.Rect(0, 0, dst.Dx(), dst.Dy()))
+		draw.CatmullRom.Scale(logo, logo.Bounds(), q.Logo, q.Logo.Bounds(), draw.Src, nil)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, logo); err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, box.Min.X, box.Min.Y, box.Dx(), box.Dy(), hexColor(bg))
+		fmt.Fprintf(bw, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`, dst.Min.X, dst.Min.Y, dst.Dx(), dst.Dy(), base64.StdEncoding.EncodeToString(buf.Bytes()))
+	}
+	bw.WriteString(`</svg>`)
+	return bw.Flush()
+}
+
+// inset Logo 四周留出约 1/10 边长的背景色留白
+func inset(box image.Rectangle) image.Rectangle {
+	pad := box.Dx() / 10
+	return box.Inset(pad)
+}
+
+// fitLogo 在 box 内等比缩放并居中
+func fitLogo(src, box image.Rectangle) image.Rectangle {
+	w, h := box.Dx(), box.Dy()
+	if src.Dx()*h > src.Dy()*w {
+		h = max(1, src.Dy()*w/src.Dx())
+	} else {
+		w = max(1, src.Dx()*h/src.Dy())
+	}
+	x, y := box.Min.X+(box.Dx()-w)/2, box.Min.Y+(box.Dy()-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
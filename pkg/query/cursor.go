@@ -0,0 +1,118 @@
+package query
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cursorPayload 游标内容：排序签名 + 上一页末行的排序值
+// 签名不一致（换了排序方式）时游标失效，避免按错误的列比较
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// errCursor 游标无法解析或与当前排序不匹配
+var errCursor = errors.New("query: invalid cursor")
+
+// signature 排序签名，如 "-created_at,id"
+func signature(sorts []Sort) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		} else {
+			parts[i] = s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeCursor 解析游标并按字段类型还原排序值
+func decodeCursor(cursor string, sorts []Sort, schema *Schema) ([]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, errCursor
+	}
+	if payload.Sort != signature(sorts) || len(payload.Values) != len(sorts) {
+		return nil, errCursor
+	}
+	values := make([]interface{}, len(sorts))
+	for i, s := range sorts {
+		v, err := parseValue(schema.Fields[s.Field].Kind, payload.Values[i])
+		if err != nil {
+			return nil, errCursor
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// encodeCursor 取行记录上各排序列的值生成下一页游标
+func encodeCursor(db *gorm.DB, sorts []Sort, schema *Schema, row interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(row); err != nil {
+		return "", err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(row))
+	payload := cursorPayload{Sort: signature(sorts), Values: make([]string, len(sorts))}
+	for i, s := range sorts {
+		column := schema.Fields[s.Field].Column
+		if j := strings.LastIndexByte(column, '.'); j >= 0 {
+			column = column[j+1:]
+		}
+		field := stmt.Schema.LookUpField(column)
+		if field == nil {
+			return "", fmt.Errorf("query: column %s not found in %s", column, stmt.Schema.Name)
+		}
+		v, _ := field.ValueOf(context.Background(), rv)
+		text, err := formatValue(v)
+		if err != nil {
+			return "", err
+		}
+		payload.Values[i] = text
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// formatValue 将列值格式化为可被 parseValue 还原的文本
+func formatValue(v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		v = dv
+	}
+	switch x := v.(type) {
+	case nil:
+		return "", fmt.Errorf("query: sort column is null")
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	case []byte:
+		return string(x), nil
+	case string:
+		return x, nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"justus/pkg/setting"
+)
+
+// Kind 字段值类型，决定过滤值与游标值的解析方式
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Bool
+	Time
+)
+
+// Op 过滤操作符
+type Op string
+
+const (
+	Eq   Op = "eq"
+	Ne   Op = "ne"
+	Gt   Op = "gt"
+	Gte  Op = "gte"
+	Lt   Op = "lt"
+	Lte  Op = "lte"
+	In   Op = "in"
+	Like Op = "like"
+)
+
+// 常用操作符组合
+var (
+	// Exact 精确匹配类字段（状态、ID 等）
+	Exact = []Op{Eq, Ne, In}
+	// Range 可比较字段（数值、时间）
+	Range = []Op{Eq, Ne, Gt, Gte, Lt, Lte, In}
+	// Text 文本字段
+	Text = []Op{Eq, Ne, In, Like}
+)
+
+const (
+	// DefaultMaxLimit 未配置 MaxLimit 时单页最大条数
+	DefaultMaxLimit = 100
+	// fallbackLimit AppSetting.PageSize 未配置时的默认条数
+	fallbackLimit = 10
+	// maxInValues in 操作符最多允许的值个数
+	maxInValues = 100
+)
+
+// Field 对外暴露的可查询字段，名称与响应 JSON 字段一致
+type Field struct {
+	// Column 数据库列，可带表名前缀；为空表示计算字段，只能出现在 fields 中
+	Column string
+	Kind   Kind
+	// Ops 允许的过滤操作符，为空表示不可过滤
+	Ops []Op
+	// Sortable 是否允许排序；可为 NULL 的列不要开启，否则游标翻页会跳过 NULL 行
+	Sortable bool
+}
+
+// Schema 列表接口的查询白名单
+type Schema struct {
+	Fields map[string]Field
+	// Search keyword 模糊匹配的列
+	Search []string
+	// Key 唯一且可排序的字段（通常为 id），追加在排序末尾保证顺序与游标稳定
+	Key         string
+	DefaultSort []Sort
+	// DefaultLimit 为 0 时使用 AppSetting.PageSize
+	DefaultLimit int
+	// MaxLimit 为 0 时使用 DefaultMaxLimit
+	MaxLimit int
+}
+
+func (s *Schema) defaultLimit() int {
+	if s.DefaultLimit > 0 {
+		return s.DefaultLimit
+	}
+	if setting.AppSetting != nil && setting.AppSetting.PageSize > 0 {
+		return setting.AppSetting.PageSize
+	}
+	return fallbackLimit
+}
+
+func (s *Schema) maxLimit() int {
+	if s.MaxLimit > 0 {
+		return s.MaxLimit
+	}
+	return DefaultMaxLimit
+}
+
+// filterable 可过滤字段名（排序后），用于错误提示
+func (s *Schema) filterable() string {
+	var names []string
+	for name, f := range s.Fields {
+		if f.Column != "" && len(f.Ops) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// sortable 可排序字段名（排序后），用于错误提示
+func (s *Schema) sortable() string {
+	var names []string
+	for name, f := range s.Fields {
+		if f.Column != "" && f.Sortable {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// selectable 可出现在 fields 中的字段名（排序后），用于错误提示
+func (s *Schema) selectable() string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func (f Field) allows(op Op) bool {
+	for _, o := range f.Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) opNames() string {
+	names := make([]string, len(f.Ops))
+	for i, o := range f.Ops {
+		names[i] = string(o)
+	}
+	return strings.Join(names, " ")
+}
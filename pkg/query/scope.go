@@ -0,0 +1,138 @@
+package query
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Pagination 响应中的分页信息
+// 页码模式返回 page 与 total；游标模式不统计总数，仅凭 has_more/next_cursor 继续翻页
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Page 列表查询结果
+type Page[T any] struct {
+	Items      []T
+	Pagination Pagination
+}
+
+// Find 在 base 上按 spec 查询一页数据
+// base 只应包含业务条件（如租户隔离），过滤、排序与分页由 spec 负责；页码模式同时统计总数
+func Find[T any](base *gorm.DB, spec *Spec) (*Page[T], error) {
+	db := spec.Where(base).Session(&gorm.Session{})
+	page := &Page[T]{Pagination: Pagination{Page: spec.Page, Limit: spec.Limit}}
+
+	if !spec.Cursor() {
+		var total int64
+		if err := db.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Pagination.Total = &total
+	}
+
+	var rows []T
+	if err := spec.Paginate(spec.Order(db)).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > spec.Limit {
+		rows = rows[:spec.Limit]
+		cursor, err := encodeCursor(base, spec.Sorts, spec.schema, rows[len(rows)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.Pagination.HasMore = true
+		page.Pagination.NextCursor = cursor
+	}
+	page.Items = rows
+	return page, nil
+}
+
+// Where 过滤与关键字条件（GORM scope），不含排序与分页，可单独用于 Count
+func (s *Spec) Where(db *gorm.DB) *gorm.DB {
+	for _, f := range s.Filters {
+		column := s.schema.Fields[f.Field].Column
+		switch f.Op {
+		case Eq:
+			db = db.Where(column+" = ?", f.values[0])
+		case Ne:
+			db = db.Where(column+" <> ?", f.values[0])
+		case Gt:
+			db = db.Where(column+" > ?", f.values[0])
+		case Gte:
+			db = db.Where(column+" >= ?", f.values[0])
+		case Lt:
+			db = db.Where(column+" < ?", f.values[0])
+		case Lte:
+			db = db.Where(column+" <= ?", f.values[0])
+		case In:
+			db = db.Where(column+" IN ?", f.values)
+		case Like:
+			db = db.Where(column+" LIKE ? ESCAPE '!'", contains(f.Raw))
+		}
+	}
+	if s.Keyword != "" && len(s.schema.Search) > 0 {
+		like := contains(s.Keyword)
+		conds := make([]string, len(s.schema.Search))
+		args := make([]interface{}, len(s.schema.Search))
+		for i, column := range s.schema.Search {
+			conds[i] = column + " LIKE ? ESCAPE '!'"
+			args[i] = like
+		}
+		db = db.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	return db
+}
+
+// Order 排序（GORM scope），末尾包含唯一键
+func (s *Spec) Order(db *gorm.DB) *gorm.DB {
+	for _, sort := range s.Sorts {
+		column := s.schema.Fields[sort.Field].Column
+		if sort.Desc {
+			db = db.Order(column + " DESC")
+		} else {
+			db = db.Order(column + " ASC")
+		}
+	}
+	return db
+}
+
+// Paginate 游标条件或页码偏移（GORM scope），多取一条用于判断是否还有下一页
+func (s *Spec) Paginate(db *gorm.DB) *gorm.DB {
+	if s.Cursor() && len(s.Sorts) > 0 {
+		// 展开为 (a > ?) OR (a = ? AND b < ?) OR …，兼容各列排序方向不同的情况
+		var (
+			ors  []string
+			args []interface{}
+		)
+		for i, sort := range s.Sorts {
+			var ands []string
+			for j := 0; j < i; j++ {
+				ands = append(ands, s.schema.Fields[s.Sorts[j].Field].Column+" = ?")
+				args = append(args, s.after[j])
+			}
+			cmp := " > ?"
+			if sort.Desc {
+				cmp = " < ?"
+			}
+			ands = append(ands, s.schema.Fields[sort.Field].Column+cmp)
+			args = append(args, s.after[i])
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+		db = db.Where("("+strings.Join(ors, " OR ")+")", args...)
+	} else if s.Page > 1 {
+		db = db.Offset((s.Page - 1) * s.Limit)
+	}
+	return db.Limit(s.Limit + 1)
+}
+
+// contains 转义 LIKE 通配符后拼成包含匹配，转义符为 '!'（MySQL 与 SQLite 通用）
+func contains(text string) string {
+	r := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return "%" + r.Replace(text) + "%"
+}
@@ -0,0 +1,325 @@
+package query
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"justus/pkg/e"
+)
+
+// Filter 过滤条件
+type Filter struct {
+	Field string
+	Op    Op
+	// Raw 原始参数值，用于响应回显
+	Raw    string
+	values []interface{}
+}
+
+// Sort 排序字段
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Spec 解析后的列表查询
+//
+// 参数约定：
+//
+//	filter[status]=1、filter[created_at][gte]=2024-01-01、filter[id][in]=1,2,3
+//	status=1           可过滤且支持 eq 的字段可直接作为参数（兼容旧接口）
+//	keyword=foo        在 Schema.Search 列上模糊匹配
+//	sort=-created_at,id 多字段排序，"-" 表示倒序；兼容 sort_by + order
+//	fields=id,username  稀疏字段，仅裁剪响应
+//	limit=20           单页条数，兼容 page_size
+//	page=2 / cursor=…  页码或游标翻页，同时出现时以游标为准
+type Spec struct {
+	Filters []Filter
+	Sorts   []Sort
+	Fields  []string
+	Keyword string
+	Limit   int
+	// Page 页码模式下的页码；游标模式为 0
+	Page int
+
+	schema *Schema
+	// after 游标中的上一页末行排序值，nil 表示页码模式
+	after []interface{}
+}
+
+// reserved 非过滤用途的参数名
+var reserved = map[string]bool{
+	"keyword": true, "sort": true, "sort_by": true, "order": true, "fields": true,
+	"limit": true, "page_size": true, "page": true, "cursor": true, "filter": true,
+}
+
+// Parse 按 schema 白名单解析查询参数，参数不合法时返回带字段明细的校验错误
+func Parse(values url.Values, schema *Schema) (*Spec, error) {
+	p := &parser{values: values, schema: schema}
+	spec := &Spec{schema: schema, Page: 1}
+
+	spec.Keyword = strings.TrimSpace(values.Get("keyword"))
+	if len(schema.Search) == 0 {
+		spec.Keyword = ""
+	}
+	spec.Limit = p.limit()
+	if page := strings.TrimSpace(values.Get("page")); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			p.fail("page", "min", "1", "不能小于 1")
+		} else {
+			spec.Page = n
+		}
+	}
+	spec.Filters = p.filters()
+	spec.Sorts = p.sorts()
+	spec.Fields = p.fields()
+
+	if cursor := values.Get("cursor"); cursor != "" && len(p.errs) == 0 {
+		after, err := decodeCursor(cursor, spec.Sorts, schema)
+		if err != nil {
+			p.fail("cursor", "cursor", "", "游标无效")
+		} else {
+			spec.after = after
+			spec.Page = 0
+		}
+	}
+
+	if len(p.errs) > 0 {
+		return nil, e.Validation(p.errs...)
+	}
+	return spec, nil
+}
+
+// Cursor 是否为游标翻页
+func (s *Spec) Cursor() bool {
+	return s.after != nil
+}
+
+// Echo 回显已生效的关键字与过滤条件，键与请求参数写法一致
+func (s *Spec) Echo() map[string]interface{} {
+	echo := map[string]interface{}{"keyword": s.Keyword}
+	for _, f := range s.Filters {
+		if f.Op == Eq {
+			echo[f.Field] = f.Raw
+			continue
+		}
+		echo[f.Field+"["+string(f.Op)+"]"] = f.Raw
+	}
+	return echo
+}
+
+// Project 按 fields 参数裁剪列表项的输出字段；未指定 fields 时原样返回
+func (s *Spec) Project(items interface{}) interface{} {
+	if len(s.Fields) == 0 {
+		return items
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return items
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return items
+	}
+	projected := make([]map[string]json.RawMessage, len(rows))
+	for i, row := range rows {
+		keep := make(map[string]json.RawMessage, len(s.Fields))
+		for _, name := range s.Fields {
+			if v, ok := row[name]; ok {
+				keep[name] = v
+			}
+		}
+		projected[i] = keep
+	}
+	return projected
+}
+
+// parser 收集解析过程中的全部字段错误，一次性返回
+type parser struct {
+	values url.Values
+	schema *Schema
+	errs   []e.FieldError
+}
+
+func (p *parser) fail(field, rule, param, message string) {
+	p.errs = append(p.errs, e.FieldError{Field: field, Rule: rule, Param: param, Message: message})
+}
+
+func (p *parser) limit() int {
+	name := "limit"
+	raw := p.values.Get(name)
+	if raw == "" {
+		name = "page_size"
+		raw = p.values.Get(name)
+	}
+	if raw == "" {
+		return p.schema.defaultLimit()
+	}
+	n, err := strconv.Atoi(raw)
+	maxLimit := p.schema.maxLimit()
+	switch {
+	case err != nil || n < 1:
+		p.fail(name, "min", "1", "不能小于 1")
+	case n > maxLimit:
+		p.fail(name, "max", strconv.Itoa(maxLimit), "不能大于 "+strconv.Itoa(maxLimit))
+	}
+	return n
+}
+
+func (p *parser) filters() []Filter {
+	var filters []Filter
+	keys := make([]string, 0, len(p.values))
+	for key := range p.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := p.values.Get(key)
+		name, op, explicit := splitFilterKey(key)
+		if !explicit {
+			// 兼容旧接口的 status=1 写法：仅限可精确匹配的字段，其他未知参数（签名等）忽略
+			if reserved[key] {
+				continue
+			}
+			if f, ok := p.schema.Fields[key]; !ok || f.Column == "" || !f.allows(Eq) {
+				continue
+			}
+		}
+		if raw == "" {
+			continue
+		}
+		field, ok := p.schema.Fields[name]
+		if !ok || field.Column == "" || len(field.Ops) == 0 {
+			p.fail(key, "oneof", p.schema.filterable(), "可过滤字段: "+p.schema.filterable())
+			continue
+		}
+		if !field.allows(op) {
+			p.fail(key, "oneof", field.opNames(), "支持的操作符: "+field.opNames())
+			continue
+		}
+
+		parts := []string{raw}
+		if op == In {
+			parts = strings.Split(raw, ",")
+			if len(parts) > maxInValues {
+				p.fail(key, "max", strconv.Itoa(maxInValues), "最多 "+strconv.Itoa(maxInValues)+" 个值")
+				continue
+			}
+		}
+		values := make([]interface{}, 0, len(parts))
+		valid := true
+		for _, part := range parts {
+			v, err := parseValue(field.Kind, strings.TrimSpace(part))
+			if err != nil {
+				p.fail(key, "format", "", "格式不正确")
+				valid = false
+				break
+			}
+			values = append(values, v)
+		}
+		if valid {
+			filters = append(filters, Filter{Field: name, Op: op, Raw: raw, values: values})
+		}
+	}
+	return filters
+}
+
+func (p *parser) sorts() []Sort {
+	var sorts []Sort
+	raw := p.values.Get("sort")
+	if raw == "" && p.values.Get("sort_by") != "" {
+		raw = p.values.Get("sort_by")
+		if strings.EqualFold(p.values.Get("order"), "desc") {
+			raw = "-" + raw
+		}
+	}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		s := Sort{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if f, ok := p.schema.Fields[s.Field]; !ok || f.Column == "" || !f.Sortable {
+			p.fail("sort", "oneof", p.schema.sortable(), "可排序字段: "+p.schema.sortable())
+			continue
+		}
+		if !seen[s.Field] {
+			seen[s.Field] = true
+			sorts = append(sorts, s)
+		}
+	}
+	if len(sorts) == 0 {
+		for _, s := range p.schema.DefaultSort {
+			seen[s.Field] = true
+			sorts = append(sorts, s)
+		}
+	}
+	// 末尾追加唯一键，保证同值行顺序确定，游标才不会漏行或重复
+	if key := p.schema.Key; key != "" && !seen[key] {
+		desc := len(sorts) > 0 && sorts[len(sorts)-1].Desc
+		sorts = append(sorts, Sort{Field: key, Desc: desc})
+	}
+	return sorts
+}
+
+func (p *parser) fields() []string {
+	raw := p.values.Get("fields")
+	if raw == "" {
+		return nil
+	}
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := p.schema.Fields[name]; !ok {
+			p.fail("fields", "oneof", p.schema.selectable(), "可选字段: "+p.schema.selectable())
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// splitFilterKey 解析 filter[name] 与 filter[name][op]；非 filter[...] 形式按 eq 处理
+func splitFilterKey(key string) (name string, op Op, explicit bool) {
+	if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+		return key, Eq, false
+	}
+	inner := strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]")
+	if i := strings.Index(inner, "]["); i >= 0 {
+		return inner[:i], Op(inner[i+2:]), true
+	}
+	return inner, Eq, true
+}
+
+// timeLayouts 过滤值与游标接受的时间格式，无时区时按本地时区解析
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// parseValue 按字段类型解析参数值
+func parseValue(kind Kind, raw string) (interface{}, error) {
+	switch kind {
+	case Int:
+		return strconv.ParseInt(raw, 10, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Time:
+		var err error
+		for _, layout := range timeLayouts {
+			var t time.Time
+			if t, err = time.ParseInLocation(layout, raw, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, err
+	}
+	return raw, nil
+}
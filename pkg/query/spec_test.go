@@ -0,0 +1,85 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"justus/pkg/e"
+)
+
+var testSchema = &Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Kind: Int, Ops: Range, Sortable: true},
+		"name":       {Column: "name", Ops: Text, Sortable: true},
+		"status":     {Column: "status", Kind: Int, Ops: Exact},
+		"created_at": {Column: "created_at", Kind: Time, Ops: Range, Sortable: true},
+		"full_name":  {},
+	},
+	Search:      []string{"name"},
+	Key:         "id",
+	DefaultSort: []Sort{{Field: "created_at", Desc: true}},
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		sort    string
+		filters int
+		rules   []string
+	}{
+		{"defaults", "", "-created_at,-id", 0, nil},
+		{"legacy status", "status=1&keyword=a&page=2&page_size=5", "-created_at,-id", 1, nil},
+		{"explicit ops", "filter[id][in]=1,2&filter[created_at][gte]=2024-01-01&sort=name,-id", "name,-id", 2, nil},
+		{"legacy sort", "sort_by=name&order=desc", "-name,-id", 0, nil},
+		{"unknown params ignored", "sign=x&timestamp=1", "-created_at,-id", 0, nil},
+		{"bad field and op", "filter[password]=x&filter[status][like]=1", "", 0, []string{"oneof", "oneof"}},
+		{"bad value", "filter[id]=abc", "", 0, []string{"format"}},
+		{"bad sort and fields", "sort=full_name&fields=secret", "", 0, []string{"oneof", "oneof"}},
+		{"limit bounds", "limit=1000", "", 0, []string{"max"}},
+		{"bad cursor", "cursor=abc", "", 0, []string{"cursor"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tc.query)
+			spec, err := Parse(values, testSchema)
+			if tc.rules != nil {
+				var ae *e.Error
+				if !errors.As(err, &ae) || ae.Code != e.ERROR_DATA_VALIDATION || len(ae.Fields) != len(tc.rules) {
+					t.Fatalf("want %d field errors, got %v", len(tc.rules), err)
+				}
+				for i, rule := range tc.rules {
+					if ae.Fields[i].Rule != rule {
+						t.Fatalf("field %d: rule=%s want %s", i, ae.Fields[i].Rule, rule)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := signature(spec.Sorts); got != tc.sort {
+				t.Fatalf("sort=%s want %s", got, tc.sort)
+			}
+			if len(spec.Filters) != tc.filters {
+				t.Fatalf("filters=%d want %d", len(spec.Filters), tc.filters)
+			}
+		})
+	}
+}
+
+func TestCursorRequiresSameSort(t *testing.T) {
+	sorts := []Sort{{Field: "name"}, {Field: "id"}}
+	raw, _ := json.Marshal(cursorPayload{Sort: signature(sorts), Values: []string{"x", "7"}})
+	cursor := base64.RawURLEncoding.EncodeToString(raw)
+
+	if _, err := decodeCursor(cursor, sorts, testSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeCursor(cursor, []Sort{{Field: "name", Desc: true}, {Field: "id"}}, testSchema); err == nil {
+		t.Fatal("cursor accepted for a different sort")
+	}
+}
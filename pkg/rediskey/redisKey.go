@@ -7,9 +7,39 @@ func GetWhiteListKey() string {
 	return WhiteList
 }
 
-// 多租户Key前缀
+// 多租户Key前缀（全局前缀 RedisSetting.Prefix 由 gredis 统一追加，这里不再重复拼接）
 func TenantPrefix(tenantID uint) string {
-	return "justus:tenant:" + itoa(tenantID) + ":"
+	return "tenant:" + itoa(tenantID) + ":"
+}
+
+// AllTenantsModulePattern 全部租户下指定模块key的匹配模式（平台级清理使用）
+func AllTenantsModulePattern(module string) string {
+	return "tenant:*:" + module + ":*"
+}
+
+// AllTenantsMenuWhitelistPattern 全部租户菜单白名单key的匹配模式
+func AllTenantsMenuWhitelistPattern() string {
+	return "tenant:*:menus:whitelist"
+}
+
+// AllTenantsMenuTreePattern 全部租户菜单树缓存key的匹配模式
+func AllTenantsMenuTreePattern() string {
+	return "tenant:*:menus:tree:*"
+}
+
+// TenantPermsPattern 租户下权限相关key的匹配模式
+func TenantPermsPattern(tenantID uint) string {
+	return TenantPrefix(tenantID) + "perms:*"
+}
+
+// UserPattern 平台级用户维度key的匹配模式（普通用户不区分租户）
+func UserPattern() string {
+	return "user:*"
+}
+
+// TenantAdminAccessCodesKey 管理员在租户下的权限码缓存key
+func TenantAdminAccessCodesKey(tenantID uint, userID uint) string {
+	return TenantPrefix(tenantID) + "perms:admin:" + itoa(userID) + ":codes"
 }
 
 // 租户菜单白名单缓存key
@@ -17,9 +47,26 @@ func TenantMenuWhitelistKey(tenantID uint) string {
 	return TenantPrefix(tenantID) + "menus:whitelist"
 }
 
+// TenantMenuGenerationKey 租户菜单世代号key
+// 菜单树缓存key携带世代号，递增世代号即可原子地使该租户全部菜单树失效
+// 世代号只增不删：清零后旧世代的菜单树可能重新被当作最新，批量清理缓存时须跳过此key
+func TenantMenuGenerationKey(tenantID uint) string {
+	return TenantPrefix(tenantID) + "menus:gen"
+}
+
+// TenantMenuTreePattern 租户下全部菜单树缓存key的匹配模式
+func TenantMenuTreePattern(tenantID uint) string {
+	return TenantPrefix(tenantID) + "menus:tree:*"
+}
+
 // 用户在租户下的菜单树缓存key
-func TenantUserMenuTreeKey(tenantID uint, userID uint) string {
-	return TenantPrefix(tenantID) + "admin:" + itoa(userID) + ":menu_tree"
+func TenantUserMenuTreeKey(tenantID uint, userID uint, generation uint) string {
+	return TenantPrefix(tenantID) + "menus:tree:g" + itoa(generation) + ":admin:" + itoa(userID)
+}
+
+// UserLangKey 普通用户语言偏好缓存key（用户不属于租户，不带租户前缀）
+func UserLangKey(userID uint) string {
+	return "user:" + itoa(userID) + ":lang"
 }
 
 // itoa 简易无依赖整型转字符串
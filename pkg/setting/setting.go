@@ -1,7 +1,9 @@
 package setting
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -85,8 +87,18 @@ type App struct {
 	ImageAllowExts []string
 
 	ExportSavePath string
+	// ImportSavePath 导入上传文件的暂存目录（不对外提供下载）
+	ImportSavePath string
+	// ImportMaxSize 导入文件大小上限（MB）
+	ImportMaxSize  int
 	QrCodeSavePath string
+	// QrCodeCacheTTL 二维码缓存文件的保留时间（小时），超过后由定时任务删除，默认 168
+	QrCodeCacheTTL time.Duration
 	FontSavePath   string
+	// FileSignSecret 文件签名链接的 HMAC 密钥，为空时由 JwtSecret 经 HKDF 派生；不能与 JwtSecret 相同
+	FileSignSecret string
+	// FileUrlExpire 文件签名链接的默认有效期（分钟）
+	FileUrlExpire int
 
 	LogSavePath string
 	LogSaveName string
@@ -101,6 +113,10 @@ type Server struct {
 	HttpPort     int
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// ShutdownTimeout 优雅停机等待进行中请求的最长时间
+	ShutdownTimeout time.Duration
+	// DrainDelay 停机时就绪探针置为失败后、关闭监听前的等待时间，留给负载均衡摘流
+	DrainDelay time.Duration
 }
 
 var ServerSetting = &Server{}
@@ -131,6 +147,101 @@ type Redis struct {
 
 var RedisSetting = &Redis{}
 
+// Metrics Prometheus 指标配置
+type Metrics struct {
+	Enabled  bool
+	Path     string
+	Token    string   // 非空时要求 Authorization: Bearer <Token>
+	AllowIPs []string // 允许访问的IP或CIDR，Token 为空时生效
+}
+
+var MetricsSetting = &Metrics{}
+
+// Tracing OpenTelemetry 链路追踪配置
+type Tracing struct {
+	Enabled     bool
+	Endpoint    string            // OTLP/HTTP 地址，如 http://otel-collector:4318，未带路径时使用 /v1/traces
+	ServiceName string            // 默认 justus
+	SampleRatio float64           // 根 span 采样比例（0~1），未配置时全部采样；上游已决定采样时沿用上游
+	Headers     map[string]string // 导出请求附加头，如鉴权 Token
+}
+
+var TracingSetting = &Tracing{}
+
+// Mail 邮件发送配置；Host 为空时不发送，仅写日志
+type Mail struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// InviteURL 管理员邀请落地页，邀请令牌以 ?token= 追加；为空时使用 PrefixUrl + /admin/invite
+	InviteURL string
+	// InviteTTL 邀请有效期（小时），默认 72
+	InviteTTL time.Duration
+}
+
+var MailSetting = &Mail{}
+
+// Trash 回收站配置：软删除记录保留 Retention 后由定时任务永久删除
+type Trash struct {
+	// Retention 保留天数，默认 30
+	Retention time.Duration
+	// PurgeSpec 清理任务的 cron 表达式（含秒），默认每天 03:30
+	PurgeSpec string
+}
+
+var TrashSetting = &Trash{}
+
+// Upload 文件上传配置；单文件大小与扩展名白名单沿用 app.ImageMaxSize、app.ImageAllowExts
+type Upload struct {
+	// Driver 存储驱动：local（默认，写入 RuntimeRootPath + ImageSavePath）或 s3
+	Driver string
+	// TenantQuota 每个租户的存储配额（MB），0 表示不限
+	TenantQuota int64
+	S3          S3
+	// Image 图片处理：上传时修正方向、去除 EXIF、限制尺寸，并生成缩略图等变体
+	Image ImagePipeline
+}
+
+// ImagePipeline 图片处理配置，仅作用于 avatar、image 分类的上传
+type ImagePipeline struct {
+	// MaxDimension 原图长边上限（像素），超出时等比缩小，0 表示不限
+	MaxDimension int
+	// Quality JPEG 编码质量（1-100）
+	Quality int
+	// Lazy 为 true 时变体在首次访问 /variants 路由时生成，否则在上传时生成
+	Lazy     bool
+	Variants []ImageVariant
+}
+
+// ImageVariant 图片变体，如缩略图
+type ImageVariant struct {
+	// Name 变体名，出现在存储键与访问地址中，如 thumb
+	Name   string
+	Width  int
+	Height int
+	// Fit cover 居中裁剪填满，contain（默认）等比缩放到框内
+	Fit string
+	// Format jpeg、png 或 webp（无损编码，保留透明），为空时 PNG、GIF 原图输出 png，其余输出 jpeg
+	Format string
+}
+
+// S3 S3 兼容对象存储（AWS S3、MinIO、OSS 等）
+type S3 struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle 使用 endpoint/bucket/key 形式访问，MinIO 等自建服务通常需要开启
+	PathStyle bool
+	// BaseURL 对外访问地址前缀，为空时使用 endpoint 与 bucket 拼接
+	BaseURL string
+}
+
+var UploadSetting = &Upload{}
+
 var v *viper.Viper
 
 // GetMiddlewareLogConfig 获取中间件日志配置
@@ -148,15 +259,30 @@ func GetMiddlewareLogConfig() *MiddlewareLogConfig {
 }
 
 // Setup initialize the configuration instance
+// 兼容旧入口：加载失败直接 panic，并开启配置热加载
 func Setup() {
-	// 优先加载根目录下的 .env 文件
-	_ = godotenv.Load(".env")
-	// 读取环境变量 APP_ENV，默认为 dev
+	if err := Load(""); err != nil {
+		panic(err)
+	}
+	Watch(nil)
+}
+
+// ConfigFile 按环境变量 APP_ENV 选择配置文件，默认为 dev
+func ConfigFile() string {
 	env := os.Getenv("APP_ENV")
 	if env == "" {
 		env = "dev"
 	}
-	configFile := fmt.Sprintf("conf/app.%s.yaml", env)
+	return fmt.Sprintf("conf/app.%s.yaml", env)
+}
+
+// Load 读取配置文件与环境变量并填充各配置项，configFile 为空时使用 ConfigFile()
+func Load(configFile string) error {
+	// 优先加载根目录下的 .env 文件
+	_ = godotenv.Load(".env")
+	if configFile == "" {
+		configFile = ConfigFile()
+	}
 
 	v = viper.New()
 	v.SetConfigFile(configFile)
@@ -166,6 +292,7 @@ func Setup() {
 
 	// 设置环境变量映射
 	v.BindEnv("app.JwtSecret", "JWT_SECRET")
+	v.BindEnv("app.FileSignSecret", "FILE_SIGN_SECRET")
 	v.BindEnv("app.PrefixUrl", "APP_PREFIX_URL")
 	v.BindEnv("app.ImageUrl", "APP_IMAGE_URL")
 	v.BindEnv("app.AesKey", "AES_KEY")
@@ -194,42 +321,323 @@ func Setup() {
 	v.BindEnv("zincsearch.Timeout", "ZINC_TIMEOUT")
 	v.BindEnv("zincsearch.DefaultIndex", "ZINC_DEFAULT_INDEX")
 
+	// Metrics 环境变量绑定
+	v.BindEnv("metrics.Enabled", "METRICS_ENABLED")
+	v.BindEnv("metrics.Token", "METRICS_TOKEN")
+
+	// Tracing 环境变量绑定
+	v.BindEnv("tracing.Enabled", "TRACING_ENABLED")
+	v.BindEnv("tracing.Endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	// Mail 环境变量绑定
+	v.BindEnv("mail.Host", "MAIL_HOST")
+	v.BindEnv("mail.Username", "MAIL_USERNAME")
+	v.BindEnv("mail.Password", "MAIL_PASSWORD")
+
+	// 对象存储环境变量绑定
+	v.BindEnv("upload.Driver", "UPLOAD_DRIVER")
+	v.BindEnv("upload.S3.Endpoint", "S3_ENDPOINT")
+	v.BindEnv("upload.S3.Bucket", "S3_BUCKET")
+	v.BindEnv("upload.S3.AccessKeyID", "S3_ACCESS_KEY_ID")
+	v.BindEnv("upload.S3.SecretAccessKey", "S3_SECRET_ACCESS_KEY")
+
 	if err := v.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("Fatal error config file: %w", err))
+		return fmt.Errorf("read config file %s: %w", configFile, err)
+	}
+	c, err := decode()
+	if err != nil {
+		return err
+	}
+	c.install()
+	return nil
+}
+
+// config 一次完整解析出的全部配置，校验通过后才写入各全局配置项
+type config struct {
+	app      *App
+	server   *Server
+	database *Database
+	redis    *Redis
+	logger   *LoggerSettingS
+	zinc     *ZincSearchConfig
+	metrics  *Metrics
+	tracing  *Tracing
+	mail     *Mail
+	trash    *Trash
+	upload   *Upload
+}
+
+// current 以当前全局配置项构造 config，供 Validate 使用
+func current() *config {
+	return &config{
+		app:      AppSetting,
+		server:   ServerSetting,
+		database: DatabaseSetting,
+		redis:    RedisSetting,
+		logger:   LoggerSetting,
+		zinc:     ZincSearchSetting,
+		metrics:  MetricsSetting,
+		tracing:  TracingSetting,
+		mail:     MailSetting,
+		trash:    TrashSetting,
+		upload:   UploadSetting,
+	}
+}
+
+// install 将 config 写入各全局配置项；保持指针不变，已持有配置指针的包同样看到新值
+func (c *config) install() {
+	*AppSetting = *c.app
+	*ServerSetting = *c.server
+	*DatabaseSetting = *c.database
+	*RedisSetting = *c.redis
+	*LoggerSetting = *c.logger
+	*ZincSearchSetting = *c.zinc
+	*MetricsSetting = *c.metrics
+	*TracingSetting = *c.tracing
+	*MailSetting = *c.mail
+	*TrashSetting = *c.trash
+	*UploadSetting = *c.upload
+}
+
+// decode 将配置解析到全新的结构体并做单位换算，不修改全局配置项
+// 每次都从零值开始，文件中缺失的键取默认值，不会在已换算的旧值上重复换算
+func decode() (*config, error) {
+	c := &config{
+		app:      &App{},
+		server:   &Server{},
+		database: &Database{},
+		redis:    &Redis{},
+		logger:   &LoggerSettingS{},
+		zinc:     &ZincSearchConfig{},
+		metrics:  &Metrics{},
+		tracing:  &Tracing{},
+		mail:     &Mail{},
+		trash:    &Trash{},
+		upload:   &Upload{},
+	}
+	sections := []struct {
+		key    string
+		target interface{}
+	}{
+		{"app", c.app},
+		{"server", c.server},
+		{"database", c.database},
+		{"redis", c.redis},
+		{"log", c.logger},
+		{"zincsearch", c.zinc},
+		{"metrics", c.metrics},
+		{"tracing", c.tracing},
+		{"mail", c.mail},
+		{"trash", c.trash},
+		{"upload", c.upload},
+	}
+	for _, sec := range sections {
+		if err := v.UnmarshalKey(sec.key, sec.target); err != nil {
+			return nil, fmt.Errorf("unmarshal %s config: %w", sec.key, err)
+		}
 	}
 
-	if err := v.UnmarshalKey("app", AppSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal app config error: %w", err))
+	if c.metrics.Path == "" {
+		c.metrics.Path = "/metrics"
 	}
-	if err := v.UnmarshalKey("server", ServerSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal server config error: %w", err))
+	if c.tracing.ServiceName == "" {
+		c.tracing.ServiceName = "justus"
 	}
-	if err := v.UnmarshalKey("database", DatabaseSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal database config error: %w", err))
+	if c.tracing.SampleRatio == 0 {
+		c.tracing.SampleRatio = 1
 	}
-	if err := v.UnmarshalKey("redis", RedisSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal redis config error: %w", err))
+	if c.app.ImageMaxSize <= 0 {
+		c.app.ImageMaxSize = 5
 	}
-	if err := v.UnmarshalKey("log", LoggerSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal log config error: %w", err))
+	c.app.ImageMaxSize = c.app.ImageMaxSize * 1024 * 1024
+	if len(c.app.ImageAllowExts) == 0 {
+		c.app.ImageAllowExts = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
 	}
-	if err := v.UnmarshalKey("zincsearch", ZincSearchSetting); err != nil {
-		panic(fmt.Errorf("Unmarshal zincsearch config error: %w", err))
+	if c.app.ImageSavePath == "" {
+		c.app.ImageSavePath = "uploads/"
 	}
+	if c.upload.Driver == "" {
+		c.upload.Driver = "local"
+	}
+	c.upload.TenantQuota = c.upload.TenantQuota * 1024 * 1024
+	if c.upload.Image.Quality <= 0 || c.upload.Image.Quality > 100 {
+		c.upload.Image.Quality = 85
+	}
+	if c.app.QrCodeSavePath == "" {
+		c.app.QrCodeSavePath = "qrcode/"
+	}
+	if c.app.QrCodeCacheTTL <= 0 {
+		c.app.QrCodeCacheTTL = 168
+	}
+	c.app.QrCodeCacheTTL = c.app.QrCodeCacheTTL * time.Hour
+	if c.app.FileUrlExpire <= 0 {
+		c.app.FileUrlExpire = 60
+	}
+	if c.app.ExportSavePath == "" {
+		c.app.ExportSavePath = "export/"
+	}
+	if c.app.ImportSavePath == "" {
+		c.app.ImportSavePath = "import/"
+	}
+	if c.app.ImportMaxSize <= 0 {
+		c.app.ImportMaxSize = 10
+	}
+	c.app.ImportMaxSize = c.app.ImportMaxSize * 1024 * 1024
+	c.server.ReadTimeout = c.server.ReadTimeout * time.Second
+	c.server.WriteTimeout = c.server.WriteTimeout * time.Second
+	if c.server.ShutdownTimeout <= 0 {
+		c.server.ShutdownTimeout = 30
+	}
+	c.server.ShutdownTimeout = c.server.ShutdownTimeout * time.Second
+	c.server.DrainDelay = c.server.DrainDelay * time.Second
+	c.redis.IdleTimeout = c.redis.IdleTimeout * time.Second
+	if c.mail.InviteTTL <= 0 {
+		c.mail.InviteTTL = 72
+	}
+	c.mail.InviteTTL = c.mail.InviteTTL * time.Hour
+	if c.mail.Port == 0 {
+		c.mail.Port = 587
+	}
+	if c.trash.Retention <= 0 {
+		c.trash.Retention = 30
+	}
+	c.trash.Retention = c.trash.Retention * 24 * time.Hour
+	if c.trash.PurgeSpec == "" {
+		c.trash.PurgeSpec = "0 30 3 * * *"
+	}
+	return c, nil
+}
 
-	AppSetting.ImageMaxSize = AppSetting.ImageMaxSize * 1024 * 1024
-	ServerSetting.ReadTimeout = ServerSetting.ReadTimeout * time.Second
-	ServerSetting.WriteTimeout = ServerSetting.WriteTimeout * time.Second
-	RedisSetting.IdleTimeout = RedisSetting.IdleTimeout * time.Second
-
-	// 配置热加载
+// Watch 开启配置热加载
+// 新配置整体解析并通过校验后才替换全局配置项，随后调用 onReload，由调用方把密钥等已安装到其他包的值重新应用；onReload 可为 nil
+func Watch(onReload func()) {
+	if v == nil {
+		return
+	}
 	v.WatchConfig()
 	v.OnConfigChange(func(e fsnotify.Event) {
 		fmt.Println("Config file changed:", e.Name)
-		_ = v.UnmarshalKey("app", AppSetting)
-		_ = v.UnmarshalKey("server", ServerSetting)
-		_ = v.UnmarshalKey("database", DatabaseSetting)
-		_ = v.UnmarshalKey("redis", RedisSetting)
-		_ = v.UnmarshalKey("log", LoggerSetting)
+		if err := reload(); err != nil {
+			fmt.Println("Reload config failed, keeping previous config:", err)
+			return
+		}
+		if onReload != nil {
+			onReload()
+		}
 	})
 }
+
+// reload 重新解析并校验配置，失败时全局配置项保持不变
+func reload() error {
+	c, err := decode()
+	if err != nil {
+		return err
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.install()
+	return nil
+}
+
+// minReleaseSecretLen 生产模式下 JWT 密钥的最小长度
+const minReleaseSecretLen = 32
+
+// Validate 校验当前全局配置的必填项与取值范围，返回全部问题而不是第一个
+func Validate() error {
+	return current().validate()
+}
+
+// validate 校验一组配置，热加载时在写入全局配置项之前调用
+func (c *config) validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.app.JwtSecret == "" {
+		add("app.JwtSecret (JWT_SECRET) is required")
+	} else if c.server.RunMode == "release" && len(c.app.JwtSecret) < minReleaseSecretLen {
+		add("app.JwtSecret must be at least %d characters in release mode", minReleaseSecretLen)
+	}
+	if c.app.FileSignSecret != "" && c.app.FileSignSecret == c.app.JwtSecret {
+		add("app.FileSignSecret (FILE_SIGN_SECRET) must differ from app.JwtSecret")
+	}
+	// 落盘的二维码通过签名链接访问，缓存须比链接活得久
+	if c.app.QrCodeCacheTTL < time.Duration(c.app.FileUrlExpire)*time.Minute {
+		add("app.QrCodeCacheTTL must not be shorter than app.FileUrlExpire")
+	}
+	if n := len(c.app.AesKey); n != 0 && n != 16 && n != 24 && n != 32 {
+		add("app.AesKey must be 16, 24 or 32 bytes, got %d", n)
+	}
+
+	switch c.server.RunMode {
+	case "debug", "release", "test":
+	default:
+		add("server.RunMode must be debug, release or test, got %q", c.server.RunMode)
+	}
+	if c.server.HttpPort <= 0 || c.server.HttpPort > 65535 {
+		add("server.HttpPort must be between 1 and 65535, got %d", c.server.HttpPort)
+	}
+	if c.server.ReadTimeout < 0 || c.server.WriteTimeout < 0 || c.server.DrainDelay < 0 {
+		add("server timeouts must not be negative")
+	}
+
+	if c.database.Host == "" || c.database.Name == "" || c.database.User == "" {
+		add("database.Host, database.Name and database.User are required")
+	}
+	if c.redis.Host == "" {
+		add("redis.Host is required")
+	}
+
+	switch c.logger.LogType {
+	case "", LogFileLogging:
+	case LogFileType:
+		if c.logger.LogFileSavePath == "" || c.logger.LogFileName == "" {
+			add("log.LogFileSavePath and log.LogFileName are required for file logging")
+		}
+	case LogFileZinc:
+		if c.zinc.Host == "" || c.zinc.DefaultIndex == "" {
+			add("zincsearch.Host and zincsearch.DefaultIndex are required for zinc logging")
+		}
+	case LogFileSLS:
+		sls := c.logger.SLS
+		if sls.Endpoint == "" || sls.Project == "" || sls.Logstore == "" || sls.AccessKeyID == "" || sls.AccessKeySecret == "" {
+			add("log.SLS Endpoint, Project, Logstore and access keys are required for sls logging")
+		}
+	default:
+		add("log.LogType must be one of file, logging, sls, zinc, got %q", c.logger.LogType)
+	}
+
+	if c.metrics.Enabled {
+		if !strings.HasPrefix(c.metrics.Path, "/") {
+			add("metrics.Path must start with '/', got %q", c.metrics.Path)
+		}
+		if c.metrics.Token == "" && len(c.metrics.AllowIPs) == 0 {
+			add("metrics endpoint requires metrics.Token or metrics.AllowIPs")
+		}
+		// 生产环境前面通常有反向代理，仅凭 IP 白名单不足以保护指标接口
+		if c.server.RunMode == "release" && c.metrics.Token == "" {
+			add("metrics.Token (METRICS_TOKEN) is required in release mode")
+		}
+	}
+
+	if c.tracing.Enabled {
+		if u, err := url.Parse(c.tracing.Endpoint); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			add("tracing.Endpoint must be an http(s) URL, got %q", c.tracing.Endpoint)
+		}
+		if c.tracing.SampleRatio < 0 || c.tracing.SampleRatio > 1 {
+			add("tracing.SampleRatio must be between 0 and 1, got %v", c.tracing.SampleRatio)
+		}
+	}
+
+	for _, variant := range c.upload.Image.Variants {
+		switch strings.ToLower(variant.Format) {
+		case "", "jpeg", "jpg", "png", "webp":
+		default:
+			add("upload.Image.Variants[%s].Format must be jpeg, png or webp, got %q", variant.Name, variant.Format)
+		}
+	}
+
+	return errors.Join(errs...)
+}
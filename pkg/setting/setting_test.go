@@ -0,0 +1,88 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const reloadBase = `
+app:
+  JwtSecret: test-secret
+  ImageMaxSize: 2
+server:
+  RunMode: debug
+  HttpPort: 8000
+  ReadTimeout: 60
+database:
+  Host: 127.0.0.1
+  Name: justus
+  User: root
+redis:
+  Host: 127.0.0.1:6379
+`
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestReloadAtomic 无效的热加载不改动任何配置项，有效的热加载不重复换算单位
+func TestReloadAtomic(t *testing.T) {
+	// 空值环境变量视为未设置，避免外部环境覆盖测试配置
+	for _, key := range []string{"JWT_SECRET", "APP_PORT", "DB_HOST"} {
+		t.Setenv(key, "")
+	}
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeConfig(t, path, reloadBase)
+	if err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// HttpPort 越界使新配置无效，其余改动也不得生效
+	writeConfig(t, path, `
+app:
+  JwtSecret: other-secret
+server:
+  RunMode: debug
+  HttpPort: 70000
+database:
+  Host: 10.0.0.1
+  Name: justus
+  User: root
+redis:
+  Host: 127.0.0.1:6379
+`)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reload(); err == nil {
+		t.Fatal("invalid config reloaded")
+	}
+	if AppSetting.JwtSecret != "test-secret" || DatabaseSetting.Host != "127.0.0.1" || ServerSetting.HttpPort != 8000 {
+		t.Fatalf("invalid reload leaked: secret=%q host=%q port=%d", AppSetting.JwtSecret, DatabaseSetting.Host, ServerSetting.HttpPort)
+	}
+
+	writeConfig(t, path, reloadBase)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reload(); err != nil {
+		t.Fatal(err)
+	}
+	if ServerSetting.ReadTimeout != 60*time.Second {
+		t.Errorf("ReadTimeout = %v, want 60s", ServerSetting.ReadTimeout)
+	}
+	if AppSetting.ImageMaxSize != 2*1024*1024 {
+		t.Errorf("ImageMaxSize = %d, want 2MB", AppSetting.ImageMaxSize)
+	}
+	if ServerSetting.ShutdownTimeout != 30*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want default 30s", ServerSetting.ShutdownTimeout)
+	}
+}
@@ -0,0 +1,168 @@
+// Package signurl 生成与校验带 HMAC 签名、有过期时间的文件访问链接
+//
+// 链接形如 /files/exports/a1b2.xlsx?expires=1700000000&tenant=1&filename=users.xlsx&signature=…，
+// 签名覆盖路径、过期时间、租户与下载文件名，任一部分被改动都会校验失败
+package signurl
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"justus/pkg/setting"
+)
+
+// 签名文件的访问路由与命名空间，路径形如 /files/exports/{name}
+const (
+	Route   = "/files/"
+	Exports = "exports"
+	Uploads = "uploads"
+	QrCodes = "qrcodes"
+)
+
+// defaultTTL 未配置 app.FileUrlExpire 时的有效期
+const defaultTTL = time.Hour
+
+// 查询参数名
+const (
+	paramExpires   = "expires"
+	paramTenant    = "tenant"
+	paramFilename  = "filename"
+	paramSignature = "signature"
+)
+
+var (
+	// ErrInvalid 签名缺失或不匹配
+	ErrInvalid = errors.New("signurl: invalid signature")
+	// ErrExpired 链接已过期
+	ErrExpired = errors.New("signurl: link expired")
+)
+
+var (
+	mu     sync.RWMutex
+	secret []byte
+)
+
+// deriveLabel HKDF 派生密钥的用途标签，修改会使已签发的链接全部失效
+const deriveLabel = "justus/signurl/v1"
+
+// DeriveSecret 未单独配置签名密钥时由主密钥（JwtSecret）经 HKDF-SHA256 派生，
+// 不直接复用主密钥，链接签名与 JWT 签名互不相关
+func DeriveSecret(master []byte) []byte {
+	key, err := hkdf.Key(sha256.New, master, nil, deriveLabel, sha256.Size)
+	if err != nil {
+		// 只在输出长度超出上限时出错，固定 32 字节不会发生
+		panic(err)
+	}
+	return key
+}
+
+// SetSecret 设置签名密钥，由 bootstrap 在启动时调用
+func SetSecret(key []byte) {
+	mu.Lock()
+	defer mu.Unlock()
+	secret = append([]byte(nil), key...)
+}
+
+// Options 签名选项
+type Options struct {
+	// TTL 有效期，为 0 时使用 app.FileUrlExpire
+	TTL time.Duration
+	// TenantID 链接所属租户，0 表示不限定
+	TenantID uint
+	// Filename 下载文件名，设置后以附件形式下载
+	Filename string
+}
+
+// Claims 校验通过的链接信息
+type Claims struct {
+	Path     string
+	Expires  time.Time
+	TenantID uint
+	Filename string
+}
+
+// Path 命名空间内文件的访问路径
+func Path(namespace, name string) string {
+	return Route + namespace + "/" + name
+}
+
+// Sign 为 path 生成带签名的相对地址
+func Sign(path string, opts Options) string {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = time.Duration(setting.AppSetting.FileUrlExpire) * time.Minute
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	claims := Claims{Path: path, Expires: time.Now().Add(ttl), TenantID: opts.TenantID, Filename: opts.Filename}
+
+	q := url.Values{}
+	q.Set(paramExpires, strconv.FormatInt(claims.Expires.Unix(), 10))
+	if claims.TenantID != 0 {
+		q.Set(paramTenant, strconv.FormatUint(uint64(claims.TenantID), 10))
+	}
+	if claims.Filename != "" {
+		q.Set(paramFilename, claims.Filename)
+	}
+	q.Set(paramSignature, signature(&claims))
+	return path + "?" + q.Encode()
+}
+
+// URL 为 path 生成带签名的完整地址
+func URL(path string, opts Options) string {
+	return setting.AppSetting.PrefixUrl + Sign(path, opts)
+}
+
+// Verify 校验路径与查询参数，now 之后过期的链接才有效
+func Verify(path string, query url.Values, now time.Time) (*Claims, error) {
+	expires, err := strconv.ParseInt(query.Get(paramExpires), 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	claims := &Claims{Path: path, Expires: time.Unix(expires, 0), Filename: query.Get(paramFilename)}
+	if raw := query.Get(paramTenant); raw != "" {
+		tenantID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || tenantID == 0 {
+			return nil, ErrInvalid
+		}
+		claims.TenantID = uint(tenantID)
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(query.Get(paramSignature))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	want, _ := base64.RawURLEncoding.DecodeString(signature(claims))
+	if !hmac.Equal(got, want) {
+		return nil, ErrInvalid
+	}
+	// 先验签再判断过期，避免伪造的链接得到“已过期”的提示
+	if !now.Before(claims.Expires) {
+		return nil, ErrExpired
+	}
+	return claims, nil
+}
+
+// signature 对规范化的链接信息计算 HMAC-SHA256；前缀区分用途，避免与同密钥的其他签名混用
+func signature(c *Claims) string {
+	mu.RLock()
+	mac := hmac.New(sha256.New, secret)
+	mu.RUnlock()
+	mac.Write([]byte(strings.Join([]string{
+		"signurl/v1",
+		c.Path,
+		strconv.FormatInt(c.Expires.Unix(), 10),
+		strconv.FormatUint(uint64(c.TenantID), 10),
+		c.Filename,
+	}, "\n")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package signurl
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	SetSecret([]byte("test-secret"))
+	signed := Sign("/files/exports/a.csv", Options{TTL: time.Minute, TenantID: 7, Filename: "用户.csv"})
+	path, raw, _ := strings.Cut(signed, "?")
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := Verify(path, query, time.Now())
+	if err != nil || claims.TenantID != 7 || claims.Filename != "用户.csv" {
+		t.Fatalf("verify: %+v, %v", claims, err)
+	}
+	if _, err := Verify(path, query, time.Now().Add(2*time.Minute)); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expired: %v", err)
+	}
+
+	tampered := func(key, value string) url.Values {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set(key, value)
+		return q
+	}
+	for name, q := range map[string]url.Values{
+		"tenant":    tampered("tenant", "8"),
+		"filename":  tampered("filename", "x.exe"),
+		"expires":   tampered("expires", "9999999999"),
+		"signature": tampered("signature", "AAAA"),
+	} {
+		if _, err := Verify(path, q, time.Now()); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s tampered: %v", name, err)
+		}
+	}
+	if _, err := Verify("/files/exports/b.csv", query, time.Now()); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("path changed: %v", err)
+	}
+
+	SetSecret([]byte("other-secret"))
+	defer SetSecret([]byte("test-secret"))
+	if _, err := Verify(path, query, time.Now()); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("secret rotated: %v", err)
+	}
+}
+
+// TestDeriveSecret 派生密钥与主密钥不同且稳定，用主密钥签名的链接不能通过派生密钥校验
+func TestDeriveSecret(t *testing.T) {
+	master := []byte("jwt-secret")
+	derived := DeriveSecret(master)
+	if len(derived) != 32 || string(derived) == string(master) || string(DeriveSecret(master)) != string(derived) {
+		t.Fatalf("derived = %x", derived)
+	}
+
+	SetSecret(master)
+	defer SetSecret([]byte("test-secret"))
+	path, raw, _ := strings.Cut(Sign("/files/exports/a.csv", Options{TTL: time.Minute}), "?")
+	query, _ := url.ParseQuery(raw)
+	SetSecret(derived)
+	if _, err := Verify(path, query, time.Now()); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("master-signed link accepted: %v", err)
+	}
+}
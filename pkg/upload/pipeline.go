@@ -0,0 +1,304 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	_ "image/gif" // 解码 GIF 首帧用于生成变体
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	_ "golang.org/x/image/bmp" // 注册 BMP 解码器
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+
+	"justus/pkg/e"
+	"justus/pkg/setting"
+)
+
+// maxPixels 解码前按图片头检查的像素上限，防止小文件解压出超大位图
+const maxPixels = 50_000_000
+
+// 图片编码格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// DecodeImage 解码图片并按 EXIF 方向摆正；像素数超过上限时返回 ERROR_UPLOAD_TOO_LARGE
+func DecodeImage(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED, err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, e.New(e.ERROR_UPLOAD_TOO_LARGE)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED, err)
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, nil
+}
+
+// NormalizeImage 就地处理已接收的图片：摆正方向、去除 EXIF 等元数据、限制长边，
+// 并把 WebP 转为 JPEG 或 PNG、BMP 转为 PNG；GIF（可能是动图）与非图片保持原样，返回的图片为 nil
+// Temp 的大小、类型、扩展名与 SHA-256 随之更新，MD5 仍为原始内容的摘要
+func NormalizeImage(t *Temp, cfg setting.ImagePipeline) (image.Image, error) {
+	switch t.ContentType {
+	case "image/jpeg", "image/png", "image/webp", "image/bmp":
+	default:
+		return nil, nil
+	}
+	f, err := t.Open()
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	img, err := DecodeImage(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resized := false
+	if limit := cfg.MaxDimension; limit > 0 {
+		b := img.Bounds()
+		if b.Dx() > limit || b.Dy() > limit {
+			img = fit(img, limit, limit, "contain")
+			resized = true
+		}
+	}
+	// PNG 无需去除 EXIF，尺寸未变时保留原文件，避免无损图片被重新编码
+	if t.ContentType == "image/png" && !resized {
+		return img, nil
+	}
+
+	// PNG、BMP 为无损格式，仍输出 PNG；WebP 不透明时转 JPEG
+	format := FormatPNG
+	if t.ContentType == "image/jpeg" || (t.ContentType == "image/webp" && opaque(img)) {
+		format = FormatJPEG
+	}
+	body, err := encodeImage(img, format, cfg.Quality)
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	out, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	_, err = out.Write(body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(out.Name())
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+
+	t.Remove()
+	sum := sha256.Sum256(body)
+	t.Path, t.Size, t.SHA256 = out.Name(), int64(len(body)), hex.EncodeToString(sum[:])
+	t.ContentType, t.Ext = "image/"+format, formatExt(format)
+	return img, nil
+}
+
+// VariantKey 变体的存储键：images/ab/ab12….png 的 thumb 变体为 images/ab/ab12…_thumb.jpg
+func VariantKey(key string, v setting.ImageVariant) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_" + v.Name + formatExt(variantFormat(ext, v))
+}
+
+// RenderVariant 按变体配置缩放并编码，返回可直接写入存储的对象与内容
+func RenderVariant(img image.Image, key string, v setting.ImageVariant, quality int) (Object, []byte, error) {
+	format := variantFormat(path.Ext(key), v)
+	body, err := encodeImage(fit(img, v.Width, v.Height, v.Fit), format, quality)
+	if err != nil {
+		return Object{}, nil, err
+	}
+	sum := sha256.Sum256(body)
+	return Object{
+		Key:         VariantKey(key, v),
+		Size:        int64(len(body)),
+		ContentType: "image/" + format,
+		SHA256:      hex.EncodeToString(sum[:]),
+	}, body, nil
+}
+
+// FindVariant 按名称查找已配置的变体
+func FindVariant(name string) (setting.ImageVariant, bool) {
+	for _, v := range setting.UploadSetting.Image.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return setting.ImageVariant{}, false
+}
+
+// ImageURL 图片访问地址：外链原样返回；variant 为空或未配置时返回原图在存储后端的地址，
+// 否则返回 /variants 路由：变体已存在时重定向到存储地址，不存在时（Lazy 模式或处理管线上线前的图片）先从原图生成
+func ImageURL(key, variant string) string {
+	if key == "" {
+		return ""
+	}
+	if strings.Contains(key, "http") {
+		return key
+	}
+	if v, ok := FindVariant(variant); ok {
+		return setting.AppSetting.PrefixUrl + "/variants/" + v.Name + "/" + key
+	}
+	if defaultStorage != nil {
+		return defaultStorage.URL(key)
+	}
+	return setting.AppSetting.ImageUrl + "/" + key
+}
+
+// ImageURLs 全部已配置变体的地址，外链或未配置变体时返回 nil
+func ImageURLs(key string) map[string]string {
+	variants := setting.UploadSetting.Image.Variants
+	if key == "" || strings.Contains(key, "http") || len(variants) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(variants))
+	for _, v := range variants {
+		urls[v.Name] = ImageURL(key, v.Name)
+	}
+	return urls
+}
+
+// variantFormat 变体的编码格式：未指定时 PNG、GIF 原图输出 png（可能含透明），其余输出 jpeg
+func variantFormat(srcExt string, v setting.ImageVariant) string {
+	switch strings.ToLower(v.Format) {
+	case FormatPNG:
+		return FormatPNG
+	case FormatWebP:
+		return FormatWebP
+	case FormatJPEG, "jpg":
+		return FormatJPEG
+	}
+	switch strings.ToLower(srcExt) {
+	case ".png", ".gif":
+		return FormatPNG
+	}
+	return FormatJPEG
+}
+
+func formatExt(format string) string {
+	switch format {
+	case FormatPNG:
+		return ".png"
+	case FormatWebP:
+		return ".webp"
+	}
+	return ".jpg"
+}
+
+// fit 缩放到 w×h 的框内：cover 居中裁剪填满，contain 等比缩放；不放大小图，w 或 h 为 0 时只约束另一边
+func fit(img image.Image, w, h int, mode string) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 || (w <= 0 && h <= 0) {
+		return img
+	}
+	if w <= 0 {
+		w = sw * h / sh
+	}
+	if h <= 0 {
+		h = sh * w / sw
+	}
+
+	src := b
+	dw, dh := w, h
+	if mode == "cover" {
+		// 按目标宽高比裁出源图中间区域
+		if sw*h > sh*w {
+			cw := sh * w / h
+			src = image.Rect(b.Min.X+(sw-cw)/2, b.Min.Y, b.Min.X+(sw-cw)/2+cw, b.Max.Y)
+		} else {
+			ch := sw * h / w
+			src = image.Rect(b.Min.X, b.Min.Y+(sh-ch)/2, b.Max.X, b.Min.Y+(sh-ch)/2+ch)
+		}
+		if src.Dx() < dw {
+			dw, dh = src.Dx(), src.Dy()
+		}
+	} else {
+		if sw*h > sh*w {
+			dh = sh * w / sw
+		} else {
+			dw = sw * h / sh
+		}
+		if dw > sw || dh > sh {
+			dw, dh = sw, sh
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	if dw == src.Dx() && dh == src.Dy() {
+		draw.Copy(dst, image.Point{}, img, src, draw.Src, nil)
+		return dst
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// opaque 图片是否不含透明像素
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// encodeImage 编码为 JPEG、PNG 或 WebP；JPEG 不支持透明，透明区域按白色铺底
+// WebP 使用纯 Go 的无损（VP8L）编码，保留透明，quality 不生效
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatPNG:
+		err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+		return buf.Bytes(), err
+	case FormatWebP:
+		err := nativewebp.Encode(&buf, img, nil)
+		return buf.Bytes(), err
+	}
+	if !opaque(img) {
+		b := img.Bounds()
+		flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, b.Min, draw.Over)
+		img = flat
+	}
+	if quality <= 0 || quality > 100 {
+		quality = jpeg.DefaultQuality
+	}
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	return buf.Bytes(), err
+}
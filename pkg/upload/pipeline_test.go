@@ -0,0 +1,162 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"justus/pkg/setting"
+)
+
+// withOrientation 在 SOI 之后插入只含方向标签的 EXIF 段
+func withOrientation(jpg []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(&tiff, binary.BigEndian, uint16(1))
+	_ = binary.Write(&tiff, binary.BigEndian, []uint16{0x0112, 3})
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(1))
+	_ = binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	_ = binary.Write(&out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+	out.Write(jpg[2:])
+	return out.Bytes()
+}
+
+// testJPEG 左半红、右半蓝的 w×h 图片
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeImageOrientation(t *testing.T) {
+	// 方向 6：显示时顺时针旋转 90°，原图左侧的红色转到上方
+	img, err := DecodeImage(bytes.NewReader(withOrientation(testJPEG(t, 40, 20), 6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Fatalf("bounds = %v", b)
+	}
+	if r, _, bl, _ := img.At(10, 5).RGBA(); r < 0xc000 || bl > 0x4000 {
+		t.Fatalf("top pixel not red: r=%x b=%x", r, bl)
+	}
+
+	for o, want := range map[uint16]image.Point{1: {40, 20}, 3: {40, 20}, 5: {20, 40}, 8: {20, 40}} {
+		img, err := DecodeImage(bytes.NewReader(withOrientation(testJPEG(t, 40, 20), o)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.Bounds().Size(); got != want {
+			t.Fatalf("orientation %d: size = %v, want %v", o, got, want)
+		}
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	src := withOrientation(testJPEG(t, 400, 200), 6)
+	tmp, err := Receive(bytes.NewReader(src), Policy{MaxSize: 1 << 20, AllowExts: []string{".jpg"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Remove()
+	md5 := tmp.MD5
+
+	img, err := NormalizeImage(tmp, setting.ImagePipeline{MaxDimension: 100, Quality: 80})
+	if err != nil || img == nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	f, _ := tmp.Open()
+	defer f.Close()
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil || format != "jpeg" || cfg.Width != 50 || cfg.Height != 100 {
+		t.Fatalf("normalized = %s %dx%d, %v", format, cfg.Width, cfg.Height, err)
+	}
+	_, _ = f.Seek(0, 0)
+	var out bytes.Buffer
+	_, _ = out.ReadFrom(f)
+	if bytes.Contains(out.Bytes(), []byte("Exif\x00\x00")) || int64(out.Len()) != tmp.Size || tmp.MD5 != md5 {
+		t.Fatalf("exif kept or temp not updated: size=%d/%d md5 changed=%v", out.Len(), tmp.Size, tmp.MD5 != md5)
+	}
+}
+
+func TestRenderVariant(t *testing.T) {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 300, 100)))
+	img, err := DecodeImage(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		v    setting.ImageVariant
+		key  string
+		w, h int
+	}{
+		{setting.ImageVariant{Name: "thumb", Width: 50, Height: 50, Fit: "cover"}, "images/ab/abc_thumb.png", 50, 50},
+		{setting.ImageVariant{Name: "medium", Width: 60, Height: 60}, "images/ab/abc_medium.png", 60, 20},
+		{setting.ImageVariant{Name: "big", Width: 1000, Height: 1000, Format: "jpeg"}, "images/ab/abc_big.jpg", 300, 100},
+		{setting.ImageVariant{Name: "small", Width: 30, Height: 30, Format: "webp"}, "images/ab/abc_small.webp", 30, 10},
+	}
+	for _, c := range cases {
+		obj, body, err := RenderVariant(img, "images/ab/abc.png", c.v, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(body))
+		if err != nil || obj.Key != c.key || obj.Size != int64(len(body)) || cfg.Width != c.w || cfg.Height != c.h ||
+			obj.ContentType != "image/"+format {
+			t.Fatalf("%s: key=%s type=%s %dx%d, %v", c.v.Name, obj.Key, obj.ContentType, cfg.Width, cfg.Height, err)
+		}
+	}
+}
+
+func TestImageURL(t *testing.T) {
+	app, up := *setting.AppSetting, *setting.UploadSetting
+	t.Cleanup(func() {
+		*setting.AppSetting, *setting.UploadSetting = app, up
+		SetDefaultStorage(nil)
+	})
+	setting.AppSetting.PrefixUrl = "http://api"
+	setting.AppSetting.ImageUrl = "http://api/uploads"
+	setting.UploadSetting.Image.Variants = []setting.ImageVariant{{Name: "thumb", Width: 50}}
+	SetDefaultStorage(NewLocalStorage(t.TempDir(), "https://cdn.example.com/bucket"))
+
+	cases := []struct{ key, variant, want string }{
+		{"", "thumb", ""},
+		{"https://example.com/a.png", "thumb", "https://example.com/a.png"},
+		{"images/ab/abc.png", "", "https://cdn.example.com/bucket/images/ab/abc.png"},
+		{"images/ab/abc.png", "missing", "https://cdn.example.com/bucket/images/ab/abc.png"},
+		// 变体不论是否已生成都走 /variants 路由，旧图片按需生成
+		{"images/ab/abc.png", "thumb", "http://api/variants/thumb/images/ab/abc.png"},
+	}
+	for _, c := range cases {
+		if got := ImageURL(c.key, c.variant); got != c.want {
+			t.Errorf("ImageURL(%q, %q) = %q, want %q", c.key, c.variant, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package upload
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"justus/pkg/e"
+	"justus/pkg/setting"
+)
+
+// sniffLen 内容嗅探读取的字节数，与 http.DetectContentType 一致
+const sniffLen = 512
+
+// sniffExts 可识别的内容类型及其规范扩展名；SVG 等可执行脚本的类型不在其中
+var sniffExts = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+}
+
+// Policy 上传限制
+type Policy struct {
+	// MaxSize 单文件字节上限
+	MaxSize int64
+	// AllowExts 允许的扩展名（按嗅探出的类型判断，而非客户端文件名）
+	AllowExts []string
+}
+
+// DefaultPolicy 按 app.ImageMaxSize 与 app.ImageAllowExts 生成上传限制
+func DefaultPolicy() Policy {
+	return Policy{MaxSize: int64(setting.AppSetting.ImageMaxSize), AllowExts: setting.AppSetting.ImageAllowExts}
+}
+
+// allows 扩展名是否在白名单中，.jpg 与 .jpeg 视为同一类型
+func (p Policy) allows(ext string) bool {
+	for _, allow := range p.AllowExts {
+		allow = strings.ToLower(allow)
+		if allow == ext || (ext == ".jpg" && allow == ".jpeg") {
+			return true
+		}
+	}
+	return false
+}
+
+// Temp 已落到临时文件的上传内容，使用后须调用 Remove
+type Temp struct {
+	Path        string
+	Size        int64
+	ContentType string
+	// Ext 按内容嗅探得到的扩展名
+	Ext string
+	// MD5 原始上传内容的摘要，用于去重与命名；图片经 NormalizeImage 处理后保持不变
+	MD5    string
+	SHA256 string
+}
+
+// Open 打开临时文件
+func (t *Temp) Open() (*os.File, error) {
+	return os.Open(t.Path)
+}
+
+// Remove 删除临时文件
+func (t *Temp) Remove() {
+	_ = os.Remove(t.Path)
+}
+
+// Key 以内容 MD5 命名的存储键，相同内容得到相同的键，如 images/ab/ab12….png
+func (t *Temp) Key(prefix string) string {
+	return strings.Trim(prefix, "/") + "/" + t.MD5[:2] + "/" + t.MD5 + t.Ext
+}
+
+// Receive 嗅探内容类型并以流式方式写入临时文件，同时计算 MD5 与 SHA-256
+// 超过 MaxSize 时立即停止读取，不会把整个文件读入内存
+func Receive(r io.Reader, policy Policy) (*Temp, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, e.Wrap(e.ERROR_FILE_READ_FAIL, err)
+	}
+	if len(head) == 0 {
+		return nil, e.New(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED)
+	}
+	contentType := http.DetectContentType(head)
+	ext, ok := sniffExts[contentType]
+	if !ok || !policy.allows(ext) {
+		return nil, e.New(e.ERROR_UPLOAD_TYPE_NOT_ALLOWED)
+	}
+
+	f, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	t := &Temp{Path: f.Name(), ContentType: contentType, Ext: ext}
+	md5h, sha := md5.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, md5h, sha), io.LimitReader(br, policy.MaxSize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		t.Remove()
+		return nil, e.Wrap(e.ERROR_FILE_WRITE_FAIL, err)
+	}
+	if n > policy.MaxSize {
+		t.Remove()
+		return nil, e.New(e.ERROR_UPLOAD_TOO_LARGE)
+	}
+	t.Size = n
+	t.MD5 = hex.EncodeToString(md5h.Sum(nil))
+	t.SHA256 = hex.EncodeToString(sha.Sum(nil))
+	return t, nil
+}
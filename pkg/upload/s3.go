@@ -0,0 +1,199 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"justus/pkg/setting"
+)
+
+// unsignedPayload 不对请求体签名（内容哈希未知时使用）
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// emptyPayload 空请求体的 SHA-256
+const emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Storage S3 兼容对象存储，请求以 AWS Signature V4 签名
+type S3Storage struct {
+	cfg      setting.S3
+	endpoint *url.URL
+	signer   *v4.Signer
+	// Client 发送请求的 HTTP 客户端，测试可替换
+	Client *http.Client
+}
+
+// NewS3Storage 创建 S3 存储
+func NewS3Storage(cfg setting.S3) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("upload: s3 endpoint and bucket are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("upload: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer:   v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+		Client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Put 上传对象
+func (s *S3Storage) Put(ctx context.Context, obj Object, body io.Reader) error {
+	hash := obj.SHA256
+	if hash == "" {
+		hash = unsignedPayload
+	}
+	req, err := s.request(ctx, http.MethodPut, obj.Key, body, hash)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = obj.Size
+	if obj.ContentType != "" {
+		req.Header.Set("Content-Type", obj.ContentType)
+	}
+	resp, err := s.send(req, hash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.fail(resp, obj.Key)
+	}
+	return nil
+}
+
+// Open 读取对象
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil, emptyPayload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.send(req, emptyPayload)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	defer resp.Body.Close()
+	return nil, s.fail(resp, key)
+}
+
+// Exists 以 HEAD 判断对象是否存在
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := s.request(ctx, http.MethodHead, key, nil, emptyPayload)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.send(req, emptyPayload)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, s.fail(resp, key)
+}
+
+// Delete 删除对象，S3 对不存在的对象同样返回 204
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil, emptyPayload)
+	if err != nil {
+		return err
+	}
+	resp, err := s.send(req, emptyPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.fail(resp, key)
+	}
+	return nil
+}
+
+// URL 对象的访问地址
+func (s *S3Storage) URL(key string) string {
+	if s.cfg.BaseURL != "" {
+		return strings.TrimRight(s.cfg.BaseURL, "/") + "/" + key
+	}
+	return s.objectURL(key).String()
+}
+
+// objectURL 路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	p := "/" + key
+	if s.cfg.PathStyle {
+		p = "/" + s.cfg.Bucket + p
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + p
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+func (s *S3Storage) request(ctx context.Context, method, key string, body io.Reader, payloadHash string) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return nil, fmt.Errorf("upload: invalid key %q", key)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	return req, nil
+}
+
+// send 签名并发送请求
+func (s *S3Storage) send(req *http.Request, payloadHash string) (*http.Response, error) {
+	creds := aws.Credentials{AccessKeyID: s.cfg.AccessKeyID, SecretAccessKey: s.cfg.SecretAccessKey}
+	if err := s.signer.SignHTTP(req.Context(), creds, req, payloadHash, "s3", s.cfg.Region, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.Client.Do(req)
+}
+
+// fail 将非预期响应转换为错误，保留 S3 返回的错误信息片段
+func (s *S3Storage) fail(resp *http.Response, key string) error {
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("upload: s3 %s %s: status %d: %s", resp.Request.Method, key, resp.StatusCode, strings.TrimSpace(string(snippet)))
+}
+
+// escapePath 按 S3 规则编码对象路径：保留 / 与非保留字符，其余百分号编码
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
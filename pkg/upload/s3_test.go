@@ -0,0 +1,181 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"justus/pkg/setting"
+)
+
+// fakeS3 路径风格的 S3 替身：校验 V4 签名，对象保存在内存中
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+	creds  aws.Credentials
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.verify(r) {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify 以相同的密钥与时间重新签名，比较 Authorization 头
+func (f *fakeS3) verify(r *http.Request) bool {
+	got := r.Header.Get("Authorization")
+	signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || got == "" {
+		return false
+	}
+	// 与 S3 一致，只按客户端声明的 SignedHeaders 计算签名
+	_, signed, _ := strings.Cut(got, "SignedHeaders=")
+	signed, _, _ = strings.Cut(signed, ",")
+	req := r.Clone(context.Background())
+	req.URL.Scheme, req.URL.Host = "http", r.Host
+	req.Header = http.Header{}
+	for _, name := range strings.Split(signed, ";") {
+		if values, ok := r.Header[http.CanonicalHeaderKey(name)]; ok {
+			req.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	signer := v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true })
+	if err := signer.SignHTTP(req.Context(), f.creds, req, r.Header.Get("X-Amz-Content-Sha256"), "s3", "us-east-1", signedAt); err != nil {
+		f.t.Errorf("re-sign: %v", err)
+		return false
+	}
+	return req.Header.Get("Authorization") == got
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *S3Storage) {
+	t.Helper()
+	fake := &fakeS3{
+		t:       t,
+		bucket:  "media",
+		creds:   aws.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"},
+		objects: map[string][]byte{},
+		types:   map[string]string{},
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3Storage(setting.S3{
+		Endpoint:        srv.URL,
+		Bucket:          fake.bucket,
+		AccessKeyID:     fake.creds.AccessKeyID,
+		SecretAccessKey: fake.creds.SecretAccessKey,
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, s
+}
+
+func TestS3Storage(t *testing.T) {
+	fake, s := newFakeS3(t)
+	ctx := context.Background()
+	content := []byte("%PDF-1.4 report")
+
+	for _, key := range []string{"files/ab/abcdef.pdf", "files/name with space+plus.pdf"} {
+		tmp, err := Receive(bytes.NewReader(content), Policy{MaxSize: 1 << 10, AllowExts: []string{".pdf"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, _ := tmp.Open()
+		err = s.Put(ctx, Object{Key: key, Size: tmp.Size, ContentType: tmp.ContentType, SHA256: tmp.SHA256}, f)
+		_ = f.Close()
+		tmp.Remove()
+		if err != nil {
+			t.Fatalf("put %q: %v", key, err)
+		}
+		if fake.types[key] != "application/pdf" {
+			t.Fatalf("stored content type = %q", fake.types[key])
+		}
+
+		if ok, err := s.Exists(ctx, key); err != nil || !ok {
+			t.Fatalf("exists %q = %v, %v", key, ok, err)
+		}
+		rc, err := s.Open(ctx, key)
+		if err != nil {
+			t.Fatalf("open %q: %v", key, err)
+		}
+		got, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if !bytes.Equal(got, content) {
+			t.Fatalf("open %q = %q", key, got)
+		}
+
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("delete %q: %v", key, err)
+		}
+		if ok, err := s.Exists(ctx, key); err != nil || ok {
+			t.Fatalf("exists after delete = %v, %v", ok, err)
+		}
+		if _, err := s.Open(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+			t.Fatalf("open after delete: %v", err)
+		}
+	}
+
+	// 未签名的请求体同样被接受
+	if err := s.Put(ctx, Object{Key: "images/x.png", Size: 3}, strings.NewReader("png")); err != nil {
+		t.Fatalf("unsigned payload put: %v", err)
+	}
+
+	// 错误的密钥被拒绝，且错误信息带有 S3 返回内容
+	cfg := s.cfg
+	cfg.SecretAccessKey = "wrong"
+	s, _ = NewS3Storage(cfg)
+	if _, err := s.Exists(ctx, "images/x.png"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("bad credentials: %v", err)
+	}
+	if err := s.Put(ctx, Object{Key: "images/y.png", Size: 3}, strings.NewReader("png")); err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("bad credentials put: %v", err)
+	}
+
+	if got := s.URL("images/x.png"); !strings.HasSuffix(got, "/media/images/x.png") {
+		t.Fatalf("url = %q", got)
+	}
+}
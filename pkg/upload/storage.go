@@ -0,0 +1,166 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"justus/pkg/setting"
+)
+
+// PrivatePrefix 非图片文件的存储键前缀，这类对象不公开，只能通过签名链接访问
+const PrivatePrefix = "files/"
+
+// IsPrivate 存储键是否为私有对象
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+// ErrObjectNotFound 对象不存在
+var ErrObjectNotFound = errors.New("upload: object not found")
+
+// Object 待写入的对象
+type Object struct {
+	// Key 存储键，如 images/ab/ab12….png，使用 / 分隔
+	Key         string
+	Size        int64
+	ContentType string
+	// SHA256 内容的十六进制 SHA-256，S3 签名使用；为空时以 UNSIGNED-PAYLOAD 上传
+	SHA256 string
+}
+
+// Storage 文件存储后端
+type Storage interface {
+	Put(ctx context.Context, obj Object, body io.Reader) error
+	// Open 读取对象，不存在时返回 ErrObjectNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// URL 对象的访问地址
+	URL(key string) string
+}
+
+// defaultStorage ImageURL 使用的存储后端，由 wire 在启动时安装
+var defaultStorage Storage
+
+// SetDefaultStorage 安装 ImageURL 使用的存储后端
+func SetDefaultStorage(s Storage) {
+	defaultStorage = s
+}
+
+// NewStorage 按配置创建存储后端
+func NewStorage(cfg *setting.Upload) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		base := setting.AppSetting.ImageUrl
+		if base == "" {
+			base = GetImageFullUrl("")
+		}
+		return NewLocalStorage(GetImageFullPath(), base), nil
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	}
+	return nil, fmt.Errorf("upload: unknown storage driver %q", cfg.Driver)
+}
+
+// LocalStorage 本地磁盘存储，由路由以静态目录对外提供
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地存储，root 为存储目录，baseURL 为对应的访问地址前缀
+func NewLocalStorage(root, baseURL string) *LocalStorage {
+	return &LocalStorage{root: root, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Root 存储目录
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+// Put 先写临时文件再重命名，避免读到写了一半的文件
+func (s *LocalStorage) Put(_ context.Context, obj Object, body io.Reader) error {
+	path, err := s.path(obj.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open 读取对象
+func (s *LocalStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Exists 对象是否存在
+func (s *LocalStorage) Exists(_ context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete 删除对象
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL 对象的访问地址
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path 存储键对应的磁盘路径，拒绝跳出存储目录的键
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("upload: invalid key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
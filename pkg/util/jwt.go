@@ -1,12 +1,23 @@
 package util
 
 import (
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret []byte
+// jwtSecret 配置热加载时可能被替换，读写需加锁
+var (
+	secretMu  sync.RWMutex
+	jwtSecret []byte
+)
+
+func signingSecret() []byte {
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	return jwtSecret
+}
 
 type Claims struct {
 	Username string `json:"username"`
@@ -34,7 +45,7 @@ func GenerateToken(username, password string) (string, error) {
 	}
 
 	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := tokenClaims.SignedString(jwtSecret)
+	token, err := tokenClaims.SignedString(signingSecret())
 
 	return token, err
 }
@@ -42,7 +53,7 @@ func GenerateToken(username, password string) (string, error) {
 // ParseToken parsing token
 func ParseToken(token string) (*Claims, error) {
 	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		return signingSecret(), nil
 	})
 
 	if tokenClaims != nil {
@@ -53,3 +64,36 @@ func ParseToken(token string) (*Claims, error) {
 
 	return nil, err
 }
+
+// AdminTokenOptions 管理员令牌参数
+type AdminTokenOptions struct {
+	AdminUserID int
+	TenantID    int
+	TenantIDs   []int
+	IsSuper     bool
+	TTL         time.Duration // 为0时默认3小时
+}
+
+// GenerateAdminToken 签发携带管理员与租户信息的令牌
+func GenerateAdminToken(opts AdminTokenOptions) (string, error) {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = 3 * time.Hour
+	}
+	nowTime := time.Now()
+
+	claims := Claims{
+		AdminUserID: opts.AdminUserID,
+		IsSuper:     opts.IsSuper,
+		TenantID:    opts.TenantID,
+		TenantIDs:   opts.TenantIDs,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(nowTime),
+			ExpiresAt: jwt.NewNumericDate(nowTime.Add(ttl)),
+			Issuer:    "gin-blog",
+		},
+	}
+
+	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return tokenClaims.SignedString(signingSecret())
+}
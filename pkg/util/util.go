@@ -10,7 +10,14 @@ import (
 
 // Setup Initialize the util
 func Setup() {
-	jwtSecret = []byte(setting.AppSetting.JwtSecret)
+	SetJWTSecret([]byte(setting.AppSetting.JwtSecret))
+}
+
+// SetJWTSecret 设置签发与校验 JWT 使用的密钥
+func SetJWTSecret(secret []byte) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	jwtSecret = secret
 }
 
 //数字转换
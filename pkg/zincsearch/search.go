@@ -142,6 +142,11 @@ func (c *Client) RangeSearch(index, field string, gte, lte interface{}, from, si
 
 // BoolSearch 布尔查询
 func (c *Client) BoolSearch(index string, must, should, mustNot []map[string]interface{}, from, size int) (*SearchResponse, error) {
+	return c.BoolSearchWithSort(index, must, should, mustNot, nil, from, size)
+}
+
+// BoolSearchWithSort 带排序的布尔查询，sort 形如 []interface{}{map[string]interface{}{"@timestamp": "desc"}}
+func (c *Client) BoolSearchWithSort(index string, must, should, mustNot []map[string]interface{}, sort []interface{}, from, size int) (*SearchResponse, error) {
 	boolQuery := make(map[string]interface{})
 
 	if len(must) > 0 {
@@ -160,6 +165,7 @@ func (c *Client) BoolSearch(index string, must, should, mustNot []map[string]int
 		},
 		From: from,
 		Size: size,
+		Sort: sort,
 	}
 
 	if size <= 0 {
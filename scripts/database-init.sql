@@ -25,6 +25,7 @@ CREATE TABLE IF NOT EXISTS `ay_users` (
   `last_login_at` timestamp NULL DEFAULT NULL COMMENT '最后登录时间',
   `last_login_ip` varchar(45) DEFAULT '' COMMENT '最后登录IP地址',
   `login_count` int(11) DEFAULT 0 COMMENT '登录次数统计',
+  `version` int(11) unsigned NOT NULL DEFAULT 1 COMMENT '乐观锁版本号',
   `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` timestamp NULL DEFAULT NULL COMMENT '软删除时间',
@@ -83,6 +84,7 @@ CREATE TABLE IF NOT EXISTS `ay_roles` (
   `is_system` tinyint(1) DEFAULT 0 COMMENT '是否系统角色：1-系统内置不可删除，0-普通角色',
   `sort_order` int(11) DEFAULT 0 COMMENT '排序字段，数字越小越靠前',
   `created_by` bigint(20) DEFAULT 0 COMMENT '创建者ID',
+  `version` int(11) unsigned NOT NULL DEFAULT 1 COMMENT '乐观锁版本号',
   `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` timestamp NULL DEFAULT NULL COMMENT '软删除时间',
@@ -176,6 +178,7 @@ CREATE TABLE IF NOT EXISTS `ay_tenants` (
   `status` tinyint(1) DEFAULT 1 COMMENT '状态：1-启用，0-禁用',
   `plan` varchar(50) DEFAULT '' COMMENT '套餐/版本',
   `owner_user_id` bigint(20) DEFAULT 0 COMMENT '拥有者管理员ID',
+  `version` int(11) unsigned NOT NULL DEFAULT 1 COMMENT '乐观锁版本号',
   `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `deleted_at` timestamp NULL DEFAULT NULL COMMENT '软删除时间',
@@ -195,6 +198,105 @@ CREATE TABLE IF NOT EXISTS `ay_tenant_permissions` (
   KEY `idx_permission_id` (`permission_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='租户权限白名单';
 
+-- ===================================
+-- 管理员邀请表
+-- ===================================
+CREATE TABLE IF NOT EXISTS `ay_admin_invitations` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '邀请ID，主键',
+  `tenant_id` bigint(20) unsigned NOT NULL COMMENT '租户ID',
+  `email` varchar(100) NOT NULL COMMENT '被邀请邮箱',
+  `token_hash` char(64) NOT NULL COMMENT '邀请令牌SHA-256，明文只出现在邮件中',
+  `role_ids` varchar(255) DEFAULT '' COMMENT '接受后绑定的角色ID，逗号分隔',
+  `department` varchar(100) DEFAULT '' COMMENT '预设部门',
+  `position` varchar(50) DEFAULT '' COMMENT '预设职位',
+  `status` tinyint(1) DEFAULT 0 COMMENT '状态：0-待接受，1-已接受，2-已撤销',
+  `invited_by` bigint(20) unsigned DEFAULT 0 COMMENT '邀请人管理员ID',
+  `accepted_by` bigint(20) unsigned DEFAULT 0 COMMENT '接受后对应的管理员ID',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间',
+  `accepted_at` timestamp NULL DEFAULT NULL COMMENT '接受时间',
+  `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_invitation_token` (`token_hash`),
+  KEY `idx_invitation_tenant` (`tenant_id`),
+  KEY `idx_invitation_email` (`email`),
+  KEY `idx_invitation_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员邀请表';
+
+-- ===================================
+-- 审计日志表
+-- ===================================
+CREATE TABLE IF NOT EXISTS `ay_audit_logs` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '审计日志ID，主键',
+  `tenant_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '租户ID',
+  `actor_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '操作者管理员ID',
+  `action` varchar(50) NOT NULL COMMENT '动作，如admin_user.status',
+  `target_type` varchar(50) NOT NULL COMMENT '目标类型',
+  `target_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '目标ID',
+  `batch_id` varchar(32) DEFAULT '' COMMENT '批量操作批次号，同一请求内相同',
+  `success` tinyint(1) NOT NULL COMMENT '是否成功',
+  `code` int(11) DEFAULT 200 COMMENT '结果业务码',
+  `detail` varchar(1000) DEFAULT '' COMMENT '操作参数，JSON',
+  `request_id` varchar(64) DEFAULT '' COMMENT '请求ID',
+  `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_audit_tenant` (`tenant_id`),
+  KEY `idx_audit_actor` (`actor_id`),
+  KEY `idx_audit_action` (`action`),
+  KEY `idx_audit_target` (`target_id`),
+  KEY `idx_audit_batch` (`batch_id`),
+  KEY `idx_audit_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计日志表';
+
+-- ===================================
+-- 导入导出任务表
+-- ===================================
+CREATE TABLE IF NOT EXISTS `ay_data_jobs` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '任务ID，主键',
+  `tenant_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '租户ID',
+  `created_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '发起人管理员ID',
+  `kind` varchar(10) NOT NULL COMMENT '类型：export-导出，import-导入',
+  `resource` varchar(50) NOT NULL COMMENT '数据资源，如users、roles、audit-logs',
+  `format` varchar(10) NOT NULL COMMENT '文件格式：xlsx、csv',
+  `params` varchar(1000) DEFAULT '' COMMENT '导出时的列表查询参数',
+  `status` tinyint(4) DEFAULT 0 COMMENT '状态：0-排队中，1-执行中，2-成功，3-失败',
+  `total` bigint(20) DEFAULT 0 COMMENT '总行数',
+  `processed` bigint(20) DEFAULT 0 COMMENT '已处理行数',
+  `failed` bigint(20) DEFAULT 0 COMMENT '导入失败行数',
+  `file_name` varchar(255) DEFAULT '' COMMENT '结果文件名（导出文件或导入报告）',
+  `source_file` varchar(255) DEFAULT '' COMMENT '导入的上传文件路径',
+  `error` varchar(500) DEFAULT '' COMMENT '任务失败原因',
+  `started_at` timestamp NULL DEFAULT NULL COMMENT '开始时间',
+  `finished_at` timestamp NULL DEFAULT NULL COMMENT '结束时间',
+  `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_data_job_tenant` (`tenant_id`),
+  KEY `idx_data_job_status` (`status`),
+  KEY `idx_data_job_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='导入导出任务表';
+
+-- ===================================
+-- 上传文件表
+-- ===================================
+DROP TABLE IF EXISTS `ay_uploads`;
+CREATE TABLE `ay_uploads` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '文件ID，主键',
+  `tenant_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '租户ID',
+  `uploaded_by` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT '上传者管理员ID',
+  `category` varchar(20) NOT NULL COMMENT '分类：avatar、image、file',
+  `name` varchar(255) DEFAULT '' COMMENT '原始文件名',
+  `key` varchar(255) NOT NULL COMMENT '存储键，由内容MD5生成',
+  `content_type` varchar(100) DEFAULT '' COMMENT '嗅探得到的内容类型',
+  `size` bigint(20) NOT NULL DEFAULT 0 COMMENT '文件大小（字节）',
+  `md5` varchar(32) NOT NULL COMMENT '内容MD5',
+  `created_at` timestamp DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_upload_tenant_md5` (`tenant_id`, `md5`),
+  KEY `idx_upload_key` (`key`),
+  KEY `idx_upload_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='上传文件表';
+
 -- ===================================
 -- 默认角色数据 - 系统初始角色
 -- ===================================